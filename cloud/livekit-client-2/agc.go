@@ -0,0 +1,113 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// Optional auto-gain for forwarded room audio. Remote speakers arrive at
+// very different levels (a headset next to a laptop mic), so each sender is
+// normalized to a common target independently before mixing or pacing.
+// Envelope state is kept per track SID so one loud speaker never pulls the
+// others' gain down.
+
+const (
+	defaultAGCTargetDB = -20.0
+	agcMaxGainDB       = 20.0  // never boost more than this
+	agcMinGainDB       = -20.0 // never cut more than this
+	agcFloorDB         = -55.0 // below this the sender is treated as silent and gain is held
+	agcAttack          = 0.3   // envelope rise per frame when the level jumps
+	agcRelease         = 0.02  // envelope fall per frame when the level drops
+	agcGainSlew        = 0.1   // gain moves this fraction toward its target per frame
+	agcIdleTimeout     = 30 * time.Second
+)
+
+type agcState struct {
+	env      float64 // smoothed RMS, linear full scale
+	gain     float64
+	lastSeen time.Time
+}
+
+// senderAGC holds per-SID envelopes. Safe for concurrent use.
+type senderAGC struct {
+	mu     sync.Mutex
+	target float64 // linear RMS
+	states map[string]*agcState
+}
+
+func newSenderAGC(targetDB float64) *senderAGC {
+	return &senderAGC{
+		target: math.Pow(10, targetDB/20),
+		states: make(map[string]*agcState),
+	}
+}
+
+// process applies the sender's gain to a PCM16 LE frame and returns the
+// adjusted copy. key is the sender's track SID (identity when unknown).
+func (a *senderAGC) process(key string, pcm []byte, now time.Time) []byte {
+	samples := audio.BytesToInt16(pcm)
+	if len(samples) == 0 {
+		return pcm
+	}
+	var sumSq float64
+	for _, s := range samples {
+		v := float64(s) / 32768
+		sumSq += v * v
+	}
+	rms := math.Sqrt(sumSq / float64(len(samples)))
+
+	a.mu.Lock()
+	st := a.states[key]
+	if st == nil {
+		st = &agcState{env: rms, gain: 1}
+		a.states[key] = st
+	}
+	st.lastSeen = now
+	if rms > st.env {
+		st.env += (rms - st.env) * agcAttack
+	} else {
+		st.env += (rms - st.env) * agcRelease
+	}
+	if st.env > math.Pow(10, agcFloorDB/20) {
+		want := a.target / st.env
+		want = math.Max(math.Pow(10, agcMinGainDB/20), math.Min(want, math.Pow(10, agcMaxGainDB/20)))
+		st.gain += (want - st.gain) * agcGainSlew
+	}
+	gain := st.gain
+	a.pruneLocked(now)
+	a.mu.Unlock()
+
+	if math.Abs(gain-1) < 0.01 {
+		return pcm
+	}
+	for i, s := range samples {
+		v := float64(s) * gain
+		if v > 32767 {
+			v = 32767
+		} else if v < -32768 {
+			v = -32768
+		}
+		samples[i] = int16(v)
+	}
+	return audio.Int16ToBytes(samples)
+}
+
+// pruneLocked drops envelopes for senders not heard from in a while, so a
+// participant who rejoins with a new SID doesn't leak state
+func (a *senderAGC) pruneLocked(now time.Time) {
+	for key, st := range a.states {
+		if now.Sub(st.lastSeen) > agcIdleTimeout {
+			delete(a.states, key)
+		}
+	}
+}
+
+// reset forgets all envelopes, e.g. after a reconnect
+func (a *senderAGC) reset() {
+	a.mu.Lock()
+	a.states = make(map[string]*agcState)
+	a.mu.Unlock()
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// Bearer-token auth for /ws. With WS_AUTH_SECRET (HS256) or WS_AUTH_JWKS_URL
+// set, every connection must present a JWT, either as an Authorization
+// header or, for browsers that can't set headers on a WebSocket, a "token"
+// query parameter. The token's subject becomes the client's userId.
+
+// authLeeway allows for clock skew between the token issuer and the bridge
+const authLeeway = 30 * time.Second
+
+// jwksRefresh is how long a fetched key set is trusted before refetching
+const jwksRefresh = 10 * time.Minute
+
+var (
+	errNoToken      = errors.New("bearer token required")
+	errNoSubject    = errors.New("token has no subject")
+	errNoExpiry     = errors.New("token has no expiry")
+	errUnknownKey   = errors.New("token signed with an unknown key")
+	errAlgorithm    = errors.New("token signed with an unexpected algorithm")
+	errUserMismatch = errors.New("userId does not match the token subject")
+)
+
+// wsAuthenticator validates bearer tokens. A nil *wsAuthenticator means auth
+// is disabled.
+type wsAuthenticator struct {
+	secret   []byte // HS256 key; nil when using JWKS
+	jwksURL  string
+	expected jwt.Expected
+	client   *http.Client
+
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+func newWSAuthenticator(cfg *Config) *wsAuthenticator {
+	if cfg.WSAuthSecret == "" && cfg.WSAuthJWKSURL == "" {
+		return nil
+	}
+	a := &wsAuthenticator{
+		jwksURL:  cfg.WSAuthJWKSURL,
+		expected: jwt.Expected{Issuer: cfg.WSAuthIssuer},
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+	if cfg.WSAuthSecret != "" {
+		a.secret = []byte(cfg.WSAuthSecret)
+	}
+	if cfg.WSAuthAudience != "" {
+		a.expected.Audience = jwt.Audience{cfg.WSAuthAudience}
+	}
+	return a
+}
+
+// authenticate returns the subject of the request's bearer token
+func (a *wsAuthenticator) authenticate(r *http.Request) (string, error) {
+	raw := r.URL.Query().Get("token")
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, ok := strings.Cut(h, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return "", errNoToken
+		}
+		raw = strings.TrimSpace(token)
+	}
+	if raw == "" {
+		return "", errNoToken
+	}
+
+	tok, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return "", fmt.Errorf("malformed token: %w", err)
+	}
+	if len(tok.Headers) != 1 {
+		return "", errAlgorithm
+	}
+	key, err := a.verificationKey(tok.Headers[0])
+	if err != nil {
+		return "", err
+	}
+
+	var claims jwt.Claims
+	if err := tok.Claims(key, &claims); err != nil {
+		return "", fmt.Errorf("invalid token signature: %w", err)
+	}
+	if claims.Expiry == nil {
+		return "", errNoExpiry
+	}
+	if err := claims.ValidateWithLeeway(a.expected, authLeeway); err != nil {
+		return "", err
+	}
+	if claims.Subject == "" {
+		return "", errNoSubject
+	}
+	return claims.Subject, nil
+}
+
+// verificationKey picks the key for a token header. The algorithm is pinned
+// by the configured mode so an HS256 token can't be verified with a public
+// key, or an unsigned one slip through.
+func (a *wsAuthenticator) verificationKey(h jose.Header) (interface{}, error) {
+	if a.secret != nil {
+		if h.Algorithm != string(jose.HS256) {
+			return nil, errAlgorithm
+		}
+		return a.secret, nil
+	}
+	if h.Algorithm == "" || h.Algorithm == "none" || strings.HasPrefix(h.Algorithm, "HS") {
+		return nil, errAlgorithm
+	}
+	keys, err := a.keySet(false)
+	if err != nil {
+		return nil, err
+	}
+	matches := keys.Key(h.KeyID)
+	if len(matches) == 0 {
+		// The issuer may have rotated keys since the last fetch
+		if keys, err = a.keySet(true); err != nil {
+			return nil, err
+		}
+		matches = keys.Key(h.KeyID)
+	}
+	for _, k := range matches {
+		if k.Algorithm == "" || k.Algorithm == h.Algorithm {
+			return k.Key, nil
+		}
+	}
+	return nil, errUnknownKey
+}
+
+// keySet returns the cached JWKS, fetching it when stale or when force is set
+func (a *wsAuthenticator) keySet(force bool) (*jose.JSONWebKeySet, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.keys != nil && !force && time.Since(a.fetchedAt) < jwksRefresh {
+		return a.keys, nil
+	}
+	// Don't let tokens with unknown kids hammer the JWKS endpoint
+	if a.keys != nil && force && time.Since(a.fetchedAt) < 30*time.Second {
+		return a.keys, nil
+	}
+
+	resp, err := a.client.Get(a.jwksURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: HTTP %d", resp.StatusCode)
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+	a.keys = &keys
+	a.fetchedAt = time.Now()
+	return a.keys, nil
+}
@@ -4,36 +4,76 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/retry"
 	"github.com/gorilla/websocket"
 	lkpacer "github.com/livekit/mediatransportutil/pkg/pacer"
 	lksdk "github.com/livekit/server-sdk-go/v2"
-	lkmedia "github.com/livekit/server-sdk-go/v2/pkg/media"
 )
 
 // BridgeClient manages a single WebSocket connection and its LiveKit room
 type BridgeClient struct {
-	userID      string
-	websocket   *websocket.Conn
-	websocketMu sync.Mutex // Mutex for WebSocket writes
-	room        *lksdk.Room
-	context     context.Context
-	cancel      context.CancelFunc
-	config      *Config
+	userID       string
+	websocket    *websocket.Conn
+	websocketMu  sync.Mutex // Mutex for WebSocket writes
+	room         *lksdk.Room
+	roomURL      string // LiveKit URL the room was joined with
+	joinOpts     JoinOptions
+	retiringRoom *lksdk.Room // room being disconnected by switch_room
+	context      context.Context
+	cancel       context.CancelFunc
+	config       *Config
 
 	// Audio publishing
-	publishTrack   *lkmedia.PCMLocalTrack
+	publishTrack   audioTrack
+	publishMu      sync.Mutex // serializes ensurePublishTrack
+	publishRetryAt time.Time  // ensurePublishTrack fails fast until then
 	receivedFrames int
+	pendingIn      []int16               // sub-frame remainder carried to the next message
+	publishStopped bool                  // publish_audio stop: drop inbound audio until start
+	trackName      string                // publish track name from join options
+	inputFloat32   bool                  // inbound audio is float32 LE in [-1,1] (join inputFormat "f32le")
+	inputOpus      bool                  // inbound audio is type-prefixed Opus packets (join inputFormat "opus")
+	framedInput    bool                  // inbound messages carry track frame headers (join framing "tracks")
+	extraTracks    map[uint8]*extraTrack // framed tracks other than the main one, by track id
+	trackSeqs      map[uint8]*trackSeq   // framed sequence tracking, by track id
+	inputRate      int                   // sample rate of inbound audio (join inputRate)
+	trackRate      int                   // sample rate the publish track was created with
+	inRS           *resample.Resampler   // inputRate -> trackRate; nil when equal
+	inEpoch        uint64                // bumped whenever pendingIn and inRS are reset
+	processors     ProcessorChain
+	publishClips   audio.ClipCounter // published samples since publishing started
+	playClips      audio.ClipCounter // play_url samples since the last play_complete
+	dspConfig      *Config           // per-client overrides from configure; nil = c.config
 
 	// Audio subscribing with pacing
 	subscribeEnabled bool
 	targetIdentity   string
+	metaFilter       *metadataFilter
+	outputFloat32    bool
+	outputStereo     bool            // duplicate forwarded mono into interleaved L/R
+	limiter          *forwardLimiter // nil = no bandwidth ceiling
+	agc              *senderAGC      // nil = forward senders at their own level
+	pubFrames        atomic.Int64    // frames written to the current publish track, for fingerprints
 	pacingBuffer     *PacingBuffer
+	mixEnabled       bool
+	mixer            *Mixer
+	activeSenders    map[string]time.Time
+	outPacingRS      *resample.Resampler // 16kHz -> join outputRate; nil when 16kHz
+	outMixRS         *resample.Resampler
+	inFramer         *reframer // single-sender data-packet path only
 
 	// Statistics
 	stats ClientStats
@@ -42,23 +82,74 @@ type BridgeClient struct {
 	mu        sync.Mutex
 	connected bool
 	closed    chan struct{}
+	closeOnce sync.Once
+	rawConn   net.Conn       // underlying socket, for forceClose; set once at creation
+	wg        sync.WaitGroup // goroutines started via spawn
 
 	// Speaker playback
 	publisher *Publisher
+
+	// Optional copy of published/subscribed audio (nil when unused)
+	tap AudioTap
+
+	// Lifecycle event relay (nil when WEBHOOK_URL is unset)
+	webhook *WebhookRelay
+
+	// Uploads finished recordings (nil when RECORD_UPLOAD_BUCKET is unset)
+	uploader *RecordingUploader
+
+	// Prometheus counters for this client
+	metrics *clientMetrics
+
+	// userID came from a verified /ws token rather than the query string
+	authenticated bool
+
+	// c.room, readable without mu so forceClose can disconnect it even
+	// while a stuck Close holds the lock
+	liveRoom atomic.Pointer[lksdk.Room]
+
+	// The publish_tone in progress, if any. Only one generator runs at a
+	// time: toneDone is closed once it has stopped writing to the track.
+	toneCancel  context.CancelFunc
+	toneDone    chan struct{}
+	toneStarted time.Time
+
+	// track_levels reporting; nil when off
+	levels       *levelMeter
+	levelsCancel context.CancelFunc
+
+	// audio_levels reporting; nil when off
+	audioLevels       *audio.LevelMeter
+	audioLevelsCancel context.CancelFunc
+
+	// In-flight measure_latency request, if any
+	latencyProbe *latencyProbe
+
+	// start_recording in progress; nil when not recording
+	recording *recording
 }
 
 func (c *BridgeClient) Run() {
-	// Send initial connection event
-	c.sendEvent(Event{Type: "connected", State: "ready"})
+	// Send initial connection event with what this bridge supports
+	c.websocket.SetReadLimit(maxDiscardMessageBytes)
+	c.sendEvent(Event{Type: "connected", State: "ready", Capabilities: c.capabilities()})
 
 	// Start background tasks
-	go c.pingLoop()
+	c.spawn(c.pingLoop)
 
 	// Main message loop
 	for {
-		msgType, message, err := c.websocket.ReadMessage()
+		msgType, message, err := c.readMessage()
+		var tooBig *messageTooBigError
+		if errors.As(err, &tooBig) {
+			log.Printf("User %s: %v", c.userID, err)
+			c.sendError(err.Error())
+			continue
+		}
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if errors.Is(err, websocket.ErrReadLimit) {
+				log.Printf("Closing WebSocket for user %s: message over %d bytes", c.userID, maxDiscardMessageBytes)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error for user %s: %v", c.userID, err)
 			}
 			break
@@ -79,10 +170,76 @@ func (c *BridgeClient) Run() {
 	}
 }
 
+// messageTooBigError is returned by readMessage for a message over
+// maxInboundMessageBytes
+type messageTooBigError struct {
+	size int64
+}
+
+func (e *messageTooBigError) Error() string {
+	return fmt.Sprintf("message of %d bytes dropped: maxFrameBytes is %d", e.size, maxInboundMessageBytes)
+}
+
+// readMessage reads the next WS message. One over maxInboundMessageBytes is
+// read to its end and discarded, so the connection survives it.
+func (c *BridgeClient) readMessage() (int, []byte, error) {
+	msgType, r, err := c.websocket.NextReader()
+	if err != nil {
+		return 0, nil, err
+	}
+	message, err := io.ReadAll(io.LimitReader(r, maxInboundMessageBytes+1))
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(message) <= maxInboundMessageBytes {
+		return msgType, message, nil
+	}
+	rest, err := io.Copy(io.Discard, r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return msgType, nil, &messageTooBigError{size: int64(len(message)) + rest}
+}
+
 func (c *BridgeClient) handleCommand(cmd Command) {
 	switch cmd.Action {
 	case "join_room":
-		c.joinRoom(cmd.RoomName, cmd.Token, cmd.Url)
+		opts, err := parseJoinOptions(cmd.Config)
+		if err != nil {
+			c.sendError(err.Error())
+			return
+		}
+		c.joinRoom(cmd.RoomName, cmd.Token, cmd.Url, opts, opts.Force)
+	case "join_room_managed":
+		opts, err := parseJoinOptions(cmd.Config)
+		if err != nil {
+			c.sendError(err.Error())
+			return
+		}
+		if err := c.checkManagedJoin(cmd.RoomName, cmd.Url); err != nil {
+			c.sendError(fmt.Sprintf("join_room_managed: %v", err))
+			return
+		}
+		token, err := c.mintRoomToken(cmd.RoomName, c.userID)
+		if err != nil {
+			c.sendError(fmt.Sprintf("join_room_managed: %v", err))
+			return
+		}
+		// Minted tokens only ever go to our own LiveKit server
+		c.joinRoom(cmd.RoomName, token, c.config.LiveKitURL, opts, opts.Force)
+	case "switch_room":
+		// Without a config the current join settings carry over
+		c.mu.Lock()
+		opts := c.joinOpts
+		c.mu.Unlock()
+		if len(cmd.Config) > 0 {
+			var err error
+			if opts, err = parseJoinOptions(cmd.Config); err != nil {
+				c.sendError(err.Error())
+				return
+			}
+		}
+		c.joinRoom(cmd.RoomName, cmd.Token, cmd.Url, opts, true)
 	case "leave_room":
 		c.leaveRoom()
 	case "publish_tone":
@@ -94,33 +251,231 @@ func (c *BridgeClient) handleCommand(cmd Command) {
 		if duration == 0 {
 			duration = 3000
 		}
-		go c.publishTone(freq, duration)
+		if freq < 0 || freq >= publishSampleRate/2 {
+			c.sendError(fmt.Sprintf("publish_tone: freq must be between 1 and %d Hz", publishSampleRate/2-1))
+			return
+		}
+		if duration < 10 || duration > c.config.MaxToneMs {
+			c.sendError(fmt.Sprintf("publish_tone: ms must be between 10 and %d", c.config.MaxToneMs))
+			return
+		}
+		ctx, prev, done, err := c.startTone()
+		if err != nil {
+			c.sendError(err.Error())
+			return
+		}
+		c.spawn(func() {
+			defer close(done)
+			// Let the replaced tone stop writing first, so two generators
+			// never share the track
+			<-prev
+			if ctx.Err() != nil {
+				return
+			}
+			c.publishTone(ctx, freq, duration)
+		})
+	case "stop_tone":
+		c.stopTone()
+	case "configure":
+		c.configure(cmd.Config)
+	case "prepare_publish":
+		c.startPublishing("publish_ready")
+	case "publish_audio":
+		switch cmd.State {
+		case "start":
+			c.startPublishing("publish_started")
+		case "stop":
+			c.stopPublishing()
+		default:
+			c.sendError(fmt.Sprintf("publish_audio: unknown state %q", cmd.State))
+		}
 	case "subscribe_enable":
-		c.enableSubscribe(cmd.TargetIdentity)
+		var filter *metadataFilter
+		if cmd.MetadataFilter != "" {
+			f, err := parseMetadataFilter(cmd.MetadataFilter)
+			if err != nil {
+				c.sendError(err.Error())
+				return
+			}
+			filter = f
+		}
+		if cmd.Format != "" && cmd.Format != "s16le" && cmd.Format != "f32le" {
+			c.sendError("subscribe_enable: format must be s16le or f32le")
+			return
+		}
+		if cmd.OutputChannels != 0 && cmd.OutputChannels != 1 && cmd.OutputChannels != 2 {
+			c.sendError("subscribe_enable: outputChannels must be 1 or 2")
+			return
+		}
+		agcTargetDB := defaultAGCTargetDB
+		if cmd.AGCTargetDB != nil {
+			agcTargetDB = *cmd.AGCTargetDB
+		}
+		if cmd.AGC && (agcTargetDB < -60 || agcTargetDB > 0) {
+			c.sendError("subscribe_enable: agcTargetDb must be between -60 and 0")
+			return
+		}
+		c.enableSubscribe(subscribeOptions{
+			targetIdentity: cmd.TargetIdentity,
+			mix:            cmd.Mix,
+			filter:         filter,
+			float32Out:     cmd.Format == "f32le",
+			maxBytesPerSec: cmd.MaxBytesPerSec,
+			outputChannels: cmd.OutputChannels,
+			agc:            cmd.AGC,
+			agcTargetDB:    agcTargetDB,
+		})
 	case "subscribe_disable":
 		c.disableSubscribe()
 	case "play_url":
 		if c.publisher == nil {
 			c.publisher = NewPublisher(c)
 		}
-		c.publisher.HandlePlayURL(PlayURLCmd{
-			RequestID:  cmd.RequestID,
-			Url:        cmd.Url,
-			Volume:     cmd.Volume,
-			SampleRate: cmd.SampleRate,
+		c.publisher.Play(PlayURLCmd{
+			RequestID:      cmd.RequestID,
+			Url:            cmd.Url,
+			Volume:         cmd.Volume,
+			SampleRate:     cmd.SampleRate,
+			ChannelWeights: cmd.ChannelWeights,
 		})
+	case "measure_latency":
+		c.spawn(func() { c.measureLatency(cmd.RequestID, cmd.DurationMs) })
+	case "connection_info":
+		c.sendConnectionInfo()
+	case "track_levels":
+		// ms sets the report interval; state "stop" turns reporting off
+		if cmd.State == "stop" {
+			c.stopTrackLevels()
+			return
+		}
+		if cmd.DurationMs != 0 && cmd.DurationMs < minLevelsIntervalMs {
+			c.sendError(fmt.Sprintf("track_levels: ms must be at least %d", minLevelsIntervalMs))
+			return
+		}
+		c.startTrackLevels(cmd.DurationMs)
+	case "audio_levels":
+		// Like track_levels, but for every sender in the room whether or
+		// not subscribe is enabled, with a speaking flag
+		if cmd.State == "stop" {
+			c.stopAudioLevels()
+			return
+		}
+		if cmd.DurationMs != 0 && cmd.DurationMs < minLevelsIntervalMs {
+			c.sendError(fmt.Sprintf("audio_levels: ms must be at least %d", minLevelsIntervalMs))
+			return
+		}
+		if cmd.SpeakingDB < audio.SilenceDB || cmd.SpeakingDB > 0 {
+			c.sendError(fmt.Sprintf("audio_levels: speakingDb must be between %d and 0", int(audio.SilenceDB)))
+			return
+		}
+		c.startAudioLevels(cmd.DurationMs, cmd.SpeakingDB)
+	case "play_queue":
+		if len(cmd.URLs) == 0 || len(cmd.URLs) > maxQueueItems {
+			c.sendError(fmt.Sprintf("play_queue: urls must have 1 to %d entries", maxQueueItems))
+			return
+		}
+		if c.publisher == nil {
+			c.publisher = NewPublisher(c)
+		}
+		items := make([]PlayURLCmd, len(cmd.URLs))
+		for i, url := range cmd.URLs {
+			items[i] = PlayURLCmd{
+				Url:            url,
+				Volume:         cmd.Volume,
+				SampleRate:     cmd.SampleRate,
+				ChannelWeights: cmd.ChannelWeights,
+			}
+		}
+		c.publisher.PlayQueue(cmd.RequestID, items)
+	case "pause_playback":
+		// Holds the current play_url where it is; resume_playback carries on
+		if c.publisher == nil {
+			c.sendError("pause_playback: nothing is playing")
+			return
+		}
+		requestID, ok := c.publisher.Pause()
+		if !ok {
+			c.sendError("pause_playback: nothing is playing, or it is already paused")
+			return
+		}
+		c.sendJSON(map[string]interface{}{"type": "play_paused", "requestId": requestID})
+	case "resume_playback":
+		if c.publisher == nil {
+			c.sendError("resume_playback: nothing is playing")
+			return
+		}
+		requestID, ok := c.publisher.Resume()
+		if !ok {
+			c.sendError("resume_playback: nothing is paused")
+			return
+		}
+		c.sendJSON(map[string]interface{}{"type": "play_resumed", "requestId": requestID})
 	case "stop_playback":
 		if c.publisher != nil {
 			c.publisher.Stop(cmd.Reason)
 		}
+	case "start_recording":
+		// Tee forwarded audio into files until stop_recording
+		if err := c.startRecording(cmd.RequestID, cmd.Participants, cmd.Format, cmd.RotateSec); err != nil {
+			c.sendError(fmt.Sprintf("start_recording: %v", err))
+		}
+	case "stop_recording":
+		if !c.stopRecording() {
+			c.sendError("stop_recording: not recording")
+		}
 	default:
 		c.sendError(fmt.Sprintf("Unknown action: %s", cmd.Action))
 	}
 }
 
-func (c *BridgeClient) joinRoom(roomName, token, customURL string) {
+// parseJoinOptions decodes and validates the join_room / switch_room config
+func parseJoinOptions(raw json.RawMessage) (JoinOptions, error) {
+	var opts JoinOptions
+	if len(raw) == 0 {
+		return opts, nil
+	}
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return opts, fmt.Errorf("Invalid join config: %v", err)
+	}
+	if opts.OutputRate != 0 && (opts.OutputRate < minClientSampleRate || opts.OutputRate > maxClientSampleRate) {
+		return opts, errors.New("Invalid join config: outputRate must be 8000-48000")
+	}
+	if opts.InputRate != 0 && (opts.InputRate < minClientSampleRate || opts.InputRate > maxClientSampleRate) {
+		return opts, errors.New("Invalid join config: inputRate must be 8000-48000")
+	}
+	if opts.InputFormat != "" && opts.InputFormat != "s16le" && opts.InputFormat != "f32le" && opts.InputFormat != inputFormatOpus {
+		return opts, errors.New("Invalid join config: inputFormat must be s16le, f32le or opus")
+	}
+	if opts.InputFormat == inputFormatOpus && opts.Codec != "" && opts.Codec != codecOpus {
+		return opts, errors.New("Invalid join config: inputFormat opus requires codec opus")
+	}
+	if opts.Framing != "" && opts.Framing != framingRaw && opts.Framing != framingTracks {
+		return opts, errors.New("Invalid join config: framing must be raw or tracks")
+	}
+	if opts.Framing == framingTracks && opts.InputFormat == inputFormatOpus {
+		return opts, errors.New("Invalid join config: framing tracks carries PCM only")
+	}
+	if opts.OutputFormat != "" && opts.OutputFormat != "s16le" && opts.OutputFormat != "f32le" {
+		return opts, errors.New("Invalid join config: outputFormat must be s16le or f32le")
+	}
+	if opts.OutputChannels != 0 && opts.OutputChannels != 1 && opts.OutputChannels != 2 {
+		return opts, errors.New("Invalid join config: outputChannels must be 1 or 2")
+	}
+	switch opts.Codec {
+	case "", codecOpus, codecPCMU, codecPCMA:
+	default:
+		return opts, errors.New("Invalid join config: codec must be opus, pcmu or pcma")
+	}
+	return opts, nil
+}
+
+// joinRoom connects to roomName. With switching, an existing room is left
+// only once the new one is connected, so there's no gap without a room;
+// subscribe settings are kept and a live publish track is re-published in
+// the new room. If the new connection fails the client stays where it was.
+func (c *BridgeClient) joinRoom(roomName, token, customURL string, opts JoinOptions, switching bool) {
 	c.mu.Lock()
-	if c.room != nil {
+	if c.room != nil && !switching {
 		c.mu.Unlock()
 		c.sendError("Already in a room")
 		return
@@ -131,26 +486,67 @@ func (c *BridgeClient) joinRoom(roomName, token, customURL string) {
 	if url == "" {
 		url = c.config.LiveKitURL
 	}
+	if url == "" {
+		c.sendError("join_room: no LiveKit URL (LIVEKIT_URL is unset and the request has no url)")
+		return
+	}
+
+	// Forwarded audio converters, built before connecting so a bad rate
+	// fails the join rather than the room
+	var outPacingRS, outMixRS *resample.Resampler
+	if opts.OutputRate != 0 && opts.OutputRate != 16000 {
+		var err error
+		if outPacingRS, err = newResampler(16000, opts.OutputRate); err == nil {
+			outMixRS, err = newResampler(16000, opts.OutputRate)
+		}
+		if err != nil {
+			c.sendError(fmt.Sprintf("join_room: outputRate: %v", err))
+			return
+		}
+	}
 
 	log.Printf("User %s joining room %s", c.userID, roomName)
 
 	// Configure room callbacks
+	// Set once connected; the callbacks may run on SDK goroutines before then
+	var self atomic.Pointer[lksdk.Room]
 	roomCallback := &lksdk.RoomCallback{
 		OnDisconnected: func() {
+			if c.isRetiring(self.Load()) {
+				return // the room we just switched away from
+			}
 			log.Printf("Disconnected from room")
 			c.sendEvent(Event{Type: "disconnected", State: "disconnected"})
 		},
+		OnReconnected: func() {
+			log.Printf("Reconnected to room %s for user %s", roomName, c.userID)
+			if c.config.FlushOnReconnect {
+				c.flushSubscribeBuffers()
+			}
+			c.sendEvent(Event{Type: "reconnected", RoomName: roomName})
+		},
 		ParticipantCallback: lksdk.ParticipantCallback{
 			OnDataPacket: func(packet lksdk.DataPacket, params lksdk.DataReceiveParams) {
 				c.handleDataPacket(packet, params)
 			},
+			OnTrackPublished: func(publication *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+				c.sendEvent(Event{
+					Type:          "track_published",
+					RoomName:      roomName,
+					ParticipantID: rp.Identity(),
+				})
+			},
 		},
 	}
 
 	// Configure pacer for smooth audio
+	pacerBitrate := 512_000
+	if opts.PacerBitrate > 0 {
+		pacerBitrate = opts.PacerBitrate
+	}
 	pacerFactory := lkpacer.NewPacerFactory(
 		lkpacer.LeakyBucketPacer,
-		lkpacer.WithBitrate(512_000),
+		lkpacer.WithBitrate(pacerBitrate),
 		lkpacer.WithMaxLatency(100*time.Millisecond),
 	)
 
@@ -162,27 +558,132 @@ func (c *BridgeClient) joinRoom(roomName, token, customURL string) {
 	)
 	if err != nil {
 		log.Printf("Failed to connect to room: %v", err)
+		c.metrics.joinFailures.Inc()
 		c.sendError(fmt.Sprintf("Failed to connect: %v", err))
 		return
 	}
 
+	self.Store(room)
+
 	c.mu.Lock()
+	if c.context.Err() != nil {
+		// Closed or force-killed while connecting
+		c.mu.Unlock()
+		room.Disconnect()
+		return
+	}
+	old, oldName := c.room, ""
+	republish := false
+	if old != nil {
+		oldName = old.Name()
+		// The publish track belongs to the old room
+		if c.publishTrack != nil {
+			c.publishTrack.Close()
+			c.publishTrack = nil
+			republish = !c.publishStopped
+		}
+		c.closeExtraTracksLocked()
+		c.pendingIn = nil
+		c.retiringRoom = old
+	}
 	c.room = room
+	c.liveRoom.Store(room)
+	c.joinOpts = opts
+	c.roomURL = url
 	c.connected = true
+	c.trackName = opts.TrackName
+	c.inputFloat32 = opts.InputFormat == "f32le"
+	c.inputOpus = opts.InputFormat == inputFormatOpus
+	c.framedInput = opts.Framing == framingTracks
+	c.inputRate = opts.InputRate
+	if c.inputRate == 0 {
+		c.inputRate = publishSampleRate
+	}
+	c.inRS = nil
+	c.inEpoch++
+	c.outPacingRS, c.outMixRS = outPacingRS, outMixRS
 	c.mu.Unlock()
 
+	if old != nil {
+		old.Disconnect()
+		c.mu.Lock()
+		c.retiringRoom = nil
+		c.mu.Unlock()
+		log.Printf("User %s switched from room %s to %s", c.userID, oldName, roomName)
+		c.sendClipReport()
+		c.sendEvent(Event{Type: "room_left", RoomName: oldName})
+	}
+
 	c.sendEvent(Event{
 		Type:             "room_joined",
 		RoomName:         roomName,
 		ParticipantID:    string(room.LocalParticipant.Identity()),
 		ParticipantCount: len(room.GetRemoteParticipants()),
 	})
+
+	if republish {
+		if err := c.ensurePublishTrack(); err != nil {
+			c.sendError(fmt.Sprintf("Cannot republish after switch: %v", err))
+		}
+	}
+
+	if opts.AutoSubscribe && !(old != nil && c.isSubscribed()) {
+		c.enableSubscribe(subscribeOptions{
+			targetIdentity: opts.TargetIdentity,
+			mix:            opts.Mix,
+			float32Out:     opts.OutputFormat == "f32le",
+			outputChannels: opts.OutputChannels,
+		})
+	}
 }
 
-func (c *BridgeClient) leaveRoom() {
+// sendConnectionInfo reports which LiveKit server the room landed on, for
+// debugging geo-routing. The SDK doesn't expose a redirected signal URL, so
+// url is the one we dialed.
+func (c *BridgeClient) sendConnectionInfo() {
+	c.mu.Lock()
+	room, url := c.room, c.roomURL
+	c.mu.Unlock()
+	if room == nil {
+		c.sendError("Not in a room")
+		return
+	}
+
+	info := map[string]interface{}{
+		"type":            "connection_info",
+		"roomName":        room.Name(),
+		"roomSid":         room.SID(),
+		"url":             url,
+		"connectionState": string(room.ConnectionState()),
+	}
+	if si := room.ServerInfo(); si != nil {
+		info["region"] = si.Region
+		info["serverVersion"] = si.Version
+		info["nodeId"] = si.NodeId
+		info["protocol"] = si.Protocol
+		info["edition"] = si.Edition.String()
+	}
+	c.sendJSON(info)
+}
+
+// isRetiring reports whether room is the one being left by a switch
+func (c *BridgeClient) isRetiring(room *lksdk.Room) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return room != nil && room == c.retiringRoom
+}
+
+func (c *BridgeClient) isSubscribed() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	return c.subscribeEnabled
+}
+
+func (c *BridgeClient) leaveRoom() {
+	// Events lock c.mu, so they're sent after it's released
+	c.mu.Lock()
 	if c.room == nil {
+		c.mu.Unlock()
 		c.sendError("Not in a room")
 		return
 	}
@@ -190,13 +691,49 @@ func (c *BridgeClient) leaveRoom() {
 		c.publishTrack.Close()
 		c.publishTrack = nil
 	}
+	c.closeExtraTracksLocked()
 	c.room.Disconnect()
 	c.room = nil
+	c.liveRoom.Store(nil)
 	c.connected = false
+	c.mu.Unlock()
+
+	c.sendClipReport()
 	c.sendEvent(Event{Type: "room_left"})
 }
 
+// handleIncomingAudio publishes one inbound binary message: a framed
+// multi-track message when joined with framing "tracks", otherwise audio for
+// the main track
 func (c *BridgeClient) handleIncomingAudio(data []byte) {
+	c.mu.Lock()
+	framed := c.framedInput
+	c.mu.Unlock()
+	if framed {
+		c.handleFramedAudio(data)
+		return
+	}
+	c.publishMainAudio(data)
+}
+
+// publishMainAudio publishes PCM16 LE mono at the join inputRate to the main
+// track, resampled to the track rate when they differ.
+// A message may hold any number of samples: a single 10ms frame, several
+// coalesced frames, or an arbitrary size. It is split into 10ms frames and any
+// sub-frame remainder is held back and prepended to the next message, so the
+// track always receives whole frames regardless of how the client batches.
+func (c *BridgeClient) publishMainAudio(data []byte) {
+	c.mu.Lock()
+	stopped := c.publishStopped
+	c.mu.Unlock()
+	if stopped {
+		return
+	}
+
+	// Audio still in flight while leaving or closing is dropped quietly
+	if !c.isJoined() {
+		return
+	}
 	if err := c.ensurePublishTrack(); err != nil {
 		log.Printf("Cannot send audio: %v", err)
 		return
@@ -207,43 +744,89 @@ func (c *BridgeClient) handleIncomingAudio(data []byte) {
 	frameCount := c.stats.audioFramesIn
 	c.stats.mu.Unlock()
 
+	c.mu.Lock()
+	encoded, float32In := c.inputOpus, c.inputFloat32
+	c.mu.Unlock()
+	if encoded {
+		c.handleOpusMessage(data)
+		return
+	}
+
 	// Convert to int16 samples
-	samples := make([]int16, len(data)/2)
-	for i := 0; i < len(samples); i++ {
-		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
-	}
-
-	// Apply gain if configured
-	if c.config.PublishGain != 1.0 {
-		for i := range samples {
-			scaled := float64(samples[i]) * c.config.PublishGain
-			if scaled > 32767 {
-				scaled = 32767
-			} else if scaled < -32768 {
-				scaled = -32768
-			}
-			samples[i] = int16(scaled)
+	var samples []int16
+	if float32In {
+		samples = audio.Float32ToInt16(data)
+	} else {
+		samples = make([]int16, len(data)/2)
+		for i := 0; i < len(samples); i++ {
+			samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
 		}
 	}
 
+	// Match the track rate, otherwise the audio would be pitch-shifted
+	c.mu.Lock()
+	if c.inputRate != c.trackRate && c.inRS == nil {
+		var err error
+		if c.inRS, err = newResampler(c.inputRate, c.trackRate); err != nil {
+			c.mu.Unlock()
+			log.Printf("Cannot send audio: %v", err)
+			return
+		}
+	}
+	rs := c.inRS
+	trackRate := c.trackRate
+	pending, epoch := c.pendingIn, c.inEpoch
+	c.pendingIn = nil
+	c.mu.Unlock()
+	if rs != nil {
+		samples = rs.Process(samples)
+	}
+
+	// Prepend the remainder held back from the previous message
+	if len(pending) > 0 {
+		samples = append(pending, samples...)
+	}
+
 	if frameCount%500 == 0 {
 		log.Printf("Received audio chunk %d for user %s: %d bytes", frameCount, c.userID, len(data))
 	}
 
+	c.mu.Lock()
+	chain := c.processors
+	c.mu.Unlock()
+
 	// Write to LiveKit track in 10ms chunks
-	sampleRate := 16000
-	frameSamples := sampleRate / 100 // 10ms
-	for offset := 0; offset < len(samples); offset += frameSamples {
-		end := offset + frameSamples
-		if end > len(samples) {
-			end = len(samples)
-		}
-		frame := samples[offset:end]
-		if err := c.publishTrack.WriteSample(frame); err != nil {
-			log.Printf("Failed to write PCM sample: %v", err)
-			break
+	frameSamples := trackRate / 100 // 10ms
+	whole := len(samples) - len(samples)%frameSamples
+	for offset := 0; offset < whole; offset += frameSamples {
+		frame := chain.Process(samples[offset : offset+frameSamples])
+		if len(frame) == 0 {
+			continue
+		}
+		if err := c.writeFrame(frame); err != nil {
+			if !errors.Is(err, errTrackClosed) {
+				log.Printf("Failed to write PCM sample: %v", err)
+			}
+			return
+		}
+		statFramesPublished.Add(1)
+		c.metrics.framesIn.Inc()
+		c.mu.Lock()
+		c.publishClips.Add(frame, c.config.ClipLevel)
+		c.mu.Unlock()
+		if c.tap != nil {
+			c.tap.OnPublish(c.userID, append([]int16(nil), frame...))
 		}
 	}
+	if whole < len(samples) {
+		// Keep the remainder only if no join or publish stop reset the
+		// input state meanwhile; it would be glued onto the new track
+		c.mu.Lock()
+		if c.inEpoch == epoch {
+			c.pendingIn = append([]int16(nil), samples[whole:]...)
+		}
+		c.mu.Unlock()
+	}
 }
 
 func (c *BridgeClient) handleDataPacket(packet lksdk.DataPacket, params lksdk.DataReceiveParams) {
@@ -253,10 +836,18 @@ func (c *BridgeClient) handleDataPacket(packet lksdk.DataPacket, params lksdk.Da
 	c.stats.dataPktsReceived++
 	pktCount := c.stats.dataPktsReceived
 	c.stats.mu.Unlock()
-	if !c.subscribeEnabled {
+	statDataPackets.Add(1)
+	c.meterRoomAudio(params.SenderIdentity, packet)
+	c.mu.Lock()
+	subscribed, target, filter, mix := c.subscribeEnabled, c.targetIdentity, c.metaFilter, c.mixEnabled
+	c.mu.Unlock()
+	if !subscribed {
 		return
 	}
-	if c.targetIdentity != "" && params.SenderIdentity != c.targetIdentity {
+	if target != "" && params.SenderIdentity != target {
+		return
+	}
+	if filter != nil && (params.Sender == nil || !filter.match(params.Sender.Metadata())) {
 		return
 	}
 
@@ -274,66 +865,289 @@ func (c *BridgeClient) handleDataPacket(packet lksdk.DataPacket, params lksdk.Da
 	if len(pcmData) == 0 {
 		return
 	}
-	if pktCount <= 5 {
-		c.logPCMStats(pcmData, pktCount)
+	if int64(pktCount) <= int64(c.config.FingerprintFrames) {
+		logFingerprint(c.userID, "sub", int64(pktCount), pcmData)
+	}
+	if c.tap != nil {
+		c.tap.OnSubscribe(c.userID, params.SenderIdentity, pcmToInt16(pcmData))
+	}
+	c.checkLatencyMarker(pcmData, now)
+	c.mu.Lock()
+	meter := c.levels
+	agc := c.agc
+	rec := c.recording
+	c.mu.Unlock()
+	if rec != nil {
+		rec.add(params.SenderIdentity, pcmData)
+	}
+	sid := ""
+	if params.Sender != nil {
+		sid = params.Sender.SID()
+	}
+	if meter != nil {
+		meter.add(params.SenderIdentity, sid, pcmData)
+	}
+	if agc != nil {
+		key := sid
+		if key == "" {
+			key = params.SenderIdentity
+		}
+		pcmData = agc.process(key, pcmData, now)
+	}
+	// Packets from several senders can't share the pacing queue without
+	// interleaving into garbage, so they go through per-sender mixer buffers
+	multiSender := c.noteSender(params.SenderIdentity, now)
+	if mix || multiSender {
+		c.mixer.Add(params.SenderIdentity, pcmData)
+	} else {
+		for _, frame := range c.inFramer.push(c.userID, pcmData) {
+			if out := c.convertOutput(false, frame); len(out) > 0 {
+				c.pacingBuffer.Add(out)
+			}
+		}
 	}
-	c.pacingBuffer.Add(pcmData)
 	if pktCount <= 5 || pktCount%100 == 0 {
-		log.Printf("[bridge] DataPacket rx #%d from=%s bytes=%d (buffered for pacing)", pktCount, params.SenderIdentity, len(pcmData))
+		log.Printf("[bridge] DataPacket rx #%d from=%s bytes=%d (buffered for pacing, mix=%v multiSender=%v)", pktCount, params.SenderIdentity, len(pcmData), mix, multiSender)
 	}
 }
 
-func (c *BridgeClient) logPCMStats(pcmData []byte, pktNum int) {
-	minV := int16(32767)
-	maxV := int16(-32768)
-	var sumAbs, sumSq int64
-	for i := 0; i+1 < len(pcmData); i += 2 {
-		v := int16(binary.LittleEndian.Uint16(pcmData[i : i+2]))
-		if v < minV {
-			minV = v
-		}
-		if v > maxV {
-			maxV = v
-		}
-		if v < 0 {
-			sumAbs += int64(-v)
-		} else {
-			sumAbs += int64(v)
-		}
-		sumSq += int64(v) * int64(v)
+// monoToStereo interleaves each sample into identical L/R channels
+func monoToStereo(mono []int16) []int16 {
+	out := make([]int16, len(mono)*2)
+	for i, s := range mono {
+		out[2*i] = s
+		out[2*i+1] = s
+	}
+	return out
+}
+
+// convertOutput resamples forwarded 16kHz PCM to the join outputRate and
+// converts it to float32 if requested. The pacing and mixer paths keep
+// separate resampler state.
+func (c *BridgeClient) convertOutput(mixed bool, pcm []byte) []byte {
+	c.mu.Lock()
+	st := c.outPacingRS
+	if mixed {
+		st = c.outMixRS
+	}
+	float32Out := c.outputFloat32
+	stereo := c.outputStereo
+	c.mu.Unlock()
+	if st == nil && !float32Out && !stereo {
+		return pcm
+	}
+	samples := audio.BytesToInt16(pcm)
+	if st != nil {
+		samples = st.Process(samples)
 	}
-	cnt := len(pcmData) / 2
-	if cnt > 0 {
-		meanAbs := float64(sumAbs) / float64(cnt)
-		rms := math.Sqrt(float64(sumSq) / float64(cnt))
-		log.Printf("[bridge] packet #%d PCM stats: samples=%d bytes=%d meanAbs=%.1f rms=%.1f min=%d max=%d", pktNum, cnt, len(pcmData), meanAbs, rms, minV, maxV)
+	if stereo {
+		samples = monoToStereo(samples)
 	}
+	if float32Out {
+		return audio.Int16ToFloat32Bytes(samples)
+	}
+	return audio.Int16ToBytes(samples)
 }
 
-func (c *BridgeClient) ensurePublishTrack() error {
+// flushSubscribeBuffers drops audio buffered before a reconnect so it isn't
+// replayed after it, and resets the forwarding counters
+func (c *BridgeClient) flushSubscribeBuffers() {
+	c.pacingBuffer.Flush()
+	c.mixer.Flush()
+	c.inFramer.reset()
+
+	c.mu.Lock()
+	c.activeSenders = nil
+	if c.agc != nil {
+		c.agc.reset()
+	}
+	if c.outPacingRS != nil {
+		// Fresh state rather than Reset: convertOutput may be mid-Process.
+		// The rate was accepted at join, so this can't fail.
+		c.outPacingRS, _ = newResampler(16000, c.joinOpts.OutputRate)
+		c.outMixRS, _ = newResampler(16000, c.joinOpts.OutputRate)
+	}
+	c.mu.Unlock()
+
+	c.stats.mu.Lock()
+	c.stats.dataPktsReceived = 0
+	c.stats.wsSendCount = 0
+	c.stats.wsSendBytes = 0
+	c.stats.mu.Unlock()
+	log.Printf("Flushed subscribe buffers for user %s after reconnect", c.userID)
+}
+
+// senderActiveWindow is how long a sender counts as active after its last packet
+const senderActiveWindow = time.Second
+
+// noteSender records a packet from sender and reports whether more than one
+// sender is currently active.
+func (c *BridgeClient) noteSender(sender string, now time.Time) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if !c.connected || c.room == nil {
+	if c.activeSenders == nil {
+		c.activeSenders = make(map[string]time.Time)
+	}
+	c.activeSenders[sender] = now
+	for id, last := range c.activeSenders {
+		if now.Sub(last) > senderActiveWindow {
+			delete(c.activeSenders, id)
+		}
+	}
+	return len(c.activeSenders) > 1
+}
+
+// publishFailureBackoff is how long ensurePublishTrack fails fast after the
+// retries are exhausted, so every inbound message doesn't retry again
+const publishFailureBackoff = 5 * time.Second
+
+// errPublishBackoff is returned while waiting out publishFailureBackoff
+var errPublishBackoff = errors.New("publish track failed recently; backing off")
+
+// Publish attempts against LiveKit before a failure is treated as persistent
+const (
+	publishAttempts   = 3
+	publishRetryDelay = 100 * time.Millisecond
+)
+
+// ensurePublishTrack creates and publishes the track if needed. Transient
+// PublishTrack failures are retried; a persistent one is reported with a
+// publish_failed event. The publish runs outside c.mu so forwarding isn't
+// held up by the retries.
+func (c *BridgeClient) ensurePublishTrack() error {
+	c.publishMu.Lock()
+	defer c.publishMu.Unlock()
+
+	c.mu.Lock()
+	room := c.room
+	if !c.connected || room == nil {
+		c.mu.Unlock()
 		return fmt.Errorf("not connected to room")
 	}
 	if c.publishTrack != nil {
+		c.mu.Unlock()
 		return nil
 	}
+	if time.Now().Before(c.publishRetryAt) {
+		c.mu.Unlock()
+		return errPublishBackoff
+	}
+	name := c.trackName
+	if name == "" {
+		name = "microphone"
+	}
+	codec := c.joinOpts.Codec
+	encoded := c.inputOpus
+	c.mu.Unlock()
 
-	track, err := lkmedia.NewPCMLocalTrack(16000, 1, nil)
+	track, err := newAudioTrack(codec, encoded)
 	if err != nil {
-		return fmt.Errorf("create PCM track: %w", err)
+		return fmt.Errorf("create %s track: %w", codecName(codec), err)
 	}
 
-	if _, err := c.room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{Name: "microphone"}); err != nil {
+	err = retry.Transient(c.context, publishAttempts, publishRetryDelay, func() error {
+		_, err := room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{Name: name})
+		if err != nil {
+			log.Printf("PublishTrack failed for user %s: %v", c.userID, err)
+		}
+		return err
+	})
+	if err != nil {
+		track.Close()
+		c.mu.Lock()
+		c.publishRetryAt = time.Now().Add(publishFailureBackoff)
+		c.mu.Unlock()
+		c.sendEvent(Event{Type: "publish_failed", Error: err.Error()})
 		return fmt.Errorf("publish track: %w", err)
 	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.room != room {
+		// Left or switched rooms while publishing
+		track.Close()
+		return fmt.Errorf("not connected to room")
+	}
 	c.publishTrack = track
-	log.Printf("PCM audio track published for user %s", c.userID)
+	c.pubFrames.Store(0)
+	c.trackRate = publishSampleRate
+	c.publishRetryAt = time.Time{}
+	log.Printf("Audio track published for user %s (codec=%s passthrough=%v)", c.userID, codecName(codec), encoded)
 	return nil
 }
 
-func (c *BridgeClient) publishTone(freqHz, durationMs int) {
+// startPublishing creates and publishes the track up front instead of on the
+// first audio message, and resumes accepting audio after a stop. readyEvent
+// is sent once PublishTrack has completed, so audio sent after it isn't lost
+// to a lazy publish.
+func (c *BridgeClient) startPublishing(readyEvent string) {
+	c.mu.Lock()
+	c.publishStopped = false
+	c.mu.Unlock()
+	if err := c.ensurePublishTrack(); err != nil {
+		c.sendError(fmt.Sprintf("Cannot start publishing: %v", err))
+		return
+	}
+	c.sendEvent(Event{Type: readyEvent})
+}
+
+// stopPublishing unpublishes the track and drops inbound audio until the
+// next publish_audio start
+func (c *BridgeClient) stopPublishing() {
+	c.mu.Lock()
+	c.publishStopped = true
+	if c.publishTrack != nil {
+		c.publishTrack.Close()
+		c.publishTrack = nil
+	}
+	c.closeExtraTracksLocked()
+	c.pendingIn = nil
+	c.inRS = nil
+	c.inEpoch++
+	c.mu.Unlock()
+	log.Printf("Publishing stopped for user %s", c.userID)
+	c.sendClipReport()
+	c.sendEvent(Event{Type: "publish_stopped"})
+}
+
+// startTone cancels any tone in progress and returns the context for a new
+// one, a channel closed once the old tone has exited, and the channel the
+// new tone must close when it exits. Starts closer together than
+// TONE_COOLDOWN_MS are rejected.
+func (c *BridgeClient) startTone() (context.Context, <-chan struct{}, chan struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if wait := c.config.ToneCooldown - time.Since(c.toneStarted); wait > 0 {
+		return nil, nil, nil, fmt.Errorf("publish_tone: rate limited, retry in %dms", wait.Milliseconds())
+	}
+	if c.toneCancel != nil {
+		c.toneCancel()
+	}
+	prev := c.toneDone
+	if prev == nil {
+		closed := make(chan struct{})
+		close(closed)
+		prev = closed
+	}
+	ctx, cancel := context.WithCancel(c.context)
+	done := make(chan struct{})
+	c.toneCancel = cancel
+	c.toneDone = done
+	c.toneStarted = time.Now()
+	return ctx, prev, done, nil
+}
+
+// stopTone cancels the tone in progress, if any
+func (c *BridgeClient) stopTone() {
+	c.mu.Lock()
+	if c.toneCancel != nil {
+		c.toneCancel()
+		c.toneCancel = nil
+	}
+	c.mu.Unlock()
+}
+
+func (c *BridgeClient) publishTone(ctx context.Context, freqHz, durationMs int) {
 	if err := c.ensurePublishTrack(); err != nil {
 		log.Printf("Cannot publish tone: %v", err)
 		return
@@ -344,6 +1158,13 @@ func (c *BridgeClient) publishTone(freqHz, durationMs int) {
 	log.Printf("Publishing tone: freq=%dHz duration=%dms", freqHz, durationMs)
 	timeIndex := 0
 	for frame := 0; frame < totalFrames; frame++ {
+		select {
+		case <-ctx.Done():
+			log.Printf("Tone publishing stopped after %dms", frame*10)
+			c.sendEvent(Event{Type: "tone_stopped"})
+			return
+		default:
+		}
 		samples := make([]int16, samplesPerFrame)
 		for i := 0; i < samplesPerFrame; i++ {
 			angle := 2 * math.Pi * float64(freqHz) * float64(timeIndex) / float64(sampleRate)
@@ -361,11 +1182,10 @@ func (c *BridgeClient) publishTone(freqHz, durationMs int) {
 				samples[i] = int16(scaled)
 			}
 		}
-		if c.publishTrack == nil {
-			break
-		}
-		if err := c.publishTrack.WriteSample(samples); err != nil {
-			log.Printf("Failed to write tone sample: %v", err)
+		if err := c.writeFrame(samples); err != nil {
+			if !errors.Is(err, errTrackClosed) {
+				log.Printf("Failed to write tone sample: %v", err)
+			}
 			break
 		}
 		time.Sleep(10 * time.Millisecond)
@@ -373,23 +1193,74 @@ func (c *BridgeClient) publishTone(freqHz, durationMs int) {
 	log.Printf("Tone publishing completed")
 }
 
-func (c *BridgeClient) enableSubscribe(targetIdentity string) {
+// subscribeOptions are the settings of subscribe_enable
+type subscribeOptions struct {
+	targetIdentity string
+	mix            bool
+	filter         *metadataFilter
+	float32Out     bool // forward float32 LE instead of PCM16
+	maxBytesPerSec int  // hard forwarding ceiling; 0 = unlimited
+	outputChannels int  // 2 = interleaved stereo; otherwise mono
+	agc            bool // per-sender auto-gain
+	agcTargetDB    float64
+}
+
+func (c *BridgeClient) enableSubscribe(opts subscribeOptions) {
 	c.mu.Lock()
 	c.subscribeEnabled = true
-	c.targetIdentity = targetIdentity
+	c.targetIdentity = opts.targetIdentity
+	c.mixEnabled = opts.mix
+	c.metaFilter = opts.filter
+	c.outputFloat32 = opts.float32Out
+	c.outputStereo = opts.outputChannels == 2
+	c.limiter = nil
+	if opts.maxBytesPerSec > 0 {
+		c.limiter = newForwardLimiter(opts.maxBytesPerSec)
+	}
+	c.agc = nil
+	if opts.agc {
+		c.agc = newSenderAGC(opts.agcTargetDB)
+	}
 	c.mu.Unlock()
-	log.Printf("Subscribe enabled for user %s (target=%s)", c.userID, targetIdentity)
+	filterDesc := ""
+	if opts.filter != nil {
+		filterDesc = opts.filter.key + "=" + opts.filter.value
+	}
+	log.Printf("Subscribe enabled for user %s (target=%s mix=%v metadata=%s float32=%v stereo=%v agc=%v)", c.userID, opts.targetIdentity, opts.mix, filterDesc, opts.float32Out, opts.outputChannels == 2, opts.agc)
 }
 
 func (c *BridgeClient) disableSubscribe() {
 	c.mu.Lock()
 	c.subscribeEnabled = false
 	c.targetIdentity = ""
+	c.mixEnabled = false
+	c.metaFilter = nil
+	c.outputFloat32 = false
+	c.limiter = nil
+	c.agc = nil
 	c.mu.Unlock()
 	log.Printf("Subscribe disabled for user %s", c.userID)
 }
 
 func (c *BridgeClient) sendBinaryData(data []byte) {
+	c.mu.Lock()
+	limiter := c.limiter
+	c.mu.Unlock()
+	if limiter != nil {
+		if ok, dropped := limiter.allow(len(data), time.Now()); !ok {
+			statFramesDropped.Add(1)
+			c.metrics.dropped.Inc()
+			if dropped > 0 {
+				c.sendJSON(map[string]interface{}{
+					"type":           "forward_throttled",
+					"droppedFrames":  dropped,
+					"maxBytesPerSec": int(limiter.rate),
+				})
+			}
+			return
+		}
+	}
+
 	c.websocketMu.Lock()
 	defer c.websocketMu.Unlock()
 	c.mu.Lock()
@@ -401,9 +1272,12 @@ func (c *BridgeClient) sendBinaryData(data []byte) {
 	ws.SetWriteDeadline(time.Now().Add(5 * time.Second))
 	if err := ws.WriteMessage(websocket.BinaryMessage, data); err != nil {
 		log.Printf("Failed to send binary data to user %s: %v", c.userID, err)
+		c.metrics.wsErrors.Inc()
 		go c.Close()
 		return
 	}
+	statFramesForwarded.Add(1)
+	c.metrics.framesOut.Inc()
 	c.stats.mu.Lock()
 	c.stats.wsSendCount++
 	c.stats.wsSendBytes += int64(len(data))
@@ -414,6 +1288,8 @@ func (c *BridgeClient) sendBinaryData(data []byte) {
 }
 
 func (c *BridgeClient) sendEvent(event Event) {
+	c.webhook.Relay(c.userID, event)
+
 	c.websocketMu.Lock()
 	defer c.websocketMu.Unlock()
 	c.mu.Lock()
@@ -447,6 +1323,7 @@ func (c *BridgeClient) sendJSON(v interface{}) {
 	ws.SetWriteDeadline(time.Now().Add(5 * time.Second))
 	if err := ws.WriteJSON(v); err != nil {
 		log.Printf("Failed to send JSON event to user %s: %v", c.userID, err)
+		c.metrics.wsErrors.Inc()
 		// Close on write failure to reset the connection
 		go c.Close()
 	}
@@ -466,25 +1343,61 @@ func (c *BridgeClient) trySendJSON(v interface{}) bool {
 	ws.SetWriteDeadline(time.Now().Add(5 * time.Second))
 	if err := ws.WriteJSON(v); err != nil {
 		log.Printf("Failed to send JSON event to user %s: %v", c.userID, err)
+		c.metrics.wsErrors.Inc()
 		go c.Close()
 		return false
 	}
 	return true
 }
 
-func (c *BridgeClient) sendPlayComplete(requestId string, success bool, durationMs int, errMsg string) {
+// sendPlayComplete reports how a play request ended. index is its
+// play_queue position, or -1 for a play_url.
+func (c *BridgeClient) sendPlayComplete(requestId string, index int, success bool, durationMs int, code playerr.Code, detail string) {
 	evt := map[string]interface{}{
 		"type":       "play_complete",
 		"requestId":  requestId,
 		"success":    success,
 		"durationMs": durationMs,
 	}
-	if errMsg != "" {
-		evt["error"] = errMsg
+	if index >= 0 {
+		evt["index"] = index
+	}
+	if code != "" {
+		evt["code"] = code
+		evt["message"] = code.Message()
+	}
+	if detail != "" {
+		evt["error"] = detail
 	}
+	c.mu.Lock()
+	if c.playClips.Samples > 0 {
+		evt["clippedSamples"] = c.playClips.Clipped
+		evt["clipPercent"] = c.playClips.Percent()
+	}
+	c.playClips = audio.ClipCounter{}
+	c.mu.Unlock()
 	c.sendJSON(evt)
 }
 
+// sendClipReport reports clipping on the publish path since publishing
+// started, then resets the count. Nothing is sent if nothing was published.
+func (c *BridgeClient) sendClipReport() {
+	c.mu.Lock()
+	clips := c.publishClips
+	c.publishClips = audio.ClipCounter{}
+	c.mu.Unlock()
+	if clips.Samples == 0 {
+		return
+	}
+	c.sendJSON(map[string]interface{}{
+		"type":           "clip_report",
+		"path":           "publish",
+		"samples":        clips.Samples,
+		"clippedSamples": clips.Clipped,
+		"clipPercent":    clips.Percent(),
+	})
+}
+
 func (c *BridgeClient) isJoined() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -492,9 +1405,39 @@ func (c *BridgeClient) isJoined() bool {
 }
 
 func (c *BridgeClient) writeSamples(samples []int16) error {
+	if !c.isJoined() {
+		return errTrackClosed
+	}
 	if err := c.ensurePublishTrack(); err != nil {
 		return err
 	}
+	c.mu.Lock()
+	c.playClips.Add(samples, c.config.ClipLevel)
+	c.mu.Unlock()
+	return c.writeFrame(samples)
+}
+
+// publishSampleRate is the rate of the published PCM track. Tones, playback
+// and processors all produce audio at this rate.
+const publishSampleRate = 16000
+
+// errTrackClosed is returned by writeFrame once the publish track has been
+// closed; callers treat it as a quiet stop rather than a failure
+var errTrackClosed = errors.New("publish track closed")
+
+// writeFrame writes to the publish track under the client mutex, so it can't
+// race with leaveRoom/Close closing the track
+func (c *BridgeClient) writeFrame(samples []int16) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.publishTrack == nil {
+		return errTrackClosed
+	}
+	if n := c.config.FingerprintFrames; n > 0 {
+		if seq := c.pubFrames.Add(1); seq <= int64(n) {
+			logFingerprint(c.userID, "pub", seq, audio.Int16ToBytes(samples))
+		}
+	}
 	return c.publishTrack.WriteSample(samples)
 }
 
@@ -513,6 +1456,7 @@ func (c *BridgeClient) pingLoop() {
 				err := ws.WriteMessage(websocket.PingMessage, nil)
 				c.websocketMu.Unlock()
 				if err != nil {
+					c.metrics.wsErrors.Inc()
 					return
 				}
 			} else {
@@ -524,24 +1468,82 @@ func (c *BridgeClient) pingLoop() {
 	}
 }
 
-func (c *BridgeClient) Close() {
+// goroutineDrainTimeout bounds how long Close waits for spawned goroutines
+const goroutineDrainTimeout = 2 * time.Second
+
+// spawn runs fn in a goroutine tracked by the client so Close can wait for it
+func (c *BridgeClient) spawn(fn func()) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		fn()
+	}()
+}
+
+// forceClose tears down a client whose Close is stuck, typically behind a
+// blocked WebSocket write. Closing the raw socket unblocks the writer so the
+// regular Close can finish on its own. The room is disconnected right away
+// without c.mu, which the stuck Close may hold, so the replacement client
+// never shares it; Close's own Disconnect later is harmless.
+func (c *BridgeClient) forceClose() {
 	c.cancel()
-	if c.pacingBuffer != nil {
-		c.pacingBuffer.Stop()
+	if c.rawConn != nil {
+		c.rawConn.Close()
 	}
-	c.mu.Lock()
-	if c.publishTrack != nil {
-		c.publishTrack.Close()
-		c.publishTrack = nil
-	}
-	if c.room != nil {
-		c.room.Disconnect()
-		c.room = nil
+	if room := c.liveRoom.Swap(nil); room != nil {
+		room.Disconnect()
 	}
-	if c.websocket != nil {
-		c.websocket.Close()
-		c.websocket = nil
-	}
-	c.mu.Unlock()
-	close(c.closed)
+}
+
+func (c *BridgeClient) Close() {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		// Finish recorded files while their events can still be sent
+		c.stopRecording()
+		if c.pacingBuffer != nil {
+			// Forward the last partial frame along with the queued ones
+			if tail := c.inFramer.flush(); tail != nil {
+				if out := c.convertOutput(false, tail); len(out) > 0 {
+					c.pacingBuffer.Add(out)
+				}
+			}
+			c.pacingBuffer.Stop()
+		}
+		if c.mixer != nil {
+			c.mixer.Stop()
+		}
+		if c.publisher != nil {
+			c.publisher.Stop("client_closed")
+		}
+		c.mu.Lock()
+		if c.publishTrack != nil {
+			c.publishTrack.Close()
+			c.publishTrack = nil
+		}
+		c.closeExtraTracksLocked()
+		if c.room != nil {
+			c.room.Disconnect()
+			c.room = nil
+			c.liveRoom.Store(nil)
+		}
+		if c.websocket != nil {
+			c.websocket.Close()
+			c.websocket = nil
+		}
+		c.mu.Unlock()
+
+		// Wait for background goroutines so teardown is deterministic
+		done := make(chan struct{})
+		go func() {
+			c.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(goroutineDrainTimeout):
+			log.Printf("Timed out waiting for goroutines to exit for user %s", c.userID)
+		}
+		c.metrics.release()
+		close(c.closed)
+	})
 }
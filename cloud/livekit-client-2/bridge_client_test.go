@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// pcmRamp is n PCM16 LE samples counting up from first
+func pcmRamp(first, n int) []byte {
+	b := make([]byte, n*2)
+	for i := 0; i < n; i++ {
+		binary.LittleEndian.PutUint16(b[i*2:], uint16(first+i))
+	}
+	return b
+}
+
+// TestPublishMainAudioReframes sends messages of whole, coalesced and
+// partial frames and checks the track gets only whole 10ms frames, with
+// each sub-frame remainder carried into the next message in order
+func TestPublishMainAudioReframes(t *testing.T) {
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	waitForClients(t, service, 1)
+	client, _ := service.clients.Get("user-1")
+	track, leave := fakeJoin(client)
+	defer leave()
+
+	const frame = publishSampleRate / 100
+	sent := 0
+	send := func(n int) {
+		client.publishMainAudio(pcmRamp(sent, n))
+		sent += n
+	}
+	send(frame)           // one frame
+	send(frame*2 + 50)    // two coalesced frames and a remainder
+	send(frame - 50 - 10) // finishes the remainder, bar 10 samples
+	send(10 + frame*3)    // the last 10 and three more frames
+	send(30)              // less than a frame: held back
+
+	frames := track.written()
+	if len(frames) != 7 {
+		t.Fatalf("%d frames written, want 7", len(frames))
+	}
+	next := 0
+	for i, f := range frames {
+		if len(f) != frame {
+			t.Fatalf("frame %d has %d samples, want %d", i, len(f), frame)
+		}
+		for _, s := range f {
+			if int(s) != next {
+				t.Fatalf("frame %d: sample %d, want %d", i, s, next)
+			}
+			next++
+		}
+	}
+	if got := len(client.pendingIn); got != 30 {
+		t.Errorf("%d samples held back, want 30", got)
+	}
+}
+
+// TestSubscribeAGCTarget checks an explicit agcTargetDb of 0 (full scale)
+// is kept rather than replaced by the default
+func TestSubscribeAGCTarget(t *testing.T) {
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	waitForClients(t, service, 1)
+	client, _ := service.clients.Get("user-1")
+
+	tests := []struct {
+		cmd  string
+		want float64 // linear RMS target
+	}{
+		{`{"action":"subscribe_enable","agc":true}`, math.Pow(10, defaultAGCTargetDB/20)},
+		{`{"action":"subscribe_enable","agc":true,"agcTargetDb":0}`, 1},
+		{`{"action":"subscribe_enable","agc":true,"agcTargetDb":-40}`, 0.01},
+	}
+	for _, tt := range tests {
+		var cmd Command
+		if err := json.Unmarshal([]byte(tt.cmd), &cmd); err != nil {
+			t.Fatal(err)
+		}
+		client.handleCommand(cmd)
+		client.mu.Lock()
+		agc := client.agc
+		client.mu.Unlock()
+		if agc == nil {
+			t.Fatalf("%s: AGC not enabled", tt.cmd)
+		}
+		if math.Abs(agc.target-tt.want) > 1e-9 {
+			t.Errorf("%s: target = %v, want %v", tt.cmd, agc.target, tt.want)
+		}
+	}
+}
+
+// TestOversizedMessageDropped checks a message over maxFrameBytes gets an
+// error event and the connection keeps working
+func TestOversizedMessageDropped(t *testing.T) {
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if err := conn.WriteMessage(websocket.BinaryMessage, make([]byte, maxInboundMessageBytes+10)); err != nil {
+		t.Fatal(err)
+	}
+	var evt Event
+	if err := conn.ReadJSON(&evt); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("message of %d bytes dropped: maxFrameBytes is %d", maxInboundMessageBytes+10, maxInboundMessageBytes)
+	if evt.Type != "error" || evt.Error != want {
+		t.Fatalf("event = %+v, want error %q", evt, want)
+	}
+
+	if err := conn.WriteJSON(map[string]string{"action": "no_such_action"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.ReadJSON(&evt); err != nil {
+		t.Fatalf("connection closed after the oversized message: %v", err)
+	}
+	if evt.Type != "error" || evt.Error != "Unknown action: no_such_action" {
+		t.Fatalf("event = %+v, want the unknown action error", evt)
+	}
+}
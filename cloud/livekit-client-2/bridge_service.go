@@ -8,29 +8,61 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/registry"
 	"github.com/gorilla/websocket"
 )
 
 // BridgeService manages all bridge clients
 type BridgeService struct {
-	clients map[string]*BridgeClient
-	mu      sync.RWMutex
+	clients *registry.Registry[*BridgeClient]
+	mu      sync.RWMutex // guards tap
 	config  *Config
+	tap     AudioTap
+	webhook *WebhookRelay
+	upload  *RecordingUploader // nil = recordings stay on disk
+	auth    *wsAuthenticator   // nil = userId is trusted as given
 }
 
 func NewBridgeService(config *Config) *BridgeService {
 	return &BridgeService{
-		clients: make(map[string]*BridgeClient),
+		clients: registry.New[*BridgeClient](),
 		config:  config,
+		webhook: NewWebhookRelay(config.WebhookURL),
+		upload:  NewRecordingUploader(config),
+		auth:    newWSAuthenticator(config),
 	}
 }
 
+// SetAudioTap installs a tap that receives a copy of every client's audio.
+// It applies to clients connected after the call.
+func (s *BridgeService) SetAudioTap(tap AudioTap) {
+	s.mu.Lock()
+	s.tap = tap
+	s.mu.Unlock()
+}
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
 func (s *BridgeService) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	userID := r.URL.Query().Get("userId")
+	if s.auth != nil {
+		// The token's subject is the identity; a userId param, if sent,
+		// must agree with it
+		subject, err := s.auth.authenticate(r)
+		if err != nil {
+			log.Printf("Rejected WebSocket from %s: %v", r.RemoteAddr, err)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if userID != "" && userID != subject {
+			log.Printf("Rejected WebSocket from %s: %v (userId=%s sub=%s)", r.RemoteAddr, errUserMismatch, userID, subject)
+			http.Error(w, errUserMismatch.Error(), http.StatusForbidden)
+			return
+		}
+		userID = subject
+	}
 	if userID == "" {
 		http.Error(w, "userId required", http.StatusBadRequest)
 		return
@@ -55,37 +87,87 @@ func (s *BridgeService) HandleWebSocket(w http.ResponseWriter, r *http.Request)
 		cancel:    cancel,
 		config:    s.config,
 		closed:    make(chan struct{}),
+		webhook:   s.webhook,
+		uploader:  s.upload,
+		rawConn:   conn.UnderlyingConn(),
+		metrics:   newClientMetrics(userID),
+
+		authenticated: s.auth != nil,
 	}
+	client.processors = newProcessorChain(s.config)
+	client.inFramer = newReframer(s.config.InboundFrameMs, s.config.InboundFrameCheck)
+	s.mu.RLock()
+	client.tap = s.tap
+	s.mu.RUnlock()
 
-	// Initialize pacing buffer for smooth audio delivery
-	// 100ms interval to match expected audio chunk rate
-	client.pacingBuffer = NewPacingBuffer(100*time.Millisecond, 10, func(data []byte) {
+	// Initialize pacing buffer for smooth audio delivery, one tick per
+	// reframed inbound frame
+	client.pacingBuffer = newInboundPacer(s.config.InboundFrameMs, s.config.WSCoalesceFrames, func(data []byte) {
 		client.sendBinaryData(data)
 	})
+	client.pacingBuffer.onDrop = client.metrics.addDropped
 	client.pacingBuffer.Start()
 
-	// Register client (clean up any existing)
-	s.mu.Lock()
-	if existing, ok := s.clients[userID]; ok {
-		existing.Close()
-		s.mu.Unlock()
-		// Wait for cleanup
-		select {
-		case <-existing.closed:
-		case <-time.After(2 * time.Second):
+	// Mixer for summing multiple senders into one mono stream (subscribe_enable with mix)
+	client.mixer = NewMixer(100*time.Millisecond, 16000, 10, func(data []byte) {
+		if out := client.convertOutput(true, data); len(out) > 0 {
+			client.sendBinaryData(out)
 		}
-		s.mu.Lock()
+	})
+	client.mixer.onDrop = client.metrics.addDropped
+	client.mixer.Start()
+
+	// Register client (clean up any existing)
+	if existing, ok := s.clients.Get(userID); ok {
+		s.replaceClient(existing)
 	}
-	s.clients[userID] = client
-	s.mu.Unlock()
+	s.clients.Put(userID, client)
 
 	defer func() {
-		s.mu.Lock()
-		delete(s.clients, userID)
-		s.mu.Unlock()
+		// Only unregister ourselves; a reconnect may already have replaced us
+		s.clients.DeleteIf(userID, client)
 		client.Close()
 	}()
 
 	log.Printf("WebSocket connected: user=%s", userID)
 	client.Run()
 }
+
+// Shutdown closes every client, which finishes their recordings and queues
+// them for upload, sends the last webhook events, then waits for the
+// uploads until ctx is done
+func (s *BridgeService) Shutdown(ctx context.Context) {
+	var wg sync.WaitGroup
+	s.clients.Each(func(_ string, client *BridgeClient) bool {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.Close()
+		}()
+		return true
+	})
+	wg.Wait()
+	// After the clients, so their disconnected events are sent
+	s.webhook.Close()
+	s.upload.Close(ctx)
+}
+
+// replaceClient closes a previous client for the same user before the new
+// one takes over. If it hasn't finished closing within ReplaceTimeout it is
+// force-killed, so two clients never share the room.
+func (s *BridgeService) replaceClient(existing *BridgeClient) {
+	go existing.Close()
+	select {
+	case <-existing.closed:
+		return
+	case <-time.After(s.config.ReplaceTimeout):
+	}
+
+	log.Printf("Previous client for user %s did not close within %v; forcing it closed", existing.userID, s.config.ReplaceTimeout)
+	existing.forceClose()
+	select {
+	case <-existing.closed:
+	case <-time.After(goroutineDrainTimeout):
+		log.Printf("Previous client for user %s still closing after force kill; proceeding", existing.userID)
+	}
+}
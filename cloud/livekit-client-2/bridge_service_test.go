@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReplaceClientForceKillsStuckClient holds the old client's lock so its
+// Close can't finish, as a blocked write would, and checks a reconnect only
+// waits ReplaceTimeout before force-killing it
+func TestReplaceClientForceKillsStuckClient(t *testing.T) {
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ReplaceTimeout = 100 * time.Millisecond
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	waitForClients(t, service, 1)
+	old, _ := service.clients.Get("user-1")
+
+	old.mu.Lock()
+	replaced := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		service.replaceClient(old)
+		replaced <- time.Since(start)
+	}()
+
+	// Close cancels the client first thing, then blocks on the lock before
+	// it gets to the WebSocket. Only the force kill closes the socket.
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	var netErr net.Error
+	if err == nil || errors.As(err, &netErr) && netErr.Timeout() {
+		old.mu.Unlock()
+		t.Fatalf("old client's socket still open: %v", err)
+	}
+	select {
+	case <-old.closed:
+		t.Error("Close finished while its lock was held")
+	default:
+	}
+
+	old.mu.Unlock()
+	took := <-replaced
+	if took < config.ReplaceTimeout {
+		t.Errorf("replaceClient returned after %v, before ReplaceTimeout", took)
+	}
+	<-old.closed
+}
+
+// TestTapForwarderStreamsFrames checks tapped frames reach the endpoint
+// framed as the README describes
+func TestTapForwarderStreamsFrames(t *testing.T) {
+	got := make(chan []byte, 2)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			got <- msg
+		}
+	}))
+	defer endpoint.Close()
+
+	tap := NewTapForwarder("ws" + strings.TrimPrefix(endpoint.URL, "http"))
+	defer tap.Close()
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewBridgeService(config)
+	service.SetAudioTap(tap)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	waitForClients(t, service, 1)
+	client, _ := service.clients.Get("user-1")
+	if client.tap != AudioTap(tap) {
+		t.Fatal("client didn't get the service's tap")
+	}
+
+	// Frames offered before the connection is up may be dropped, so keep
+	// offering until one arrives
+	deadline := time.After(5 * time.Second)
+	var msg []byte
+	for msg == nil {
+		client.tap.OnSubscribe("user-1", "sender-1", []int16{1, -2})
+		select {
+		case msg = <-got:
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no frame reached the tap endpoint")
+		}
+	}
+	n := int(binary.BigEndian.Uint16(msg))
+	var header tapHeader
+	if err := json.Unmarshal(msg[2:2+n], &header); err != nil {
+		t.Fatal(err)
+	}
+	if header != (tapHeader{UserID: "user-1", Sender: "sender-1", Direction: "subscribe"}) {
+		t.Errorf("header = %+v", header)
+	}
+	if samples := msg[2+n:]; !bytes.Equal(samples, []byte{1, 0, 0xfe, 0xff}) {
+		t.Errorf("samples = %v, want 1, -2 as PCM16 LE", samples)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+// maxInboundMessageBytes caps one inbound WS message (about 30s of 16kHz
+// PCM16). Larger messages are dropped with an error event.
+const maxInboundMessageBytes = 1 << 20
+
+// maxDiscardMessageBytes is the largest oversized message skipped over
+// rather than closing the connection
+const maxDiscardMessageBytes = 16 << 20
+
+// Sample rate bounds accepted for join inputRate and outputRate
+const (
+	minClientSampleRate = 8000
+	maxClientSampleRate = 48000
+)
+
+// capabilities reports what this bridge supports, for the connected event
+func (c *BridgeClient) capabilities() *Capabilities {
+	return &Capabilities{
+		InputFormats:   []string{"s16le", "f32le", inputFormatOpus},
+		OutputFormats:  []string{"s16le", "f32le"},
+		MinSampleRate:  minClientSampleRate,
+		MaxSampleRate:  maxClientSampleRate,
+		PublishRate:    publishSampleRate,
+		OutputChannels: []int{1, 2},
+		Codecs:         []string{codecOpus, codecPCMU, codecPCMA},
+		MaxFrameBytes:  maxInboundMessageBytes,
+		FrameMs:        10,
+		CoalesceFrames: c.config.WSCoalesceFrames,
+		OpusForwarding: false,
+		Framings:       []string{framingRaw, framingTracks},
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+)
+
+// ClientConfig is the payload of the configure command. Absent fields keep
+// their current value; unknown fields are rejected.
+type ClientConfig struct {
+	Gain        *float64 `json:"gain,omitempty"`
+	DCBlock     *bool    `json:"dcBlock,omitempty"`
+	NoiseGate   *bool    `json:"noiseGate,omitempty"`
+	NoiseGateDB *float64 `json:"noiseGateDb,omitempty"`
+	Processors  []string `json:"processors,omitempty"` // replaces the whole chain
+
+	// Forwarded (subscribe) audio, as set by subscribe_enable and join
+	AGC            *bool    `json:"agc,omitempty"`
+	AGCTargetDB    *float64 `json:"agcTargetDb,omitempty"`
+	OutputRate     *int     `json:"outputRate,omitempty"`
+	OutputChannels *int     `json:"outputChannels,omitempty"` // 1 = mono, 2 = mono duplicated into L/R
+}
+
+// configure applies per-client DSP options. The new processor chain is
+// built in full before being swapped in, so a bad config changes nothing.
+func (c *BridgeClient) configure(raw json.RawMessage) {
+	if len(raw) == 0 {
+		c.sendError("configure: missing config")
+		return
+	}
+	var opts ClientConfig
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&opts); err != nil {
+		c.sendError(fmt.Sprintf("configure: %v", err))
+		return
+	}
+
+	c.mu.Lock()
+	base := c.dspConfig
+	c.mu.Unlock()
+	if base == nil {
+		base = c.config
+	}
+	next := *base
+
+	if opts.Gain != nil {
+		if *opts.Gain < 0 {
+			c.sendError("configure: gain must be >= 0")
+			return
+		}
+		next.PublishGain = *opts.Gain
+	}
+	if opts.NoiseGateDB != nil {
+		if *opts.NoiseGateDB >= 0 {
+			c.sendError("configure: noiseGateDb must be negative")
+			return
+		}
+		next.NoiseGateDB = *opts.NoiseGateDB
+	}
+	if opts.Processors != nil {
+		for _, name := range opts.Processors {
+			if _, ok := processorFactories[name]; !ok {
+				c.sendError(fmt.Sprintf("configure: unknown processor %q", name))
+				return
+			}
+		}
+		next.AudioProcessors = append([]string(nil), opts.Processors...)
+	} else {
+		next.AudioProcessors = append([]string(nil), next.AudioProcessors...)
+	}
+	if opts.DCBlock != nil {
+		next.AudioProcessors = toggleProcessor(next.AudioProcessors, "dcblock", *opts.DCBlock, true)
+	}
+	if opts.NoiseGate != nil {
+		next.AudioProcessors = toggleProcessor(next.AudioProcessors, "noisegate", *opts.NoiseGate, false)
+	}
+
+	fwd, err := c.forwardConfig(opts)
+	if err != nil {
+		c.sendError("configure: " + err.Error())
+		return
+	}
+
+	chain := newProcessorChain(&next)
+	c.mu.Lock()
+	c.dspConfig = &next
+	c.processors = chain
+	fwd.apply(c)
+	c.mu.Unlock()
+
+	c.sendEvent(Event{Type: "configured"})
+}
+
+// toggleProcessor adds name (at the front or back) or removes it from names
+func toggleProcessor(names []string, name string, on, front bool) []string {
+	out := names[:0]
+	for _, n := range names {
+		if n != name {
+			out = append(out, n)
+		}
+	}
+	if !on {
+		return out
+	}
+	if front {
+		return append([]string{name}, out...)
+	}
+	return append(out, name)
+}
+
+// forwardChange is the validated forwarded-audio part of a configure,
+// built outside the client lock and applied under it
+type forwardChange struct {
+	setAGC          bool       // replace c.agc with agc
+	agc             *senderAGC // nil = off
+	rate            int        // new outputRate; 0 = unchanged
+	pacingRS, mixRS *resample.Resampler
+	channels        int // new outputChannels; 0 = unchanged
+}
+
+// forwardConfig validates the AGC and output settings of opts and builds
+// the state they need, leaving the client untouched on error
+func (c *BridgeClient) forwardConfig(opts ClientConfig) (*forwardChange, error) {
+	var fwd forwardChange
+	c.mu.Lock()
+	agcWasOn := c.agc != nil
+	c.mu.Unlock()
+
+	agcOn := agcWasOn
+	if opts.AGC != nil {
+		agcOn = *opts.AGC
+	}
+	if opts.AGCTargetDB != nil {
+		if !agcOn {
+			return nil, errors.New("agcTargetDb needs agc on")
+		}
+		if *opts.AGCTargetDB < -60 || *opts.AGCTargetDB > 0 {
+			return nil, errors.New("agcTargetDb must be between -60 and 0")
+		}
+	}
+	switch {
+	case !agcOn:
+		fwd.setAGC = agcWasOn
+	case opts.AGCTargetDB != nil:
+		fwd.setAGC, fwd.agc = true, newSenderAGC(*opts.AGCTargetDB)
+	case !agcWasOn:
+		fwd.setAGC, fwd.agc = true, newSenderAGC(defaultAGCTargetDB)
+	}
+
+	if opts.OutputRate != nil {
+		rate := *opts.OutputRate
+		if rate < minClientSampleRate || rate > maxClientSampleRate {
+			return nil, fmt.Errorf("outputRate must be %d-%d", minClientSampleRate, maxClientSampleRate)
+		}
+		fwd.rate = rate
+		if rate != publishSampleRate {
+			var err error
+			if fwd.pacingRS, err = newResampler(publishSampleRate, rate); err == nil {
+				fwd.mixRS, err = newResampler(publishSampleRate, rate)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("outputRate: %v", err)
+			}
+		}
+	}
+	if opts.OutputChannels != nil {
+		if *opts.OutputChannels != 1 && *opts.OutputChannels != 2 {
+			return nil, errors.New("outputChannels must be 1 or 2")
+		}
+		fwd.channels = *opts.OutputChannels
+	}
+	return &fwd, nil
+}
+
+// apply installs the change; the caller holds c.mu
+func (f *forwardChange) apply(c *BridgeClient) {
+	if f.setAGC {
+		c.agc = f.agc
+	}
+	if f.rate != 0 {
+		c.joinOpts.OutputRate = f.rate
+		c.outPacingRS, c.outMixRS = f.pacingRS, f.mixRS
+	}
+	if f.channels != 0 {
+		c.outputStereo = f.channels == 2
+	}
+}
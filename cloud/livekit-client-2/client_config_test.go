@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestConfigureForwardedAudio checks configure changes AGC and the output
+// format live, and that an invalid field changes nothing
+func TestConfigureForwardedAudio(t *testing.T) {
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	waitForClients(t, service, 1)
+	client, _ := service.clients.Get("user-1")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	configure := func(cfg string) Event {
+		t.Helper()
+		if err := conn.WriteJSON(map[string]json.RawMessage{"action": json.RawMessage(`"configure"`), "config": json.RawMessage(cfg)}); err != nil {
+			t.Fatal(err)
+		}
+		var evt Event
+		if err := conn.ReadJSON(&evt); err != nil {
+			t.Fatal(err)
+		}
+		return evt
+	}
+
+	if evt := configure(`{"agc":true,"agcTargetDb":0,"outputRate":48000,"outputChannels":2}`); evt.Type != "configured" {
+		t.Fatalf("event = %+v, want configured", evt)
+	}
+	client.mu.Lock()
+	agc, rs, stereo, rate := client.agc, client.outPacingRS, client.outputStereo, client.joinOpts.OutputRate
+	client.mu.Unlock()
+	if agc == nil || agc.target != 1 {
+		t.Errorf("agc = %+v, want on with a full-scale target", agc)
+	}
+	if rs == nil || rate != 48000 || !stereo {
+		t.Errorf("output = resampler %v, rate %d, stereo %v; want 48kHz stereo", rs != nil, rate, stereo)
+	}
+
+	// A bad outputRate rejects the whole configure, gain included
+	if evt := configure(`{"gain":2,"agc":false,"outputRate":1}`); evt.Type != "error" {
+		t.Fatalf("event = %+v, want an error", evt)
+	}
+	client.mu.Lock()
+	unchanged := client.agc == agc && client.dspConfig.PublishGain != 2
+	client.mu.Unlock()
+	if !unchanged {
+		t.Error("rejected configure changed the client")
+	}
+
+	if evt := configure(`{"agc":false,"outputRate":16000,"outputChannels":1}`); evt.Type != "configured" {
+		t.Fatalf("event = %+v, want configured", evt)
+	}
+	client.mu.Lock()
+	restored := client.agc == nil && client.outPacingRS == nil && !client.outputStereo
+	client.mu.Unlock()
+	if !restored {
+		t.Error("configure didn't turn AGC off and restore 16kHz mono")
+	}
+
+	if evt := configure(`{"agcTargetDb":-20}`); evt.Type != "error" {
+		t.Errorf("agcTargetDb with AGC off: event = %+v, want an error", evt)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/g711track"
+	"github.com/livekit/media-sdk"
+	lkmedia "github.com/livekit/server-sdk-go/v2/pkg/media"
+	"github.com/pion/webrtc/v4"
+)
+
+// Publish codecs selectable with the join option "codec"
+const (
+	codecOpus = "opus"
+	codecPCMU = g711track.PCMU
+	codecPCMA = g711track.PCMA
+)
+
+// audioTrack is the publish track: PCMLocalTrack (Opus) or g711track.Track.
+// Both take 16kHz mono PCM and can be passed to PublishTrack.
+type audioTrack interface {
+	webrtc.TrackLocal
+	WriteSample(chunk media.PCM16Sample) error
+	Close()
+}
+
+// codecName is codec with the Opus default filled in, for logs
+func codecName(codec string) string {
+	if codec == "" {
+		return codecOpus
+	}
+	return codec
+}
+
+// newAudioTrack creates the publish track for codec ("" = Opus). With
+// encoded the track takes pre-encoded Opus packets instead of PCM.
+func newAudioTrack(codec string, encoded bool) (audioTrack, error) {
+	if encoded {
+		return newOpusTrack()
+	}
+	switch codec {
+	case codecPCMU, codecPCMA:
+		return g711track.New(codec, publishSampleRate, resampleQuality)
+	}
+	return lkmedia.NewPCMLocalTrack(publishSampleRate, 1, nil)
+}
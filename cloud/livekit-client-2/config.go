@@ -1,8 +1,15 @@
 package main
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/envconfig"
 )
 
 // Configuration from environment
@@ -10,28 +17,346 @@ type Config struct {
 	Port        string
 	LiveKitURL  string
 	PublishGain float64
+
+	// Processors applied in order to every published 10ms frame
+	AudioProcessors []string
+
+	// Frames below this RMS level are silenced by the "noisegate" processor
+	NoiseGateDB float64
+
+	// Liveness watermark tone used by the "watermark" processor
+	WatermarkHz      float64
+	WatermarkLevelDB float64
+
+	// Number of paced frames per outgoing WS message (1 = no coalescing)
+	WSCoalesceFrames int
+
+	// Serve expvar counters on /debug/vars
+	DebugVarsEnabled bool
+
+	// Listen address for /metrics and /debug/vars, kept off the public
+	// WebSocket port (empty = not served), and the HMAC key for the
+	// metrics "user" label (empty = random per process)
+	MetricsAddr     string
+	MetricsLabelKey string
+
+	// How long play_url waits for the first valid MP3 frame
+	MP3InitTimeout time.Duration
+
+	// POST room lifecycle events here as JSON (empty = disabled)
+	WebhookURL string
+
+	// Stream a copy of every client's published and subscribed audio to
+	// this WebSocket endpoint, e.g. for transcription (empty = disabled)
+	AudioTapURL string
+
+	// Drop subscribe audio buffered before a LiveKit reconnect
+	FlushOnReconnect bool
+
+	// Sample magnitude counted as clipped in clip_report / play_complete
+	ClipLevel int
+
+	// Longest publish_tone accepted, in ms
+	MaxToneMs int
+
+	// Minimum time between publish_tone starts on one client
+	ToneCooldown time.Duration
+
+	// Re-chunk inbound data-packet audio into frames of this many ms before
+	// pacing (0 = forward payloads as received), and warn when payload
+	// sizes vary wildly
+	InboundFrameMs    int
+	InboundFrameCheck bool
+
+	// How long a reconnecting user's previous client gets to close before
+	// it is force-killed
+	ReplaceTimeout time.Duration
+
+	// Play a URL even if its play_started event couldn't be sent
+	ContinueOnStartFailure bool
+
+	// How often play_url sends play_progress (0 = never)
+	PlayProgressInterval time.Duration
+
+	// Where start_recording writes its files, and how often each sender's
+	// file is rotated when the command doesn't set rotateSec
+	RecordDir    string
+	RecordRotate time.Duration
+
+	// Upload finished recordings to this S3-compatible bucket (empty = keep
+	// them on disk only). Endpoint is for non-AWS stores; files larger than
+	// one part use multipart upload.
+	RecordUploadBucket    string
+	RecordUploadPrefix    string
+	RecordUploadEndpoint  string
+	RecordUploadRegion    string
+	RecordUploadAccessKey string
+	RecordUploadSecretKey string
+	RecordUploadPartMB    int
+	RecordUploadDelete    bool // remove the local file once uploaded
+
+	// Log a fingerprint of the first N published and received frames
+	// (0 = off)
+	FingerprintFrames int
+
+	// Require a bearer JWT on /ws, verified with an HS256 secret or keys
+	// from a JWKS URL (neither = no auth). Issuer and audience are checked
+	// when set.
+	WSAuthSecret   string
+	WSAuthJWKSURL  string
+	WSAuthIssuer   string
+	WSAuthAudience string
+
+	// Credentials for join_room_managed, which mints room tokens itself,
+	// how long those tokens are valid, and the rooms it may mint them for
+	LiveKitAPIKey    string
+	LiveKitAPISecret string
+	ManagedTokenTTL  time.Duration
+	ManagedRooms     []string
+
+	// Filter quality for every sample rate conversion
+	ResampleQuality resample.Quality
 }
 
-func loadConfig() *Config {
+func loadConfig() (*Config, error) {
+	if err := envconfig.Load(); err != nil {
+		return nil, err
+	}
+
 	config := &Config{
-		Port:        getEnv("PORT", "8080"),
-		LiveKitURL:  getEnv("LIVEKIT_URL", "wss://livekit.example.com"),
+		Port:        envconfig.Get("PORT", "8080"),
+		LiveKitURL:  envconfig.Get("LIVEKIT_URL", ""),
 		PublishGain: 1.0,
+
+		AudioProcessors:  parseProcessorNames(envconfig.Get("AUDIO_PROCESSORS", "gain")),
+		NoiseGateDB:      -50,
+		WatermarkHz:      7000,
+		WatermarkLevelDB: -60,
+		WSCoalesceFrames: 1,
+		DebugVarsEnabled: envconfig.Get("DEBUG_VARS_ENABLED", "false") == "true",
+		MetricsAddr:      envconfig.Get("METRICS_ADDR", ":9090"),
+		MP3InitTimeout:   5 * time.Second,
+		WebhookURL:       envconfig.Get("WEBHOOK_URL", ""),
+		AudioTapURL:      envconfig.Get("AUDIO_TAP_URL", ""),
+		FlushOnReconnect: envconfig.Get("FLUSH_ON_RECONNECT", "true") == "true",
+		ClipLevel:        32767,
+		MaxToneMs:        60000,
+		ToneCooldown:     500 * time.Millisecond,
+
+		InboundFrameCheck: envconfig.Get("INBOUND_FRAME_CHECK", "false") == "true",
+		ReplaceTimeout:    2 * time.Second,
+
+		ContinueOnStartFailure: envconfig.Get("PLAY_START_FAILURE", "abort") == "continue",
+		PlayProgressInterval:   time.Second,
+		FingerprintFrames:      5,
+
+		RecordDir:    envconfig.Get("RECORD_DIR", filepath.Join(os.TempDir(), "livekit-recordings")),
+		RecordRotate: 5 * time.Minute,
+
+		RecordUploadBucket:    envconfig.Get("RECORD_UPLOAD_BUCKET", ""),
+		RecordUploadPrefix:    envconfig.Get("RECORD_UPLOAD_PREFIX", ""),
+		RecordUploadEndpoint:  envconfig.Get("RECORD_UPLOAD_ENDPOINT", ""),
+		RecordUploadRegion:    envconfig.Get("RECORD_UPLOAD_REGION", "us-east-1"),
+		RecordUploadAccessKey: envconfig.Get("RECORD_UPLOAD_ACCESS_KEY_ID", ""),
+		RecordUploadPartMB:    16,
+		RecordUploadDelete:    envconfig.Get("RECORD_UPLOAD_DELETE", "false") == "true",
+
+		WSAuthJWKSURL:  envconfig.Get("WS_AUTH_JWKS_URL", ""),
+		WSAuthIssuer:   envconfig.Get("WS_AUTH_ISSUER", ""),
+		WSAuthAudience: envconfig.Get("WS_AUTH_AUDIENCE", ""),
+
+		ManagedTokenTTL: time.Hour,
+		ManagedRooms:    parseManagedRooms(envconfig.Get("MANAGED_ROOMS", managedRoomUser)),
+	}
+
+	// Secrets can come from mounted secret files instead of the environment
+	for _, secret := range []struct {
+		key string
+		dst *string
+	}{
+		{"WS_AUTH_SECRET", &config.WSAuthSecret},
+		{"LIVEKIT_API_KEY", &config.LiveKitAPIKey},
+		{"LIVEKIT_API_SECRET", &config.LiveKitAPISecret},
+		{"RECORD_UPLOAD_SECRET_ACCESS_KEY", &config.RecordUploadSecretKey},
+		{"METRICS_LABEL_KEY", &config.MetricsLabelKey},
+	} {
+		value, err := envconfig.Secret(secret.key)
+		if err != nil {
+			return nil, err
+		}
+		*secret.dst = value
+	}
+
+	if gainStr := envconfig.Lookup("PUBLISH_GAIN"); gainStr != "" {
+		gain, err := strconv.ParseFloat(gainStr, 64)
+		if err != nil || gain <= 0 {
+			return nil, fmt.Errorf("PUBLISH_GAIN must be a number > 0, got %q", gainStr)
+		}
+		config.PublishGain = gain
+	}
+
+	if qualityStr := envconfig.Lookup("RESAMPLE_QUALITY"); qualityStr != "" {
+		quality, err := resample.ParseQuality(qualityStr)
+		if err != nil {
+			return nil, fmt.Errorf("RESAMPLE_QUALITY: %w", err)
+		}
+		config.ResampleQuality = quality
+	}
+
+	if gateStr := envconfig.Lookup("NOISE_GATE_DB"); gateStr != "" {
+		if db, err := strconv.ParseFloat(gateStr, 64); err == nil && db < 0 {
+			config.NoiseGateDB = db
+		}
+	}
+
+	if hzStr := envconfig.Lookup("WATERMARK_HZ"); hzStr != "" {
+		if hz, err := strconv.ParseFloat(hzStr, 64); err == nil && hz > 0 && hz < 8000 {
+			config.WatermarkHz = hz
+		}
+	}
+
+	if levelStr := envconfig.Lookup("WATERMARK_LEVEL_DB"); levelStr != "" {
+		if db, err := strconv.ParseFloat(levelStr, 64); err == nil && db < 0 {
+			config.WatermarkLevelDB = db
+		}
 	}
 
-	if gainStr := os.Getenv("PUBLISH_GAIN"); gainStr != "" {
-		if gain, err := strconv.ParseFloat(gainStr, 64); err == nil && gain > 0 {
-			config.PublishGain = gain
+	if coalesceStr := envconfig.Lookup("WS_COALESCE_FRAMES"); coalesceStr != "" {
+		if n, err := strconv.Atoi(coalesceStr); err == nil && n >= 1 {
+			config.WSCoalesceFrames = n
 		}
 	}
 
-	return config
+	if levelStr := envconfig.Lookup("CLIP_LEVEL"); levelStr != "" {
+		if level, err := strconv.Atoi(levelStr); err == nil && level > 0 && level <= 32767 {
+			config.ClipLevel = level
+		}
+	}
+
+	if maxStr := envconfig.Lookup("TONE_MAX_MS"); maxStr != "" {
+		if ms, err := strconv.Atoi(maxStr); err == nil && ms >= 10 {
+			config.MaxToneMs = ms
+		}
+	}
+
+	if cooldownStr := envconfig.Lookup("TONE_COOLDOWN_MS"); cooldownStr != "" {
+		if ms, err := strconv.Atoi(cooldownStr); err == nil && ms >= 0 {
+			config.ToneCooldown = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if frameStr := envconfig.Lookup("INBOUND_FRAME_MS"); frameStr != "" {
+		ms, err := strconv.Atoi(frameStr)
+		if err != nil || ms < 0 || ms > 1000 || ms%10 != 0 {
+			return nil, fmt.Errorf("INBOUND_FRAME_MS must be a multiple of 10 between 0 and 1000, got %q", frameStr)
+		}
+		config.InboundFrameMs = ms
+	}
+
+	if fpStr := envconfig.Lookup("AUDIO_FINGERPRINT_FRAMES"); fpStr != "" {
+		if n, err := strconv.Atoi(fpStr); err == nil && n >= 0 {
+			config.FingerprintFrames = n
+		}
+	}
+
+	if replaceStr := envconfig.Lookup("CLIENT_REPLACE_TIMEOUT_MS"); replaceStr != "" {
+		if ms, err := strconv.Atoi(replaceStr); err == nil && ms > 0 {
+			config.ReplaceTimeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if ttlStr := envconfig.Lookup("MANAGED_TOKEN_TTL_S"); ttlStr != "" {
+		if sec, err := strconv.Atoi(ttlStr); err == nil && sec > 0 {
+			config.ManagedTokenTTL = time.Duration(sec) * time.Second
+		}
+	}
+
+	if timeoutStr := envconfig.Lookup("MP3_INIT_TIMEOUT_MS"); timeoutStr != "" {
+		if ms, err := strconv.Atoi(timeoutStr); err == nil && ms > 0 {
+			config.MP3InitTimeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if progressStr := envconfig.Lookup("PLAY_PROGRESS_MS"); progressStr != "" {
+		if ms, err := strconv.Atoi(progressStr); err == nil && ms >= 0 {
+			config.PlayProgressInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if rotateStr := envconfig.Lookup("RECORD_ROTATE_S"); rotateStr != "" {
+		sec, err := strconv.Atoi(rotateStr)
+		if err != nil || sec < minRecordRotateSec || sec > maxRecordRotateSec {
+			return nil, fmt.Errorf("RECORD_ROTATE_S must be between %d and %d, got %q", minRecordRotateSec, maxRecordRotateSec, rotateStr)
+		}
+		config.RecordRotate = time.Duration(sec) * time.Second
+	}
+
+	if partStr := envconfig.Lookup("RECORD_UPLOAD_PART_MB"); partStr != "" {
+		mb, err := strconv.Atoi(partStr)
+		if err != nil || mb < minUploadPartMB || mb > maxUploadPartMB {
+			return nil, fmt.Errorf("RECORD_UPLOAD_PART_MB must be between %d and %d, got %q", minUploadPartMB, maxUploadPartMB, partStr)
+		}
+		config.RecordUploadPartMB = mb
+	}
+
+	if config.MetricsAddr == "off" {
+		config.MetricsAddr = ""
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
-// Helper function
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// validate checks the fields the bridge can't run without, so a bad
+// deployment fails at startup rather than on the first join
+func (c *Config) validate() error {
+	if _, err := strconv.Atoi(c.Port); err != nil {
+		return fmt.Errorf("PORT must be a number, got %q", c.Port)
+	}
+	if (c.LiveKitAPIKey == "") != (c.LiveKitAPISecret == "") {
+		return fmt.Errorf("LIVEKIT_API_KEY and LIVEKIT_API_SECRET must be set together")
+	}
+	if c.WSAuthSecret != "" && c.WSAuthJWKSURL != "" {
+		return fmt.Errorf("set only one of WS_AUTH_SECRET and WS_AUTH_JWKS_URL")
+	}
+	if c.RecordUploadBucket != "" && (c.RecordUploadAccessKey == "" || c.RecordUploadSecretKey == "") {
+		return fmt.Errorf("RECORD_UPLOAD_BUCKET needs RECORD_UPLOAD_ACCESS_KEY_ID and RECORD_UPLOAD_SECRET_ACCESS_KEY")
+	}
+	if c.RecordUploadEndpoint != "" {
+		if u, err := url.Parse(c.RecordUploadEndpoint); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("RECORD_UPLOAD_ENDPOINT must be an http(s) URL, got %q", c.RecordUploadEndpoint)
+		}
+	}
+	if c.AudioTapURL != "" {
+		if u, err := url.Parse(c.AudioTapURL); err != nil || (u.Scheme != "wss" && u.Scheme != "ws") || u.Host == "" {
+			return fmt.Errorf("AUDIO_TAP_URL must be a ws(s) URL, got %q", c.AudioTapURL)
+		}
+	}
+	if c.WSAuthJWKSURL != "" {
+		if u, err := url.Parse(c.WSAuthJWKSURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("WS_AUTH_JWKS_URL must be an http(s) URL, got %q", c.WSAuthJWKSURL)
+		}
+	}
+	// Unset is allowed: every join_room must then carry its own "url"
+	if c.LiveKitURL == "" {
+		return nil
+	}
+	return validateLiveKitURL(c.LiveKitURL)
+}
+
+// validateLiveKitURL requires a ws:// or wss:// URL with a host
+func validateLiveKitURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("LIVEKIT_URL is not a valid URL: %v", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return fmt.Errorf("LIVEKIT_URL must use ws:// or wss://, got %q", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("LIVEKIT_URL has no host: %q", raw)
 	}
-	return defaultValue
+	return nil
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"hash/crc32"
+	"log"
+	"math"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// Audio fingerprints for "is the right audio flowing" debugging. The first
+// AUDIO_FINGERPRINT_FRAMES frames of each direction are logged in the same
+// compact form, so a frame published by one bridge can be matched against the
+// frame another bridge received.
+
+// fingerprintSamples is how many leading sample values are printed
+const fingerprintSamples = 4
+
+// logFingerprint logs one frame's fingerprint: a CRC32 of the PCM16 bytes,
+// its first few samples and level stats. dir is "pub" or "sub".
+func logFingerprint(userID, dir string, seq int64, pcm []byte) {
+	samples := audio.BytesToInt16(pcm)
+	if len(samples) == 0 {
+		return
+	}
+	minV, maxV := samples[0], samples[0]
+	var sumSq float64
+	for _, s := range samples {
+		if s < minV {
+			minV = s
+		}
+		if s > maxV {
+			maxV = s
+		}
+		sumSq += float64(s) * float64(s)
+	}
+	rms := math.Sqrt(sumSq / float64(len(samples)))
+	head := samples
+	if len(head) > fingerprintSamples {
+		head = head[:fingerprintSamples]
+	}
+	log.Printf("[fingerprint] user=%s dir=%s frame=#%d crc=%08x samples=%d head=%v rms=%.1f min=%d max=%d",
+		userID, dir, seq, crc32.ChecksumIEEE(pcm[:len(samples)*2]), len(samples), head, rms, minV, maxV)
+}
@@ -5,10 +5,20 @@ go 1.24.2
 toolchain go1.24.6
 
 require (
+	github.com/Mentra-Community/MentraOS/cloud/pkg/audio v0.0.0-00010101000000-000000000000
+	github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil v0.0.0-00010101000000-000000000000
+	github.com/Mentra-Community/MentraOS/cloud/pkg/g711track v0.0.0-00010101000000-000000000000
+	github.com/go-jose/go-jose/v3 v3.0.4
 	github.com/gorilla/websocket v1.5.3
 	github.com/hajimehoshi/go-mp3 v0.3.4
+	github.com/livekit/media-sdk v0.0.0-20250518151703-b07af88637c5
 	github.com/livekit/mediatransportutil v0.0.0-20250519131108-fb90f5acfded
+	github.com/livekit/protocol v1.39.4-0.20250807105828-ccbae8154e54
 	github.com/livekit/server-sdk-go/v2 v2.10.0
+	github.com/pion/rtp v1.8.21
+	github.com/pion/webrtc/v4 v4.1.3
+	github.com/prometheus/client_golang v1.22.0
+	go.uber.org/goleak v1.3.0
 )
 
 require (
@@ -19,6 +29,7 @@ require (
 	github.com/antlr4-go/antlr/v4 v4.13.1 // indirect
 	github.com/at-wat/ebml-go v0.17.1 // indirect
 	github.com/benbjohnson/clock v1.3.5 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/bep/debounce v1.2.1 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/dennwc/iters v1.1.0 // indirect
@@ -26,7 +37,6 @@ require (
 	github.com/frostbyte73/core v0.1.1 // indirect
 	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/gammazero/deque v1.1.0 // indirect
-	github.com/go-jose/go-jose/v3 v3.0.4 // indirect
 	github.com/go-logr/logr v1.4.3 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/google/cel-go v0.26.0 // indirect
@@ -36,10 +46,9 @@ require (
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/lithammer/shortuuid/v4 v4.2.0 // indirect
 	github.com/livekit/mageutil v0.0.0-20250511045019-0f1ff63f7731 // indirect
-	github.com/livekit/media-sdk v0.0.0-20250518151703-b07af88637c5 // indirect
-	github.com/livekit/protocol v1.39.4-0.20250807105828-ccbae8154e54 // indirect
 	github.com/livekit/psrpc v0.6.1-0.20250726180611-3915e005e741 // indirect
 	github.com/magefile/mage v1.15.0 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
 	github.com/nats-io/nats.go v1.44.0 // indirect
 	github.com/nats-io/nkeys v0.4.11 // indirect
 	github.com/nats-io/nuid v1.0.1 // indirect
@@ -51,14 +60,15 @@ require (
 	github.com/pion/mdns/v2 v2.0.7 // indirect
 	github.com/pion/randutil v0.1.0 // indirect
 	github.com/pion/rtcp v1.2.15 // indirect
-	github.com/pion/rtp v1.8.21 // indirect
 	github.com/pion/sctp v1.8.39 // indirect
 	github.com/pion/sdp/v3 v3.0.15 // indirect
 	github.com/pion/srtp/v3 v3.0.6 // indirect
 	github.com/pion/stun/v3 v3.0.0 // indirect
 	github.com/pion/transport/v3 v3.0.7 // indirect
 	github.com/pion/turn/v4 v4.0.2 // indirect
-	github.com/pion/webrtc/v4 v4.1.3 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.64.0 // indirect
+	github.com/prometheus/procfs v0.16.1 // indirect
 	github.com/puzpuzpuz/xsync/v3 v3.5.1 // indirect
 	github.com/redis/go-redis/v9 v9.12.0 // indirect
 	github.com/stoewer/go-strcase v1.3.1 // indirect
@@ -83,3 +93,9 @@ require (
 	gopkg.in/hraban/opus.v2 v2.0.0-20230925203106-0188a62cb302 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/Mentra-Community/MentraOS/cloud/pkg/audio => ../pkg/audio
+
+replace github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil => ../pkg/bridgeutil
+
+replace github.com/Mentra-Community/MentraOS/cloud/pkg/g711track => ../pkg/g711track
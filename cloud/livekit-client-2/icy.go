@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// ICY (Icecast/SHOUTcast) internet radio.
+//
+// play_url asks for in-band metadata with "Icy-MetaData: 1". A server that
+// honours it sends icy-metaint and puts a metadata block after every that
+// many bytes of audio; icyReader strips the blocks before the decoder sees
+// them and reports title changes as now_playing events. SHOUTcast v1 answers
+// "ICY 200 OK" instead of an HTTP status line, which playClient accepts.
+
+// maxICYMetaint rejects icy-metaint values no real server sends
+const maxICYMetaint = 1 << 20
+
+// playClient fetches play_url audio. It is http.DefaultClient's transport
+// with SHOUTcast status lines read as HTTP/1.0.
+var playClient = &http.Client{Transport: icyTransport()}
+
+func icyTransport() http.RoundTripper {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	dial := t.DialContext
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &icyConn{Conn: conn}, nil
+	}
+	return t
+}
+
+// icyConn rewrites a leading "ICY " status line to "HTTP/1.0 ". Under TLS it
+// sees the handshake, which never starts that way, and passes it through.
+type icyConn struct {
+	net.Conn
+	checked bool
+	head    []byte
+}
+
+func (c *icyConn) Read(p []byte) (int, error) {
+	if !c.checked {
+		c.checked = true
+		head := make([]byte, 4)
+		n, err := io.ReadFull(c.Conn, head)
+		if n == 0 {
+			return 0, err
+		}
+		c.head = head[:n]
+		if string(c.head) == "ICY " {
+			c.head = []byte("HTTP/1.0 ")
+		}
+	}
+	if len(c.head) > 0 {
+		n := copy(p, c.head)
+		c.head = c.head[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
+// icyMetadata is what a metadata block says is playing
+type icyMetadata struct {
+	Title string
+	URL   string
+}
+
+// icyReader strips interleaved metadata from an ICY stream, calling onChange
+// when the title or URL changes
+type icyReader struct {
+	r        io.Reader
+	metaint  int
+	left     int // audio bytes before the next metadata block
+	last     icyMetadata
+	onChange func(icyMetadata)
+}
+
+func newICYReader(r io.Reader, metaint int, onChange func(icyMetadata)) *icyReader {
+	return &icyReader{r: r, metaint: metaint, left: metaint, onChange: onChange}
+}
+
+func (r *icyReader) Read(p []byte) (int, error) {
+	if r.left == 0 {
+		if err := r.readMetadata(); err != nil {
+			return 0, err
+		}
+		r.left = r.metaint
+	}
+	if len(p) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.r.Read(p)
+	r.left -= n
+	return n, err
+}
+
+// readMetadata consumes one block: a length byte (in 16-byte units) and
+// that much text, usually empty when nothing changed
+func (r *icyReader) readMetadata() error {
+	var size [1]byte
+	if _, err := io.ReadFull(r.r, size[:]); err != nil {
+		return err
+	}
+	if size[0] == 0 {
+		return nil
+	}
+	block := make([]byte, int(size[0])*16)
+	if _, err := io.ReadFull(r.r, block); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return io.EOF // stream ended inside the block
+		}
+		return err
+	}
+	meta := parseICYMetadata(block)
+	if meta != r.last {
+		r.last = meta
+		r.onChange(meta)
+	}
+	return nil
+}
+
+// parseICYMetadata reads StreamTitle and StreamUrl from a block like
+// "StreamTitle='Artist - Song';StreamUrl='https://...';". Titles that aren't UTF-8 are
+// taken as Latin-1, which most SHOUTcast sources send.
+func parseICYMetadata(block []byte) icyMetadata {
+	text := strings.TrimRight(string(block), "\x00")
+	if !utf8.ValidString(text) {
+		runes := make([]rune, len(text))
+		for i := 0; i < len(text); i++ {
+			runes[i] = rune(text[i])
+		}
+		text = string(runes)
+	}
+	return icyMetadata{
+		Title: strings.TrimSpace(icyField(text, "StreamTitle")),
+		URL:   strings.TrimSpace(icyField(text, "StreamUrl")),
+	}
+}
+
+// icyField returns key's quoted value. Values may contain quotes, so one
+// ends at the next "';" (or the last quote).
+func icyField(text, key string) string {
+	i := strings.Index(text, key+"='")
+	if i < 0 {
+		return ""
+	}
+	value := text[i+len(key)+2:]
+	if end := strings.Index(value, "';"); end >= 0 {
+		return value[:end]
+	}
+	return strings.TrimSuffix(value, "'")
+}
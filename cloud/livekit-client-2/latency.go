@@ -0,0 +1,130 @@
+package main
+
+import (
+	"log"
+	"math"
+	"time"
+)
+
+// Latency marker: a short 3kHz burst. 3kHz gives a whole number of cycles
+// per 10ms frame at 16kHz, so a single frame is enough to detect it.
+const (
+	latencyMarkerHz     = 3000
+	latencyMarkerFrames = 5 // 50ms
+	latencyMarkerRatio  = 0.7
+	latencyMarkerMinRMS = 1000
+)
+
+// latencyProbe tracks one in-flight measure_latency request
+type latencyProbe struct {
+	sentAt   time.Time
+	detected chan time.Time
+}
+
+// measureLatency publishes a marker burst and waits for it to come back on
+// the subscribe stream. This measures true media round-trip, so it needs
+// subscribe enabled and a participant that loops our audio back as data
+// packets.
+func (c *BridgeClient) measureLatency(requestID string, timeoutMs int) {
+	if timeoutMs <= 0 {
+		timeoutMs = 5000
+	}
+	result := map[string]interface{}{
+		"type":      "latency_result",
+		"requestId": requestID,
+	}
+
+	if !c.isJoined() {
+		result["error"] = "not_joined"
+		c.sendJSON(result)
+		return
+	}
+	if err := c.ensurePublishTrack(); err != nil {
+		result["error"] = "ensure_track_failed"
+		c.sendJSON(result)
+		return
+	}
+
+	probe := &latencyProbe{detected: make(chan time.Time, 1)}
+	c.mu.Lock()
+	if c.latencyProbe != nil {
+		c.mu.Unlock()
+		result["error"] = "already_measuring"
+		c.sendJSON(result)
+		return
+	}
+	c.latencyProbe = probe
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.latencyProbe = nil
+		c.mu.Unlock()
+	}()
+
+	probe.sentAt = time.Now()
+	frameSamples := 16000 / 100
+	for f := 0; f < latencyMarkerFrames; f++ {
+		frame := make([]int16, frameSamples)
+		for i := range frame {
+			frame[i] = int16(math.Sin(2*math.Pi*latencyMarkerHz*float64(i)/16000) * 0.5 * 32767)
+		}
+		if err := c.writeFrame(frame); err != nil {
+			result["error"] = "publish_failed"
+			c.sendJSON(result)
+			return
+		}
+	}
+
+	select {
+	case at := <-probe.detected:
+		ms := at.Sub(probe.sentAt).Milliseconds()
+		log.Printf("Latency probe for user %s: %dms", c.userID, ms)
+		result["latencyMs"] = ms
+	case <-time.After(time.Duration(timeoutMs) * time.Millisecond):
+		result["error"] = "timeout"
+	case <-c.closed:
+		return
+	}
+	c.sendJSON(result)
+}
+
+// checkLatencyMarker looks for the marker in subscribed PCM while a probe is active
+func (c *BridgeClient) checkLatencyMarker(pcmData []byte, at time.Time) {
+	c.mu.Lock()
+	probe := c.latencyProbe
+	c.mu.Unlock()
+	if probe == nil {
+		return
+	}
+
+	samples := pcmToInt16(pcmData)
+	frameSamples := 16000 / 100
+	for off := 0; off+frameSamples <= len(samples); off += frameSamples {
+		if isLatencyMarker(samples[off : off+frameSamples]) {
+			select {
+			case probe.detected <- at:
+			default:
+			}
+			return
+		}
+	}
+}
+
+// isLatencyMarker reports whether frame is dominated by the marker tone,
+// using a Goertzel filter normalised by the frame energy (1.0 = pure tone)
+func isLatencyMarker(frame []int16) bool {
+	n := float64(len(frame))
+	coeff := 2 * math.Cos(2*math.Pi*latencyMarkerHz/16000)
+	var s1, s2, energy float64
+	for _, v := range frame {
+		x := float64(v)
+		s0 := x + coeff*s1 - s2
+		s2, s1 = s1, s0
+		energy += x * x
+	}
+	if energy == 0 || math.Sqrt(energy/n) < latencyMarkerMinRMS {
+		return false
+	}
+	power := s1*s1 + s2*s2 - coeff*s1*s2
+	return 2*power/(n*energy) >= latencyMarkerRatio
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// Per-sender audio levels for VU meters. Room audio arrives as data packets,
+// so "tracks" here are senders: each forwarded packet is accumulated under
+// its sender identity and a track_levels event reports RMS per sender every
+// interval.
+
+// Bounds for the track_levels interval, in ms
+const (
+	minLevelsIntervalMs     = 50
+	defaultLevelsIntervalMs = 250
+)
+
+// levelMeter accumulates per-sender energy between reports, remembering
+// each sender's SID for the report
+type levelMeter struct {
+	audio.LevelMeter
+
+	mu   sync.Mutex
+	sids map[string]string
+}
+
+// TrackLevel is one entry of a track_levels event
+type TrackLevel struct {
+	Identity string  `json:"identity"`
+	SID      string  `json:"sid,omitempty"`
+	LevelDB  float64 `json:"levelDb"` // RMS in dBFS
+}
+
+func (m *levelMeter) add(identity, sid string, pcm []byte) {
+	samples := audio.BytesToInt16(pcm)
+	if len(samples) == 0 {
+		return
+	}
+	m.Add(identity, samples)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sids == nil {
+		m.sids = make(map[string]string)
+	}
+	m.sids[identity] = sid
+}
+
+// drain returns the levels since the last call, sorted by identity, and
+// resets the meter. Senders that sent nothing are left out.
+func (m *levelMeter) drain() []TrackLevel {
+	dbs := m.Drain()
+	m.mu.Lock()
+	sids := m.sids
+	m.sids = nil
+	m.mu.Unlock()
+
+	levels := make([]TrackLevel, 0, len(dbs))
+	for identity, db := range dbs {
+		levels = append(levels, TrackLevel{Identity: identity, SID: sids[identity], LevelDB: db})
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Identity < levels[j].Identity })
+	return levels
+}
+
+// startTrackLevels replaces any running level reporter with one reporting
+// every intervalMs (0 = default); stopTrackLevels ends it
+func (c *BridgeClient) startTrackLevels(intervalMs int) {
+	if intervalMs == 0 {
+		intervalMs = defaultLevelsIntervalMs
+	}
+	ctx, cancel := context.WithCancel(c.context)
+	c.mu.Lock()
+	if c.levelsCancel != nil {
+		c.levelsCancel()
+	}
+	c.levelsCancel = cancel
+	c.levels = &levelMeter{}
+	meter := c.levels
+	c.mu.Unlock()
+
+	c.spawn(func() {
+		ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			levels := meter.drain()
+			if len(levels) == 0 {
+				continue
+			}
+			c.sendJSON(map[string]interface{}{
+				"type":   "track_levels",
+				"levels": levels,
+			})
+		}
+	})
+}
+
+func (c *BridgeClient) stopTrackLevels() {
+	c.mu.Lock()
+	if c.levelsCancel != nil {
+		c.levelsCancel()
+		c.levelsCancel = nil
+	}
+	c.levels = nil
+	c.mu.Unlock()
+}
+
+// audio_levels reports who is talking: RMS per sender plus a speaking flag,
+// for every sender in the room whether or not its audio is forwarded.
+
+// AudioLevel is one entry of an audio_level event
+type AudioLevel struct {
+	Identity string  `json:"identity"`
+	LevelDB  float64 `json:"levelDb"` // RMS in dBFS
+	Speaking bool    `json:"speaking"`
+}
+
+// startAudioLevels replaces any running audio_level reporter with one
+// reporting every intervalMs (0 = default) and treating senders at or above
+// speakingDB (0 = default) as speaking; stopAudioLevels ends it
+func (c *BridgeClient) startAudioLevels(intervalMs int, speakingDB float64) {
+	if intervalMs == 0 {
+		intervalMs = defaultLevelsIntervalMs
+	}
+	if speakingDB == 0 {
+		speakingDB = audio.DefaultSpeakingDB
+	}
+	ctx, cancel := context.WithCancel(c.context)
+	c.mu.Lock()
+	if c.audioLevelsCancel != nil {
+		c.audioLevelsCancel()
+	}
+	c.audioLevelsCancel = cancel
+	c.audioLevels = &audio.LevelMeter{}
+	meter := c.audioLevels
+	c.mu.Unlock()
+
+	detector := &audio.SpeakingDetector{ThresholdDB: speakingDB, Hold: audio.DefaultSpeakingHold}
+	c.spawn(func() {
+		ticker := time.NewTicker(time.Duration(intervalMs) * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			var now time.Time
+			select {
+			case <-ctx.Done():
+				return
+			case now = <-ticker.C:
+			}
+			sources := detector.Update(meter.Drain(), now)
+			if len(sources) == 0 {
+				continue
+			}
+			levels := make([]AudioLevel, len(sources))
+			for i, s := range sources {
+				levels[i] = AudioLevel{Identity: s.Source, LevelDB: s.LevelDB, Speaking: s.Speaking}
+			}
+			c.sendJSON(map[string]interface{}{
+				"type":   "audio_level",
+				"levels": levels,
+			})
+		}
+	})
+}
+
+func (c *BridgeClient) stopAudioLevels() {
+	c.mu.Lock()
+	if c.audioLevelsCancel != nil {
+		c.audioLevelsCancel()
+		c.audioLevelsCancel = nil
+	}
+	c.audioLevels = nil
+	c.mu.Unlock()
+}
+
+// meterRoomAudio feeds a room audio packet to the audio_level reporter, if
+// one is running
+func (c *BridgeClient) meterRoomAudio(identity string, packet lksdk.DataPacket) {
+	c.mu.Lock()
+	meter := c.audioLevels
+	c.mu.Unlock()
+	if meter == nil {
+		return
+	}
+	userPacket, ok := packet.(*lksdk.UserDataPacket)
+	if !ok {
+		return
+	}
+	pcm := userPacket.Payload
+	if len(pcm)%2 == 1 {
+		pcm = pcm[1:]
+	}
+	meter.Add(identity, audio.BytesToInt16(pcm))
+}
@@ -1,35 +1,116 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"expvar"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// shutdownTimeout bounds how long SIGTERM waits for clients to close and
+// recordings to finish uploading
+const shutdownTimeout = 30 * time.Second
+
 func main() {
-	config := loadConfig()
+	config, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	resampleQuality = config.ResampleQuality
 	service := NewBridgeService(config)
+	var tap *TapForwarder
+	if config.AudioTapURL != "" {
+		tap = NewTapForwarder(config.AudioTapURL)
+		service.SetAudioTap(tap)
+		log.Printf("Forwarding a copy of client audio to %s", config.AudioTapURL)
+	}
+	mux := http.NewServeMux()
 
 	// WebSocket endpoint
-	http.HandleFunc("/ws", service.HandleWebSocket)
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+
+	// Operator endpoints get their own listener, so the public /ws port
+	// never exposes them
+	opsMux := http.NewServeMux()
+
+	// Prometheus metrics (clients, frames in/out/dropped, WS and join errors)
+	registerMetrics(service)
+	opsMux.Handle("/metrics", promhttp.Handler())
+
+	// Runtime counters (frames published/forwarded/dropped, active clients)
+	if config.DebugVarsEnabled {
+		publishClientCount(service)
+		opsMux.Handle("/debug/vars", expvar.Handler())
+	}
 
 	// Health check endpoint
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		service.mu.RLock()
-		clientCount := len(service.clients)
-		service.mu.RUnlock()
+		clients := service.clients.Stats()
 
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status":      "healthy",
-			"connections": clientCount,
+			"status":          "healthy",
+			"connections":     clients.Size,
+			"peakConnections": clients.Peak,
+			"totalConnected":  clients.Created,
 		})
 	})
 
 	log.Printf("LiveKit Bridge starting on port %s", config.Port)
-	log.Printf("Configuration: LiveKitURL=%s", config.LiveKitURL)
+	if config.WSAuthSecret == "" && config.WSAuthJWKSURL == "" {
+		log.Printf("WARNING: /ws is unauthenticated; set WS_AUTH_SECRET or WS_AUTH_JWKS_URL to require a bearer token")
+	}
+	if config.LiveKitURL == "" {
+		log.Printf("WARNING: LIVEKIT_URL is not set; join_room will fail unless the request carries a \"url\"")
+	} else {
+		log.Printf("Configuration: LiveKitURL=%s", config.LiveKitURL)
+	}
+
+	server := &http.Server{Addr: ":" + config.Port, Handler: mux}
+	var opsServer *http.Server
+	if config.MetricsAddr != "" {
+		opsServer = &http.Server{Addr: config.MetricsAddr, Handler: opsMux}
+		go func() {
+			log.Printf("Serving /metrics on %s", config.MetricsAddr)
+			if err := opsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("METRICS_ADDR: %v", err)
+			}
+		}()
+	}
+
+	// Handle graceful shutdown
+	stopped := make(chan struct{})
+	go func() {
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		<-sigCh
+		log.Println("Received shutdown signal, gracefully stopping...")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		// WebSockets are hijacked, so Shutdown leaves them to service.Shutdown
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("HTTP shutdown: %v", err)
+		}
+		if opsServer != nil {
+			opsServer.Close()
+		}
+		service.Shutdown(ctx)
+		if tap != nil {
+			tap.Close()
+		}
+		close(stopped)
+	}()
 
-	if err := http.ListenAndServe(":"+config.Port, nil); err != nil {
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
+	<-stopped
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/livekit/media-sdk"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/pion/webrtc/v4"
+	"go.uber.org/goleak"
+)
+
+// TestMain fails the run if any test leaves goroutines behind, such as a
+// client whose Close doesn't stop everything it spawned
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+// dialClient connects userID to srv's /ws and reads the connected event
+func dialClient(t *testing.T, srv *httptest.Server, userID string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?userId=" + userID
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evt map[string]interface{}
+	if err := conn.ReadJSON(&evt); err != nil || evt["type"] != "connected" {
+		t.Fatalf("first message = %v, %v; want the connected event", evt, err)
+	}
+	return conn
+}
+
+// waitForClients waits until the service has n clients
+func waitForClients(t *testing.T, service *BridgeService, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for service.clients.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d clients registered, want %d", service.clients.Len(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// fakeTrack stands in for the publish track and keeps what was written;
+// write, if set, decides what each frame write returns
+type fakeTrack struct {
+	webrtc.TrackLocal
+	write func() error
+
+	mu     sync.Mutex
+	frames [][]int16
+}
+
+func (t *fakeTrack) WriteSample(s media.PCM16Sample) error {
+	t.mu.Lock()
+	t.frames = append(t.frames, append([]int16(nil), s...))
+	t.mu.Unlock()
+	if t.write != nil {
+		return t.write()
+	}
+	return nil
+}
+
+func (t *fakeTrack) Close() {}
+
+// written returns the frames written so far
+func (t *fakeTrack) written() [][]int16 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([][]int16(nil), t.frames...)
+}
+
+// fakeJoin makes c look joined to a room at the default rates, publishing
+// to the returned track. Call the returned func before the client closes:
+// the placeholder room can't be disconnected.
+func fakeJoin(c *BridgeClient) (*fakeTrack, func()) {
+	track := &fakeTrack{}
+	c.mu.Lock()
+	c.room = &lksdk.Room{}
+	c.connected = true
+	c.publishTrack = track
+	c.inputRate = publishSampleRate
+	c.trackRate = publishSampleRate
+	c.mu.Unlock()
+	return track, func() {
+		c.mu.Lock()
+		c.room = nil
+		c.publishTrack = nil
+		c.mu.Unlock()
+	}
+}
+
+// TestClientLifecycle connects, replaces and disconnects clients without a
+// room; TestMain then checks their goroutines are gone
+func TestClientLifecycle(t *testing.T) {
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	first := dialClient(t, srv, "user-1")
+	defer first.Close()
+	if err := first.WriteJSON(map[string]string{"action": "leave_room"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reconnect for the same user replaces the first client
+	second := dialClient(t, srv, "user-1")
+	waitForClients(t, service, 1)
+	second.Close()
+	waitForClients(t, service, 0)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// metadataFilter matches participants whose JSON metadata has a top-level
+// field equal to a value, e.g. "role=presenter"
+type metadataFilter struct {
+	key   string
+	value string
+
+	mu    sync.Mutex
+	cache map[string]bool // raw metadata -> match, since it rarely changes
+}
+
+func parseMetadataFilter(expr string) (*metadataFilter, error) {
+	key, value, ok := strings.Cut(expr, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return nil, fmt.Errorf("metadataFilter must be key=value, got %q", expr)
+	}
+	return &metadataFilter{
+		key:   key,
+		value: strings.TrimSpace(value),
+		cache: make(map[string]bool),
+	}, nil
+}
+
+// match reports whether the participant metadata satisfies the filter.
+// Metadata that isn't a JSON object never matches.
+func (f *metadataFilter) match(metadata string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if m, ok := f.cache[metadata]; ok {
+		return m
+	}
+
+	var fields map[string]interface{}
+	matched := false
+	if err := json.Unmarshal([]byte(metadata), &fields); err == nil {
+		if v, ok := fields[f.key]; ok {
+			matched = fmt.Sprint(v) == f.value
+		}
+	}
+	f.cache[metadata] = matched
+	return matched
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus metrics served on /metrics, on METRICS_ADDR rather than the
+// public WebSocket port. Per-client series are labelled by a keyed hash of
+// the userId (METRICS_LABEL_KEY), so labels can't be matched against a
+// list of guessed ids, and are removed when the user's last client goes
+// away so the label set stays bounded.
+
+const metricsNamespace = "livekit_bridge"
+
+var (
+	promFramesIn = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "audio_frames_in_total",
+		Help:      "10ms audio frames received over WS and written to the publish track (Opus packets count their length in 10ms frames).",
+	}, []string{"user"})
+	promFramesOut = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "audio_frames_out_total",
+		Help:      "Forwarded room audio messages written to the WS.",
+	}, []string{"user"})
+	promFramesDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "dropped_frames_total",
+		Help:      "Forwarded frames dropped by the pacing queue, mixer or bandwidth limit.",
+	}, []string{"user"})
+	promWSWriteErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "ws_write_errors_total",
+		Help:      "Failed WS writes (each closes the client).",
+	}, []string{"user"})
+	promJoinFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "room_join_failures_total",
+		Help:      "join_room / switch_room attempts that failed to connect.",
+	}, []string{"user"})
+)
+
+var perUserMetrics = []*prometheus.CounterVec{promFramesIn, promFramesOut, promFramesDropped, promWSWriteErrors, promJoinFailures}
+
+// labelKey keys userLabel; set by registerMetrics
+var labelKey []byte
+
+// registerMetrics registers the collectors, including the connected client
+// gauge read from s
+func registerMetrics(s *BridgeService) {
+	labelKey = []byte(s.config.MetricsLabelKey)
+	if len(labelKey) == 0 {
+		// Labels then only stay stable for the life of the process
+		labelKey = make([]byte, 32)
+		rand.Read(labelKey)
+	}
+	for _, vec := range perUserMetrics {
+		prometheus.MustRegister(vec)
+	}
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "connected_clients",
+		Help:      "WS clients currently connected.",
+	}, func() float64 { return float64(s.clients.Len()) }))
+}
+
+// userLabel is the metrics label for userID
+func userLabel(userID string) string {
+	mac := hmac.New(sha256.New, labelKey)
+	mac.Write([]byte(userID))
+	return hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// clientMetrics are one client's counters, bound to its user label
+type clientMetrics struct {
+	label        string
+	framesIn     prometheus.Counter
+	framesOut    prometheus.Counter
+	dropped      prometheus.Counter
+	wsErrors     prometheus.Counter
+	joinFailures prometheus.Counter
+}
+
+// A reconnecting user briefly has two clients sharing one label, so series
+// are only deleted when the last of them is released
+var (
+	labelRefsMu sync.Mutex
+	labelRefs   = make(map[string]int)
+)
+
+func newClientMetrics(userID string) *clientMetrics {
+	label := userLabel(userID)
+	labelRefsMu.Lock()
+	labelRefs[label]++
+	labelRefsMu.Unlock()
+	return &clientMetrics{
+		label:        label,
+		framesIn:     promFramesIn.WithLabelValues(label),
+		framesOut:    promFramesOut.WithLabelValues(label),
+		dropped:      promFramesDropped.WithLabelValues(label),
+		wsErrors:     promWSWriteErrors.WithLabelValues(label),
+		joinFailures: promJoinFailures.WithLabelValues(label),
+	}
+}
+
+// release drops the client's series once no other client uses its label
+func (m *clientMetrics) release() {
+	labelRefsMu.Lock()
+	defer labelRefsMu.Unlock()
+	labelRefs[m.label]--
+	if labelRefs[m.label] > 0 {
+		return
+	}
+	delete(labelRefs, m.label)
+	for _, vec := range perUserMetrics {
+		vec.DeleteLabelValues(m.label)
+	}
+}
+
+// addDropped counts n dropped frames; usable as a PacingBuffer/Mixer onDrop
+func (m *clientMetrics) addDropped(n int) {
+	m.dropped.Add(float64(n))
+}
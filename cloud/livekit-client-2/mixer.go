@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Mixer sums per-sender PCM16 streams into a single mono stream on a fixed tick
+type Mixer struct {
+	buffers      map[string][]int16
+	mu           sync.Mutex
+	ticker       *time.Ticker
+	quit         chan struct{}
+	sendFunc     func([]byte)
+	interval     time.Duration
+	frameSamples int
+	maxSamples   int
+	wg           sync.WaitGroup
+	stopOnce     sync.Once
+	onDrop       func(n int) // called with frames dropped from a sender running ahead (optional)
+}
+
+func NewMixer(interval time.Duration, sampleRate int, maxFrames int, sendFunc func([]byte)) *Mixer {
+	frameSamples := int(int64(sampleRate) * int64(interval) / int64(time.Second))
+	return &Mixer{
+		buffers:      make(map[string][]int16),
+		interval:     interval,
+		frameSamples: frameSamples,
+		maxSamples:   frameSamples * maxFrames,
+		sendFunc:     sendFunc,
+		quit:         make(chan struct{}),
+	}
+}
+
+func (m *Mixer) Start() {
+	m.ticker = time.NewTicker(m.interval)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for {
+			select {
+			case <-m.ticker.C:
+				m.mixNext()
+			case <-m.quit:
+				m.ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the worker and waits for it to exit
+func (m *Mixer) Stop() {
+	m.stopOnce.Do(func() { close(m.quit) })
+	m.wg.Wait()
+}
+
+// Add appends PCM16 LE data to the sender's buffer
+func (m *Mixer) Add(sender string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf := m.buffers[sender]
+	for i := 0; i+1 < len(data); i += 2 {
+		buf = append(buf, int16(binary.LittleEndian.Uint16(data[i:])))
+	}
+	// If a sender runs too far ahead, drop its oldest samples
+	if len(buf) > m.maxSamples {
+		dropped := (len(buf) - m.maxSamples + m.frameSamples - 1) / m.frameSamples
+		statFramesDropped.Add(int64(dropped))
+		if m.onDrop != nil {
+			m.onDrop(dropped)
+		}
+		buf = buf[len(buf)-m.maxSamples:]
+	}
+	m.buffers[sender] = buf
+}
+
+// Flush discards all buffered sender audio
+func (m *Mixer) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.buffers = make(map[string][]int16)
+}
+
+func (m *Mixer) mixNext() {
+	m.mu.Lock()
+	if len(m.buffers) == 0 {
+		m.mu.Unlock()
+		return
+	}
+	sum := make([]int32, m.frameSamples)
+	n := 0
+	for sender, buf := range m.buffers {
+		take := len(buf)
+		if take > m.frameSamples {
+			take = m.frameSamples
+		}
+		for i := 0; i < take; i++ {
+			sum[i] += int32(buf[i])
+		}
+		if take > n {
+			n = take
+		}
+		if take == len(buf) {
+			delete(m.buffers, sender)
+		} else {
+			m.buffers[sender] = buf[take:]
+		}
+	}
+	m.mu.Unlock()
+
+	if n == 0 {
+		return
+	}
+	sum = sum[:n]
+
+	// Limit: scale the whole frame down instead of hard-clipping the peaks
+	var peak int32
+	for _, v := range sum {
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+	scale := 1.0
+	if peak > 32767 {
+		scale = 32767.0 / float64(peak)
+	}
+	out := make([]byte, n*2)
+	for i, v := range sum {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(float64(v)*scale)))
+	}
+	m.sendFunc(out)
+}
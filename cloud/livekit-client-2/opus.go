@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/livekit/media-sdk"
+	"github.com/pion/webrtc/v4"
+	pionmedia "github.com/pion/webrtc/v4/pkg/media"
+)
+
+// Pre-encoded Opus publishing. Clients that already have Opus (a browser
+// MediaRecorder, a SIP leg) join with inputFormat "opus" and send one packet
+// per binary message; packets go onto the track as-is instead of being
+// decoded to PCM and re-encoded.
+
+// inputFormatOpus is the join inputFormat for pre-encoded Opus
+const inputFormatOpus = "opus"
+
+// Type byte that starts each binary message in Opus mode. Other values are
+// reserved.
+const opusPacketType = 0x01
+
+var (
+	errEncodedTrack   = errors.New("publish track takes pre-encoded opus; PCM sources are unavailable")
+	errNotOpusPublish = errors.New("publish track is not in opus mode")
+)
+
+// opusTrack publishes Opus packets without transcoding. It satisfies
+// audioTrack so it is published and closed like the PCM tracks, but rejects
+// PCM writes (tones, play_url).
+type opusTrack struct {
+	*webrtc.TrackLocalStaticSample
+}
+
+func newOpusTrack() (*opusTrack, error) {
+	id := strconv.FormatInt(time.Now().UnixNano(), 36)
+	track, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: 48000, Channels: 2},
+		"go_track"+id, "go_stream"+id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &opusTrack{TrackLocalStaticSample: track}, nil
+}
+
+func (t *opusTrack) WriteSample(media.PCM16Sample) error {
+	return errEncodedTrack
+}
+
+func (t *opusTrack) Close() {}
+
+// writePacket sends one Opus packet, timed by the duration in its TOC, and
+// returns that duration
+func (t *opusTrack) writePacket(packet []byte) (time.Duration, error) {
+	d, err := opusPacketDuration(packet)
+	if err != nil {
+		return 0, err
+	}
+	return d, t.TrackLocalStaticSample.WriteSample(pionmedia.Sample{Data: packet, Duration: d})
+}
+
+// opusFrameTenthsMs is the frame duration, in tenths of a ms, of each TOC config
+// (RFC 6716 section 3.1): SILK 0-11, hybrid 12-15, CELT 16-31
+var opusFrameTenthsMs = [32]int{
+	100, 200, 400, 600, 100, 200, 400, 600, 100, 200, 400, 600,
+	100, 200, 100, 200,
+	25, 50, 100, 200, 25, 50, 100, 200, 25, 50, 100, 200, 25, 50, 100, 200,
+}
+
+// opusPacketDuration reads the audio duration of an Opus packet from its TOC
+// byte and frame count
+func opusPacketDuration(packet []byte) (time.Duration, error) {
+	if len(packet) == 0 {
+		return 0, errors.New("empty opus packet")
+	}
+	toc := packet[0]
+	frames := 1
+	switch toc & 0x03 {
+	case 1, 2:
+		frames = 2
+	case 3:
+		if len(packet) < 2 {
+			return 0, errors.New("opus packet missing frame count")
+		}
+		frames = int(packet[1] & 0x3f)
+		if frames == 0 {
+			return 0, errors.New("opus packet has zero frames")
+		}
+	}
+	d := time.Duration(opusFrameTenthsMs[toc>>3]*frames) * 100 * time.Microsecond
+	if d > 120*time.Millisecond {
+		return 0, fmt.Errorf("opus packet too long (%v)", d)
+	}
+	return d, nil
+}
+
+// handleOpusMessage publishes one binary message in Opus mode: a type byte
+// followed by a single Opus packet
+func (c *BridgeClient) handleOpusMessage(data []byte) {
+	if len(data) < 2 || data[0] != opusPacketType {
+		log.Printf("Dropping malformed opus message from user %s (%d bytes)", c.userID, len(data))
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	track, ok := c.publishTrack.(*opusTrack)
+	if !ok {
+		log.Printf("Cannot send opus for user %s: %v", c.userID, errNotOpusPublish)
+		return
+	}
+	d, err := track.writePacket(data[1:])
+	if err != nil {
+		log.Printf("Failed to write opus packet for user %s: %v", c.userID, err)
+		return
+	}
+	c.metrics.framesIn.Add(d.Seconds() * 100) // in 10ms frames, like PCM
+}
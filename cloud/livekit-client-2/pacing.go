@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/binary"
 	"sync"
 	"time"
 )
@@ -14,13 +15,46 @@ type PacingBuffer struct {
 	sendFunc func([]byte)
 	interval time.Duration
 	maxSize  int
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+
+	// Coalescing: collect this many paced frames into one WS message
+	coalesce int
+	batch    [][]byte
+
+	// Called with the number of frames dropped from a full queue (optional)
+	onDrop func(n int)
+}
+
+// defaultPacingInterval paces frames out when INBOUND_FRAME_MS is 0 and
+// payloads are forwarded as received
+const defaultPacingInterval = 100 * time.Millisecond
+
+// pacingQueueDepth is how much audio the inbound pacer holds before it
+// drops the oldest frame
+const pacingQueueDepth = time.Second
+
+// newInboundPacer returns a pacer ticking once per reframed inbound frame
+// (frameMs, 0 = the default interval) that queues at least
+// pacingQueueDepth of audio
+func newInboundPacer(frameMs int, coalesce int, sendFunc func([]byte)) *PacingBuffer {
+	interval := defaultPacingInterval
+	if frameMs > 0 {
+		interval = time.Duration(frameMs) * time.Millisecond
+	}
+	maxSize := max(int(pacingQueueDepth/interval), 10)
+	return NewPacingBuffer(interval, maxSize, coalesce, sendFunc)
 }
 
-func NewPacingBuffer(interval time.Duration, maxSize int, sendFunc func([]byte)) *PacingBuffer {
+func NewPacingBuffer(interval time.Duration, maxSize int, coalesce int, sendFunc func([]byte)) *PacingBuffer {
+	if coalesce < 1 {
+		coalesce = 1
+	}
 	return &PacingBuffer{
 		queue:    make([][]byte, 0),
 		interval: interval,
 		maxSize:  maxSize,
+		coalesce: coalesce,
 		sendFunc: sendFunc,
 		quit:     make(chan struct{}),
 	}
@@ -28,7 +62,9 @@ func NewPacingBuffer(interval time.Duration, maxSize int, sendFunc func([]byte))
 
 func (pb *PacingBuffer) Start() {
 	pb.ticker = time.NewTicker(pb.interval)
+	pb.wg.Add(1)
 	go func() {
+		defer pb.wg.Done()
 		for {
 			select {
 			case <-pb.ticker.C:
@@ -41,7 +77,35 @@ func (pb *PacingBuffer) Start() {
 	}()
 }
 
-func (pb *PacingBuffer) Stop() { close(pb.quit) }
+// Stop halts the worker and waits for it and any in-flight sends to finish.
+// Frames still queued, and a partly filled coalesced batch, are then sent
+// straight away so the end of the stream isn't lost.
+func (pb *PacingBuffer) Stop() {
+	pb.stopOnce.Do(func() {
+		close(pb.quit)
+		pb.wg.Wait()
+		pb.mu.Lock()
+		tail := append(pb.batch, pb.queue...)
+		pb.batch, pb.queue = nil, nil
+		pb.mu.Unlock()
+		pb.sendTail(tail)
+	})
+	pb.wg.Wait()
+}
+
+// sendTail sends the frames left at Stop, in batches of coalesce
+func (pb *PacingBuffer) sendTail(frames [][]byte) {
+	for len(frames) > 0 {
+		if pb.coalesce == 1 {
+			pb.sendFunc(frames[0])
+			frames = frames[1:]
+			continue
+		}
+		n := min(pb.coalesce, len(frames))
+		pb.sendFunc(encodeCoalesced(frames[:n]))
+		frames = frames[n:]
+	}
+}
 
 func (pb *PacingBuffer) Add(data []byte) {
 	pb.mu.Lock()
@@ -54,10 +118,22 @@ func (pb *PacingBuffer) Add(data []byte) {
 	// If queue is full, drop oldest
 	if len(pb.queue) >= pb.maxSize {
 		pb.queue = pb.queue[1:]
+		statFramesDropped.Add(1)
+		if pb.onDrop != nil {
+			pb.onDrop(1)
+		}
 	}
 	pb.queue = append(pb.queue, dataCopy)
 }
 
+// Flush discards queued and partially coalesced frames
+func (pb *PacingBuffer) Flush() {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.queue = pb.queue[:0]
+	pb.batch = nil
+}
+
 func (pb *PacingBuffer) sendNext() {
 	pb.mu.Lock()
 	defer pb.mu.Unlock()
@@ -65,7 +141,40 @@ func (pb *PacingBuffer) sendNext() {
 	if len(pb.queue) > 0 {
 		data := pb.queue[0]
 		pb.queue = pb.queue[1:]
+		if pb.coalesce > 1 {
+			// Frames keep their paced cadence; only every Nth tick writes
+			pb.batch = append(pb.batch, data)
+			if len(pb.batch) < pb.coalesce {
+				return
+			}
+			data = encodeCoalesced(pb.batch)
+			pb.batch = nil
+		}
 		// Send outside of lock to avoid blocking
-		go pb.sendFunc(data)
+		pb.wg.Add(1)
+		go func() {
+			defer pb.wg.Done()
+			pb.sendFunc(data)
+		}()
+	}
+}
+
+// encodeCoalesced packs frames into one message:
+// [count u16 LE][len u32 LE]*count followed by the frame payloads.
+// Lengths are u32 since a 48kHz f32le stereo frame of 100ms or more
+// doesn't fit in a u16.
+func encodeCoalesced(frames [][]byte) []byte {
+	size := 2 + 4*len(frames)
+	for _, f := range frames {
+		size += len(f)
+	}
+	out := make([]byte, size)
+	binary.LittleEndian.PutUint16(out[0:2], uint16(len(frames)))
+	off := 2 + 4*len(frames)
+	for i, f := range frames {
+		binary.LittleEndian.PutUint32(out[2+i*4:], uint32(len(f)))
+		copy(out[off:], f)
+		off += len(f)
 	}
+	return out
 }
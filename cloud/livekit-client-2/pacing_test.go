@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPacingBufferStopSendsTail queues more frames than get paced out and
+// checks Stop sends the rest, with the last coalesced batch short
+func TestPacingBufferStopSendsTail(t *testing.T) {
+	var mu sync.Mutex
+	var sent [][]byte
+	pb := NewPacingBuffer(time.Hour, 10, 3, func(data []byte) {
+		mu.Lock()
+		sent = append(sent, data)
+		mu.Unlock()
+	})
+	pb.Start()
+	for i := 0; i < 4; i++ {
+		pb.Add(bytes.Repeat([]byte{byte(i)}, 4))
+	}
+	pb.Stop()
+
+	if len(sent) != 2 {
+		t.Fatalf("%d messages sent, want 2", len(sent))
+	}
+	for i, want := range []int{3, 1} {
+		if n := int(binary.LittleEndian.Uint16(sent[i])); n != want {
+			t.Errorf("message %d holds %d frames, want %d", i, n, want)
+		}
+	}
+	if last := sent[1]; !bytes.Equal(last[6:], []byte{3, 3, 3, 3}) {
+		t.Errorf("last message = %v, want frame 3", last)
+	}
+
+	// Stopping again sends nothing more
+	pb.Stop()
+	if len(sent) != 2 {
+		t.Errorf("second Stop sent %d more messages", len(sent)-2)
+	}
+}
+
+// TestInboundPacerKeepsUp feeds 1s of 20ms frames in real time and checks
+// the pacer, ticking at the frame rate, drops none of them
+func TestInboundPacerKeepsUp(t *testing.T) {
+	var mu sync.Mutex
+	var sent, dropped int
+	pb := newInboundPacer(20, 1, func([]byte) {
+		mu.Lock()
+		sent++
+		mu.Unlock()
+	})
+	pb.onDrop = func(n int) {
+		mu.Lock()
+		dropped += n
+		mu.Unlock()
+	}
+	pb.Start()
+
+	const frames = 50
+	tick := time.NewTicker(20 * time.Millisecond)
+	for i := 0; i < frames; i++ {
+		pb.Add(make([]byte, 640))
+		<-tick.C
+	}
+	tick.Stop()
+	pb.Stop()
+
+	if dropped != 0 {
+		t.Errorf("%d of %d frames dropped", dropped, frames)
+	}
+	if sent != frames {
+		t.Errorf("%d frames sent, want %d", sent, frames)
+	}
+}
+
+// TestEncodeCoalescedLargeFrames checks frame lengths past 65535 bytes
+// survive the batch header
+func TestEncodeCoalescedLargeFrames(t *testing.T) {
+	// 200ms of 48kHz f32le stereo
+	big := bytes.Repeat([]byte{1}, 48000*2*4/5)
+	small := []byte{2, 2}
+	msg := encodeCoalesced([][]byte{big, small})
+
+	if n := binary.LittleEndian.Uint16(msg); n != 2 {
+		t.Fatalf("count = %d, want 2", n)
+	}
+	off := 2 + 4*2
+	for i, want := range [][]byte{big, small} {
+		n := int(binary.LittleEndian.Uint32(msg[2+i*4:]))
+		if n != len(want) {
+			t.Fatalf("frame %d length = %d, want %d", i, n, len(want))
+		}
+		if !bytes.Equal(msg[off:off+n], want) {
+			t.Errorf("frame %d payload mismatch", i)
+		}
+		off += n
+	}
+	if off != len(msg) {
+		t.Errorf("%d trailing bytes", len(msg)-off)
+	}
+}
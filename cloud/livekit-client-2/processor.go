@@ -0,0 +1,150 @@
+package main
+
+import (
+	"log"
+	"math"
+	"strings"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// AudioProcessor transforms one frame of 16kHz mono PCM16 before it is published.
+// It may modify frame in place and may return a different length; returning
+// an empty slice drops the frame.
+type AudioProcessor interface {
+	Process(frame []int16) []int16
+}
+
+// ProcessorChain runs processors in order, feeding each the previous output
+type ProcessorChain []AudioProcessor
+
+func (c ProcessorChain) Process(frame []int16) []int16 {
+	for _, p := range c {
+		if len(frame) == 0 {
+			return frame
+		}
+		frame = p.Process(frame)
+	}
+	return frame
+}
+
+// processorFactories maps AUDIO_PROCESSORS names to constructors. Processors
+// may keep state between frames, so a fresh instance is built per client.
+var processorFactories = map[string]func(cfg *Config) AudioProcessor{
+	"gain":    func(cfg *Config) AudioProcessor { return &GainProcessor{Gain: cfg.PublishGain} },
+	"dcblock": func(cfg *Config) AudioProcessor { return &DCBlockProcessor{} },
+	"noisegate": func(cfg *Config) AudioProcessor {
+		return &NoiseGateProcessor{Threshold: math.Pow(10, cfg.NoiseGateDB/20) * 32767}
+	},
+	"watermark": func(cfg *Config) AudioProcessor {
+		return &WatermarkProcessor{
+			FreqHz:     cfg.WatermarkHz,
+			Level:      math.Pow(10, cfg.WatermarkLevelDB/20),
+			SampleRate: 16000,
+		}
+	},
+}
+
+// parseProcessorNames splits a comma-separated processor list, dropping
+// unknown names with a warning
+func parseProcessorNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := processorFactories[name]; !ok {
+			log.Printf("Unknown audio processor %q, skipping", name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// newProcessorChain builds the configured chain
+func newProcessorChain(cfg *Config) ProcessorChain {
+	chain := make(ProcessorChain, 0, len(cfg.AudioProcessors))
+	for _, name := range cfg.AudioProcessors {
+		chain = append(chain, processorFactories[name](cfg))
+	}
+	return chain
+}
+
+// GainProcessor scales samples by a fixed factor with clipping
+type GainProcessor struct {
+	Gain float64
+}
+
+func (g *GainProcessor) Process(frame []int16) []int16 {
+	audio.ApplyGain(frame, g.Gain)
+	return frame
+}
+
+// WatermarkProcessor mixes a very low-level, high-frequency sine into every
+// frame so a downstream monitor can confirm the media path is alive
+type WatermarkProcessor struct {
+	FreqHz     float64
+	Level      float64 // linear amplitude relative to full scale
+	SampleRate float64
+	phase      float64
+}
+
+func (w *WatermarkProcessor) Process(frame []int16) []int16 {
+	step := 2 * math.Pi * w.FreqHz / w.SampleRate
+	amp := w.Level * 32767
+	for i := range frame {
+		v := float64(frame[i]) + amp*math.Sin(w.phase)
+		if v > 32767 {
+			v = 32767
+		} else if v < -32768 {
+			v = -32768
+		}
+		frame[i] = int16(v)
+		w.phase += step
+	}
+	// Keep phase bounded so precision doesn't drift on long sessions
+	w.phase = math.Mod(w.phase, 2*math.Pi)
+	return frame
+}
+
+// DCBlockProcessor removes DC offset with a one-pole high-pass filter
+type DCBlockProcessor struct {
+	prevIn  float64
+	prevOut float64
+}
+
+func (d *DCBlockProcessor) Process(frame []int16) []int16 {
+	const r = 0.995 // ~13Hz corner at 16kHz
+	for i, s := range frame {
+		x := float64(s)
+		y := x - d.prevIn + r*d.prevOut
+		d.prevIn, d.prevOut = x, y
+		if y > 32767 {
+			y = 32767
+		} else if y < -32768 {
+			y = -32768
+		}
+		frame[i] = int16(y)
+	}
+	return frame
+}
+
+// NoiseGateProcessor silences frames whose RMS is below Threshold
+type NoiseGateProcessor struct {
+	Threshold float64 // linear RMS, full scale = 32767
+}
+
+func (g *NoiseGateProcessor) Process(frame []int16) []int16 {
+	var sum float64
+	for _, s := range frame {
+		sum += float64(s) * float64(s)
+	}
+	if math.Sqrt(sum/float64(len(frame))) < g.Threshold {
+		for i := range frame {
+			frame[i] = 0
+		}
+	}
+	return frame
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// playProgress sends a play_url's play_progress events: positionMs, how far
+// into the URL the listener is, and durationMs once the decoder knows the
+// total length (WAV only). The position leaves out what is still queued
+// ahead of real time, and nothing is sent while it doesn't move.
+type playProgress struct {
+	duration atomic.Int64 // ms; 0 until known
+
+	stopOnce sync.Once
+	done     chan struct{}
+	exited   chan struct{}
+}
+
+// startProgress reports cmd's progress every interval until stop. Items of
+// a play_queue share a pacer, so the position counts from where it is now.
+func (p *Publisher) startProgress(pacer *audio.Pacer, cmd PlayURLCmd, interval time.Duration) *playProgress {
+	pp := &playProgress{done: make(chan struct{}), exited: make(chan struct{})}
+	base := pacer.Written()
+	p.client.spawn(func() {
+		defer close(pp.exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last int64
+		for {
+			select {
+			case <-pp.done:
+				return
+			case <-ticker.C:
+			}
+			played := pacer.Position() - base
+			if played <= 0 || played.Milliseconds() == last {
+				continue
+			}
+			last = played.Milliseconds()
+			evt := map[string]interface{}{
+				"type":       "play_progress",
+				"requestId":  cmd.RequestID,
+				"positionMs": last,
+			}
+			if d := pp.duration.Load(); d > 0 {
+				evt["durationMs"] = d
+			}
+			if cmd.done.index >= 0 {
+				evt["index"] = cmd.done.index
+			}
+			p.client.sendJSON(evt)
+		}
+	})
+	return pp
+}
+
+// setDuration records the total length of the audio
+func (pp *playProgress) setDuration(d time.Duration) {
+	if pp != nil && d > 0 {
+		pp.duration.Store(d.Milliseconds())
+	}
+}
+
+// stop ends the reports, waiting for one in flight so none follows
+// play_complete
+func (pp *playProgress) stop() {
+	if pp == nil {
+		return
+	}
+	pp.stopOnce.Do(func() { close(pp.done) })
+	<-pp.exited
+}
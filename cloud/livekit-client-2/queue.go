@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+)
+
+// play_queue plays several URLs back to back on the publish track as one
+// request. Each item gets its own play_started and play_complete (carrying
+// the item's index), then a queue_complete ends the request. A failed item
+// doesn't stop the queue; stop_playback or a new play request cancels the
+// rest, and the skipped items still get a cancelled play_complete.
+
+// maxQueueItems bounds a single play_queue
+const maxQueueItems = 100
+
+// PlayQueue plays items in order as request requestID. Pause, resume and
+// stop apply to the queue as a whole. The shared pacer carries on from one
+// item to the next, so there is no gap beyond the next item's fetch.
+func (p *Publisher) PlayQueue(requestID string, items []PlayURLCmd) {
+	p.start(requestID, func(ctx context.Context, pacer *audio.Pacer) {
+		p.runQueue(ctx, pacer, requestID, items)
+	})
+}
+
+func (p *Publisher) runQueue(ctx context.Context, pacer *audio.Pacer, requestID string, items []PlayURLCmd) {
+	log.Printf("play_queue start: reqId=%s items=%d", requestID, len(items))
+	var played, failed int
+	for i, item := range items {
+		item.RequestID = requestID
+		item.done = &playCompletion{client: p.client, requestID: requestID, index: i}
+		if ctx.Err() != nil {
+			item.complete(false, 0, playerr.Cancelled, "cancelled")
+		} else {
+			p.HandlePlayURL(ctx, pacer, item)
+		}
+		if item.done.success {
+			played++
+		} else {
+			failed++
+		}
+	}
+	log.Printf("play_queue done: reqId=%s played=%d failed=%d", requestID, played, failed)
+	p.client.sendJSON(map[string]interface{}{
+		"type":      "queue_complete",
+		"requestId": requestID,
+		"played":    played,
+		"failed":    failed,
+		"cancelled": ctx.Err() != nil,
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// forwardThrottleReportInterval bounds how often forward_throttled is sent
+const forwardThrottleReportInterval = time.Second
+
+// forwardLimiter is a token bucket capping forwarded bytes per second.
+// Unlike pacing it never delays audio: frames over the ceiling are dropped.
+type forwardLimiter struct {
+	mu         sync.Mutex
+	rate       float64 // bytes per second
+	tokens     float64
+	last       time.Time
+	dropped    int // frames dropped since the last report
+	lastReport time.Time
+}
+
+func newForwardLimiter(bytesPerSec int) *forwardLimiter {
+	return &forwardLimiter{
+		rate:   float64(bytesPerSec),
+		tokens: float64(bytesPerSec), // allow up to one second of burst
+		last:   time.Now(),
+	}
+}
+
+// allow reports whether n bytes may be sent now. When the frame is dropped
+// and a report is due, report holds the number of frames dropped since the
+// previous report.
+func (l *forwardLimiter) allow(n int, now time.Time) (ok bool, report int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+
+	if l.tokens >= float64(n) {
+		l.tokens -= float64(n)
+		return true, 0
+	}
+
+	l.dropped++
+	if now.Sub(l.lastReport) >= forwardThrottleReportInterval {
+		report = l.dropped
+		l.dropped = 0
+		l.lastReport = now
+	}
+	return false, report
+}
@@ -0,0 +1,502 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/opus"
+	"github.com/livekit/protocol/logger"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
+)
+
+// Recording. start_recording tees forwarded audio into files under
+// RECORD_DIR: the 16kHz PCM of each selected sender (every forwarded sender
+// when none are named), as received and before AGC or mixing, goes to its
+// own file named <userId>_<sender>_<UTC start time>.wav or .ogg, readable
+// only by the bridge's user. A sender's
+// file is closed once it is rotateSec old and the next one is opened on its
+// next audio, so a sender who goes quiet doesn't hold a file open. Files are
+// written off the audio path: if the disk falls behind, packets are dropped
+// and counted rather than stalling forwarding.
+
+const (
+	recordFormatWAV = "wav"
+	recordFormatOgg = "ogg"
+)
+
+const recordSampleRate = 16000
+
+// Ogg/Opus files are encoded in 20ms packets
+const (
+	recordOpusFrameSamples = recordSampleRate / 50
+	recordOpusGranuleStep  = 48000 / 50 // Ogg Opus granule positions run at 48kHz
+)
+
+// Bounds for rotateSec / RECORD_ROTATE_S
+const (
+	minRecordRotateSec = 10
+	maxRecordRotateSec = 24 * 60 * 60
+)
+
+// recordQueueSize is how many packets may wait for the disk
+const recordQueueSize = 512
+
+type recordChunk struct {
+	sender  string
+	samples []int16
+}
+
+// recording is a running start_recording
+type recording struct {
+	client    *BridgeClient
+	requestID string
+	format    string
+	rotate    time.Duration
+	senders   map[string]bool // nil = every forwarded sender
+
+	chunks  chan recordChunk
+	dropped atomic.Int64
+	done    chan struct{}
+	exited  chan struct{}
+
+	files map[string]*recordFile // open file per sender; run goroutine only
+	count int                    // files closed so far
+}
+
+// recordFile is one sender's current file
+type recordFile struct {
+	sender  string
+	path    string
+	started time.Time
+	samples int64
+	w       recordWriter
+}
+
+type recordWriter interface {
+	write(samples []int16) error
+	close() error
+}
+
+// startRecording starts writing the forwarded audio of participants (all
+// when empty) in format ("wav" when empty), rotating files every rotateSec
+// (0 = RECORD_ROTATE_S)
+func (c *BridgeClient) startRecording(requestID string, participants []string, format string, rotateSec int) error {
+	switch format {
+	case "":
+		format = recordFormatWAV
+	case recordFormatWAV, recordFormatOgg:
+	default:
+		return fmt.Errorf("format must be %q or %q, got %q", recordFormatWAV, recordFormatOgg, format)
+	}
+	rotate := c.config.RecordRotate
+	if rotateSec != 0 {
+		if rotateSec < minRecordRotateSec || rotateSec > maxRecordRotateSec {
+			return fmt.Errorf("rotateSec must be between %d and %d", minRecordRotateSec, maxRecordRotateSec)
+		}
+		rotate = time.Duration(rotateSec) * time.Second
+	}
+	if err := os.MkdirAll(c.config.RecordDir, 0o700); err != nil {
+		return fmt.Errorf("cannot create RECORD_DIR: %v", err)
+	}
+
+	r := &recording{
+		client:    c,
+		requestID: requestID,
+		format:    format,
+		rotate:    rotate,
+		chunks:    make(chan recordChunk, recordQueueSize),
+		done:      make(chan struct{}),
+		exited:    make(chan struct{}),
+		files:     make(map[string]*recordFile),
+	}
+	if len(participants) > 0 {
+		r.senders = make(map[string]bool, len(participants))
+		for _, p := range participants {
+			r.senders[p] = true
+		}
+	}
+
+	c.mu.Lock()
+	if c.recording != nil {
+		c.mu.Unlock()
+		return errors.New("already recording; send stop_recording first")
+	}
+	c.recording = r
+	c.mu.Unlock()
+
+	log.Printf("Recording for user %s: format=%s rotate=%v participants=%v", c.userID, format, rotate, participants)
+	c.sendJSON(map[string]interface{}{
+		"type":         "recording_started",
+		"requestId":    requestID,
+		"format":       format,
+		"rotateSec":    int(rotate / time.Second),
+		"participants": participants,
+	})
+	c.spawn(r.run)
+	return nil
+}
+
+// stopRecording closes the recording's files, waiting until they are
+// written. It returns false if nothing was recording.
+func (c *BridgeClient) stopRecording() bool {
+	c.mu.Lock()
+	r := c.recording
+	c.recording = nil
+	c.mu.Unlock()
+	if r == nil {
+		return false
+	}
+	close(r.done)
+	<-r.exited
+	return true
+}
+
+// add queues a forwarded packet for its sender's file, if that sender is
+// recorded
+func (r *recording) add(sender string, pcm []byte) {
+	if r.senders != nil && !r.senders[sender] {
+		return
+	}
+	select {
+	case r.chunks <- recordChunk{sender: sender, samples: pcmToInt16(pcm)}:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// run writes queued packets until stopRecording or a write fails, then
+// closes the files and sends recording_stopped
+func (r *recording) run() {
+	defer close(r.exited)
+	err := r.loop()
+	for sender, f := range r.files {
+		if cerr := r.closeFile(f); err == nil {
+			err = cerr
+		}
+		delete(r.files, sender)
+	}
+
+	evt := map[string]interface{}{
+		"type":          "recording_stopped",
+		"requestId":     r.requestID,
+		"files":         r.count,
+		"droppedChunks": r.dropped.Load(),
+	}
+	if err != nil {
+		// The recording ended on its own; let start_recording run again
+		c := r.client
+		c.mu.Lock()
+		if c.recording == r {
+			c.recording = nil
+		}
+		c.mu.Unlock()
+		log.Printf("Recording for user %s failed: %v", c.userID, err)
+		evt["error"] = err.Error()
+	}
+	r.client.sendJSON(evt)
+}
+
+func (r *recording) loop() error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case chunk := <-r.chunks:
+			if err := r.write(chunk); err != nil {
+				return err
+			}
+		case now := <-ticker.C:
+			for sender, f := range r.files {
+				if now.Sub(f.started) < r.rotate {
+					continue
+				}
+				delete(r.files, sender)
+				if err := r.closeFile(f); err != nil {
+					return err
+				}
+			}
+		case <-r.done:
+			// Write what was queued before the stop
+			for {
+				select {
+				case chunk := <-r.chunks:
+					if err := r.write(chunk); err != nil {
+						return err
+					}
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// write appends chunk to its sender's file, rotating or opening it first
+// as needed
+func (r *recording) write(chunk recordChunk) error {
+	f := r.files[chunk.sender]
+	if f != nil && time.Since(f.started) >= r.rotate {
+		delete(r.files, chunk.sender)
+		if err := r.closeFile(f); err != nil {
+			return err
+		}
+		f = nil
+	}
+	if f == nil {
+		var err error
+		if f, err = r.openFile(chunk.sender); err != nil {
+			return err
+		}
+		r.files[chunk.sender] = f
+	}
+	f.samples += int64(len(chunk.samples))
+	return f.w.write(chunk.samples)
+}
+
+// maxRecordNameTries bounds the "-2", "-3", ... suffixes tried when a
+// file name is already taken
+const maxRecordNameTries = 100
+
+func (r *recording) openFile(sender string) (*recordFile, error) {
+	started := time.Now()
+	base := fmt.Sprintf("%s_%s_%s", fileSafe(r.client.userID), fileSafe(sender),
+		started.UTC().Format("20060102T150405.000Z"))
+	f, path, err := createRecordFile(r.client.config.RecordDir, base, r.format)
+	if err != nil {
+		return nil, err
+	}
+
+	var w recordWriter
+	if r.format == recordFormatOgg {
+		// oggwriter opens the file itself; it keeps the mode set here
+		f.Close()
+		w, err = newOggRecordWriter(path)
+	} else {
+		w, err = newWAVRecordWriter(f)
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("cannot create %s: %w", path, err)
+	}
+	return &recordFile{sender: sender, path: path, started: started, w: w}, nil
+}
+
+// createRecordFile creates base.ext in dir, owner-only, never reusing an
+// existing file: a taken name gets a numeric suffix instead
+func createRecordFile(dir, base, ext string) (*os.File, string, error) {
+	for n := 1; n <= maxRecordNameTries; n++ {
+		name := base + "." + ext
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.%s", base, n, ext)
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			return f, path, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, "", fmt.Errorf("cannot create %s: %w", path, err)
+		}
+	}
+	return nil, "", fmt.Errorf("cannot create %s.%s in %s: name taken", base, ext, dir)
+}
+
+// closeFile finishes f and sends its recording_file event
+func (r *recording) closeFile(f *recordFile) error {
+	err := f.w.close()
+	r.count++
+	evt := map[string]interface{}{
+		"type":        "recording_file",
+		"requestId":   r.requestID,
+		"participant": f.sender,
+		"path":        f.path,
+		"format":      r.format,
+		"startedAt":   f.started.UTC().Format(time.RFC3339Nano),
+		"durationMs":  f.samples * 1000 / recordSampleRate,
+	}
+	if info, statErr := os.Stat(f.path); statErr == nil {
+		evt["bytes"] = info.Size()
+	}
+	if err != nil {
+		err = fmt.Errorf("cannot finish %s: %w", f.path, err)
+		evt["error"] = err.Error()
+	}
+	r.client.sendJSON(evt)
+	if err == nil {
+		r.upload(f)
+	}
+	return err
+}
+
+// upload hands f to the uploader, if any, and reports the outcome. The
+// client may be gone by then, in which case the event is dropped.
+func (r *recording) upload(f *recordFile) {
+	r.client.uploader.Upload(f.path, func(objectURL string, err error) {
+		evt := map[string]interface{}{
+			"type":        "recording_uploaded",
+			"requestId":   r.requestID,
+			"participant": f.sender,
+			"path":        f.path,
+		}
+		if err != nil {
+			evt["type"] = "recording_upload_failed"
+			evt["error"] = err.Error()
+		} else {
+			evt["url"] = objectURL
+		}
+		r.client.sendJSON(evt)
+	})
+}
+
+// fileSafe keeps letters, digits, '-' and '_' of an identity for use in a
+// file name, replacing everything else. A replaced identity gets a short
+// hash of the original appended, so "a.b" and "a_b" get different names.
+func fileSafe(s string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+	if safe == s {
+		return s
+	}
+	sum := sha256.Sum256([]byte(s))
+	return safe + "-" + hex.EncodeToString(sum[:4])
+}
+
+// wavRecordWriter writes 16kHz mono PCM16 WAV. The header's sizes are
+// filled in on close.
+type wavRecordWriter struct {
+	f         *os.File
+	bw        *bufio.Writer
+	dataBytes int64
+	buf       []byte
+}
+
+// newWAVRecordWriter writes to f, which it owns from then on
+func newWAVRecordWriter(f *os.File) (*wavRecordWriter, error) {
+	w := &wavRecordWriter{f: f, bw: bufio.NewWriter(f)}
+	if _, err := w.bw.Write(wavHeader(0)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *wavRecordWriter) write(samples []int16) error {
+	w.buf = w.buf[:0]
+	for _, s := range samples {
+		w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(s))
+	}
+	w.dataBytes += int64(len(w.buf))
+	_, err := w.bw.Write(w.buf)
+	return err
+}
+
+func (w *wavRecordWriter) close() error {
+	err := w.bw.Flush()
+	if err == nil {
+		_, err = w.f.WriteAt(wavHeader(w.dataBytes), 0)
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// wavHeader is the 44-byte header of a 16kHz mono PCM16 WAV with dataBytes
+// of samples
+func wavHeader(dataBytes int64) []byte {
+	h := make([]byte, 0, 44)
+	h = append(h, "RIFF"...)
+	h = binary.LittleEndian.AppendUint32(h, uint32(36+dataBytes))
+	h = append(h, "WAVEfmt "...)
+	h = binary.LittleEndian.AppendUint32(h, 16)
+	h = binary.LittleEndian.AppendUint16(h, 1) // PCM
+	h = binary.LittleEndian.AppendUint16(h, 1) // mono
+	h = binary.LittleEndian.AppendUint32(h, recordSampleRate)
+	h = binary.LittleEndian.AppendUint32(h, recordSampleRate*2)
+	h = binary.LittleEndian.AppendUint16(h, 2)
+	h = binary.LittleEndian.AppendUint16(h, 16)
+	h = append(h, "data"...)
+	h = binary.LittleEndian.AppendUint32(h, uint32(dataBytes))
+	return h
+}
+
+// oggRecordWriter encodes 16kHz mono PCM16 to Ogg/Opus
+type oggRecordWriter struct {
+	enc     media.PCM16Writer
+	pending []int16 // less than one Opus frame, carried to the next write
+}
+
+func newOggRecordWriter(path string) (*oggRecordWriter, error) {
+	ogg, err := oggwriter.New(path, recordSampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := opus.Encode(&oggPacketWriter{ogg: ogg}, 1, logger.GetLogger())
+	if err != nil {
+		ogg.Close()
+		return nil, err
+	}
+	return &oggRecordWriter{enc: enc}, nil
+}
+
+func (w *oggRecordWriter) write(samples []int16) error {
+	w.pending = append(w.pending, samples...)
+	n := 0
+	for ; len(w.pending)-n >= recordOpusFrameSamples; n += recordOpusFrameSamples {
+		if err := w.enc.WriteSample(media.PCM16Sample(w.pending[n : n+recordOpusFrameSamples])); err != nil {
+			return err
+		}
+	}
+	w.pending = w.pending[:copy(w.pending, w.pending[n:])]
+	return nil
+}
+
+// close pads the last partial frame with silence, then finishes the stream
+func (w *oggRecordWriter) close() error {
+	var err error
+	if len(w.pending) > 0 {
+		frame := make([]int16, recordOpusFrameSamples)
+		copy(frame, w.pending)
+		err = w.enc.WriteSample(media.PCM16Sample(frame))
+	}
+	if cerr := w.enc.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// oggPacketWriter puts encoded Opus packets into an Ogg file, one per page
+type oggPacketWriter struct {
+	ogg     *oggwriter.OggWriter
+	granule uint32
+}
+
+func (w *oggPacketWriter) String() string { return "OggWriter" }
+
+func (w *oggPacketWriter) SampleRate() int { return recordSampleRate }
+
+func (w *oggPacketWriter) WriteSample(packet opus.Sample) error {
+	err := w.ogg.WriteRTP(&rtp.Packet{Header: rtp.Header{Timestamp: w.granule}, Payload: packet})
+	w.granule += recordOpusGranuleStep
+	return err
+}
+
+func (w *oggPacketWriter) Close() error { return w.ogg.Close() }
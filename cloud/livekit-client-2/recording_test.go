@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSafeKeepsIdentitiesApart(t *testing.T) {
+	if got := fileSafe("user-1_a"); got != "user-1_a" {
+		t.Errorf("fileSafe of a safe identity = %q, want it unchanged", got)
+	}
+	a, b := fileSafe("a.b"), fileSafe("a_b")
+	if a == b {
+		t.Errorf(`fileSafe("a.b") and fileSafe("a_b") are both %q`, a)
+	}
+}
+
+func TestCreateRecordFileNeverReuses(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 3; i++ {
+		f, path, err := createRecordFile(dir, "u_s_20250101T000000.000Z", "wav")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		paths = append(paths, filepath.Base(path))
+	}
+	want := []string{"u_s_20250101T000000.000Z.wav", "u_s_20250101T000000.000Z-2.wav", "u_s_20250101T000000.000Z-3.wav"}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("file %d = %s, want %s", i, paths[i], want[i])
+		}
+	}
+	info, err := os.Stat(filepath.Join(dir, want[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("mode = %v, want 0600", mode)
+	}
+}
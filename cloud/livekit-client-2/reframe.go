@@ -0,0 +1,94 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// reframer re-chunks inbound data-packet audio into fixed-size frames.
+// Senders pack anything from 10ms to 60ms per packet; downstream pacing
+// expects a steady frame size.
+type reframer struct {
+	mu         sync.Mutex
+	frameBytes int // 0 = pass payloads through unchanged
+	buf        []byte
+
+	// Payload size tracking for the variance warning
+	checkSizes bool
+	avgSize    float64
+	lastWarn   time.Time
+}
+
+// frameSizeWarnRatio is how far a payload may stray from the running
+// average size before it is reported
+const frameSizeWarnRatio = 3.0
+
+// frameSizeWarnInterval rate-limits the variance warning
+const frameSizeWarnInterval = 10 * time.Second
+
+// newReframer returns a reframer for 16kHz mono PCM16 at frameMs per frame
+func newReframer(frameMs int, checkSizes bool) *reframer {
+	return &reframer{frameBytes: 16000 * frameMs / 1000 * 2, checkSizes: checkSizes}
+}
+
+// push adds a payload and returns the complete frames now available
+func (r *reframer) push(userID string, pcm []byte) [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.checkSizes {
+		r.checkSize(userID, len(pcm))
+	}
+	if r.frameBytes == 0 {
+		return [][]byte{pcm}
+	}
+
+	r.buf = append(r.buf, pcm...)
+	var frames [][]byte
+	for len(r.buf) >= r.frameBytes {
+		frame := make([]byte, r.frameBytes)
+		copy(frame, r.buf)
+		frames = append(frames, frame)
+		r.buf = r.buf[r.frameBytes:]
+	}
+	// Compact so the backing array doesn't grow without bound
+	r.buf = append(r.buf[:0:0], r.buf...)
+	return frames
+}
+
+// checkSize warns when a payload is far from the running average size,
+// which usually means a sender bug. Must be called with mu held.
+func (r *reframer) checkSize(userID string, n int) {
+	if r.avgSize == 0 {
+		r.avgSize = float64(n)
+		return
+	}
+	ratio := float64(n) / r.avgSize
+	if (ratio > frameSizeWarnRatio || ratio < 1/frameSizeWarnRatio) && time.Since(r.lastWarn) > frameSizeWarnInterval {
+		r.lastWarn = time.Now()
+		log.Printf("[bridge] inbound payload size %d bytes is far from the usual %.0f for user %s; check the sender's framing", n, r.avgSize, userID)
+	}
+	r.avgSize += (float64(n) - r.avgSize) * 0.05
+}
+
+// flush returns the partial frame held back, zero-padded to a whole frame,
+// or nil if there is none
+func (r *reframer) flush() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.buf) == 0 {
+		return nil
+	}
+	frame := make([]byte, r.frameBytes)
+	copy(frame, r.buf)
+	r.buf = nil
+	return frame
+}
+
+// reset drops any partial frame, e.g. after a reconnect
+func (r *reframer) reset() {
+	r.mu.Lock()
+	r.buf = nil
+	r.mu.Unlock()
+}
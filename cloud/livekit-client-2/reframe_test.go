@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReframerFlushPadsPartialFrame(t *testing.T) {
+	r := newReframer(10, false)
+	frame := 320
+
+	if got := r.push("u", bytes.Repeat([]byte{1}, frame+100)); len(got) != 1 || len(got[0]) != frame {
+		t.Fatalf("push returned %d frames, want one of %d bytes", len(got), frame)
+	}
+	tail := r.flush()
+	if len(tail) != frame {
+		t.Fatalf("flush returned %d bytes, want a whole %d byte frame", len(tail), frame)
+	}
+	if !bytes.Equal(tail[:100], bytes.Repeat([]byte{1}, 100)) || !bytes.Equal(tail[100:], make([]byte, frame-100)) {
+		t.Errorf("flush = %v, want the 100 held-back bytes then zeros", tail)
+	}
+	if tail := r.flush(); tail != nil {
+		t.Errorf("second flush = %d bytes, want nil", len(tail))
+	}
+}
@@ -3,58 +3,199 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/binary"
 	"errors"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/builtin"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
 	mp3 "github.com/hajimehoshi/go-mp3"
 )
 
+// playbackLead is how far playback may run ahead of real time. It bounds
+// how much already-queued audio plays after stop_playback or pause_playback.
+const playbackLead = 200 * time.Millisecond
+
 // Publisher manages URL playback into a LiveKit PCM track
 type Publisher struct {
 	client *BridgeClient
+
+	mu     sync.Mutex
 	cancel context.CancelFunc
-	active string // requestId
+	active string        // requestId
+	pacer  *audio.Pacer  // paces the active request; pause_playback holds it
+	done   chan struct{} // closed once the active request has returned
 }
 
 func NewPublisher(c *BridgeClient) *Publisher { return &Publisher{client: c} }
 
 func (p *Publisher) Stop(reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.cancel != nil {
 		p.cancel()
 	}
 }
 
+// Play runs cmd in the background so commands (stop, pause) are still
+// handled while it plays. A request already playing is cancelled, and the
+// new one starts once it has stopped writing.
+func (p *Publisher) Play(cmd PlayURLCmd) {
+	p.start(cmd.RequestID, func(ctx context.Context, pacer *audio.Pacer) {
+		p.HandlePlayURL(ctx, pacer, cmd)
+	})
+}
+
+// start makes play the active request and runs it in the background once
+// the previous one, which it cancels, has returned
+func (p *Publisher) start(requestID string, play func(ctx context.Context, pacer *audio.Pacer)) {
+	ctx, cancel := context.WithCancel(p.client.context)
+	pacer := audio.NewPacer(publishSampleRate, playbackLead)
+	done := make(chan struct{})
+
+	p.mu.Lock()
+	prevCancel, prevDone := p.cancel, p.done
+	p.cancel, p.active, p.pacer, p.done = cancel, requestID, pacer, done
+	p.mu.Unlock()
+	if prevCancel != nil {
+		prevCancel()
+	}
+
+	p.client.spawn(func() {
+		defer close(done)
+		defer cancel()
+		if prevDone != nil {
+			<-prevDone
+		}
+		play(ctx, pacer)
+
+		p.mu.Lock()
+		if p.done == done {
+			p.cancel, p.active, p.pacer, p.done = nil, "", nil, nil
+		}
+		p.mu.Unlock()
+	})
+}
+
+// Pause holds the active request where it is, keeping its fetch and
+// decoder open, and returns its requestId. ok is false if nothing is
+// playing or it is already paused.
+func (p *Publisher) Pause() (requestID string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pacer == nil {
+		return "", false
+	}
+	return p.active, p.pacer.Pause()
+}
+
+// Resume continues a request held by Pause
+func (p *Publisher) Resume() (requestID string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pacer == nil {
+		return "", false
+	}
+	return p.active, p.pacer.Resume()
+}
+
 type PlayURLCmd struct {
 	RequestID  string  `json:"requestId"`
 	Url        string  `json:"url"`
 	Volume     float64 `json:"volume,omitempty"`
 	SampleRate int     `json:"sampleRate,omitempty"`
+
+	// Per-channel downmix weights for multi-channel WAV (default: average)
+	ChannelWeights []float64 `json:"channelWeights,omitempty"`
+
+	done *playCompletion
 }
 
-func (p *Publisher) HandlePlayURL(cmd PlayURLCmd) {
-	ctx, cancel := context.WithCancel(context.Background())
-	p.cancel = cancel
-	p.active = cmd.RequestID
+// playCompletion makes sure a play request ends with exactly one
+// play_complete, whichever path it exits through
+type playCompletion struct {
+	once      sync.Once
+	client    *BridgeClient
+	requestID string
+	index     int // play_queue position, or -1
+	success   bool
+	progress  *playProgress // stopped before play_complete goes out
+}
+
+func (d *playCompletion) send(success bool, durationMs int, code playerr.Code, detail string) {
+	d.once.Do(func() {
+		d.progress.stop()
+		d.success = success
+		d.client.sendPlayComplete(d.requestID, d.index, success, durationMs, code, detail)
+	})
+}
+
+// complete sends the request's play_complete; later calls are no-ops
+func (cmd PlayURLCmd) complete(success bool, durationMs int, code playerr.Code, detail string) {
+	cmd.done.send(success, durationMs, code, detail)
+}
+
+// HandlePlayURL plays cmd to completion, writing through pacer; Play runs
+// it in the background. cmd.done may be set by the caller to read the
+// outcome afterwards.
+func (p *Publisher) HandlePlayURL(ctx context.Context, pacer *audio.Pacer, cmd PlayURLCmd) {
+	// Backstop for any exit that didn't report an outcome. A panic (say, a
+	// decoder choking on bad input) fails this request rather than the
+	// whole bridge.
+	if cmd.done == nil {
+		cmd.done = &playCompletion{client: p.client, requestID: cmd.RequestID, index: -1}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("play_url panic (reqId=%s): %v\n%s", cmd.RequestID, r, debug.Stack())
+			cmd.complete(false, 0, playerr.Internal, "panic")
+			return
+		}
+		cmd.done.send(false, 0, playerr.Internal, "ended_without_completion")
+	}()
 
 	if err := p.client.ensurePublishTrack(); err != nil {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "ensure_track_failed")
+		cmd.complete(false, 0, playerr.TrackUnavailable, "ensure_track_failed")
+		return
+	}
+	if interval := p.client.config.PlayProgressInterval; interval > 0 {
+		cmd.done.progress = p.startProgress(pacer, cmd, interval)
+	}
+
+	// Embedded diagnostic assets play without touching the network
+	if strings.HasPrefix(cmd.Url, builtin.Scheme) {
+		f, err := builtin.Open(cmd.Url)
+		if err != nil {
+			cmd.complete(false, 0, playerr.InvalidURL, "unknown_builtin")
+			return
+		}
+		defer f.Close()
+		log.Printf("play_url start: reqId=%s url=%s (builtin)", cmd.RequestID, cmd.Url)
+		if !p.notifyStarted(cmd) {
+			return
+		}
+		p.streamWAV(ctx, pacer, f, cmd)
 		return
 	}
 
 	// Fetch URL
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cmd.Url, nil)
 	if err != nil {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "bad_url")
+		cmd.complete(false, 0, playerr.InvalidURL, "bad_url")
 		return
 	}
-	resp, err := http.DefaultClient.Do(req)
+	req.Header.Set("Icy-MetaData", "1")
+	resp, err := playClient.Do(req)
 	if err != nil {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "fetch_failed")
+		cmd.complete(false, 0, playerr.FetchFailed, "fetch_failed")
 		return
 	}
 	defer resp.Body.Close()
@@ -66,124 +207,139 @@ func (p *Publisher) HandlePlayURL(cmd PlayURLCmd) {
 
 	// Non-200 responses are treated as failures
 	if status < 200 || status >= 300 {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "http_status_"+http.StatusText(status))
+		cmd.complete(false, 0, playerr.HTTPStatus, "http_status_"+http.StatusText(status))
 		return
 	}
-	// Notify start; if this fails to send, abort early to avoid wasted work
-	if !p.client.trySendJSON(map[string]interface{}{
-		"type":      "play_started",
-		"requestId": cmd.RequestID,
-		"url":       cmd.Url,
-	}) {
-		log.Printf("play_url early abort: cannot notify start (reqId=%s)", cmd.RequestID)
+	if !p.notifyStarted(cmd) {
+		return
+	}
+
+	// Internet radio interleaves now-playing metadata with the audio
+	var src io.Reader = resp.Body
+	if metaint := resp.Header.Get("icy-metaint"); metaint != "" {
+		n, err := strconv.Atoi(metaint)
+		if err != nil || n <= 0 || n > maxICYMetaint {
+			cmd.complete(false, 0, playerr.MalformedAudio, "icy_metaint_invalid")
+			return
+		}
+		station := resp.Header.Get("icy-name")
+		log.Printf("play_url icy stream: reqId=%s station=%q metaint=%d", cmd.RequestID, station, n)
+		src = newICYReader(resp.Body, n, func(meta icyMetadata) {
+			p.notifyNowPlaying(cmd, station, meta)
+		})
+	}
+
+	// A 200 with no body would otherwise surface as a decoder error
+	body := bufio.NewReader(src)
+	if _, err := body.Peek(1); err == io.EOF {
+		cmd.complete(false, 0, playerr.EmptyAudio, "empty_audio")
 		return
 	}
 
-	// Support MP3 (audio/mpeg) and WAV (audio/wav, audio/x-wav, audio/wave)
-	if strings.Contains(ctype, "audio/mpeg") || strings.HasSuffix(strings.ToLower(cmd.Url), ".mp3") {
+	// Support MP3 (audio/mpeg, audio/mp3) and WAV (audio/wav, audio/x-wav, audio/wave)
+	if strings.Contains(ctype, "audio/mpeg") || strings.Contains(ctype, "audio/mp3") || strings.HasSuffix(strings.ToLower(cmd.Url), ".mp3") {
 		log.Printf("play_url decoder: mp3")
-		p.streamMP3(ctx, resp.Body, cmd)
+		p.streamMP3(ctx, pacer, body, cmd)
 		return
 	}
 	if strings.Contains(ctype, "audio/wav") || strings.Contains(ctype, "audio/x-wav") || strings.Contains(ctype, "audio/wave") || strings.HasSuffix(strings.ToLower(cmd.Url), ".wav") {
 		log.Printf("play_url decoder: wav")
-		p.streamWAV(ctx, resp.Body, cmd)
+		p.streamWAV(ctx, pacer, body, cmd)
 		return
 	}
 	log.Printf("play_url unsupported content-type: %s (url=%s)", ctype, cmd.Url)
-	p.client.sendPlayComplete(cmd.RequestID, false, 0, "unsupported_content_type")
+	cmd.complete(false, 0, playerr.UnsupportedFormat, "unsupported_content_type")
 }
 
-func bytesToI16(pcm []byte) []int16 {
-	if len(pcm)%2 == 1 {
-		pcm = pcm[:len(pcm)-1]
+// notifyStarted sends play_started and reports whether playback should go
+// ahead. If the notification can't be sent, PLAY_START_FAILURE decides:
+// "abort" (default) ends the request with a notify_failed completion so it
+// never dangles, "continue" plays anyway.
+func (p *Publisher) notifyStarted(cmd PlayURLCmd) bool {
+	evt := map[string]interface{}{
+		"type":      "play_started",
+		"requestId": cmd.RequestID,
+		"url":       cmd.Url,
+	}
+	if cmd.done.index >= 0 {
+		evt["index"] = cmd.done.index
 	}
-	out := make([]int16, len(pcm)/2)
-	for i := 0; i < len(out); i++ {
-		out[i] = int16(binary.LittleEndian.Uint16(pcm[i*2:]))
+	if p.client.trySendJSON(evt) {
+		return true
 	}
-	return out
+	if p.client.config.ContinueOnStartFailure {
+		log.Printf("play_url: cannot notify start, playing anyway (reqId=%s)", cmd.RequestID)
+		return true
+	}
+	log.Printf("play_url early abort: cannot notify start (reqId=%s)", cmd.RequestID)
+	cmd.complete(false, 0, playerr.NotifyFailed, "play_started_send_failed")
+	return false
 }
 
-func applyGain(samples []int16, gain float64) {
-	if gain == 1.0 {
-		return
+// notifyNowPlaying sends now_playing when an internet radio stream's title
+// changes
+func (p *Publisher) notifyNowPlaying(cmd PlayURLCmd, station string, meta icyMetadata) {
+	evt := map[string]interface{}{
+		"type":      "now_playing",
+		"requestId": cmd.RequestID,
+		"title":     meta.Title,
 	}
-	for i := range samples {
-		v := float64(samples[i]) * gain
-		if v > 32767 {
-			v = 32767
-		} else if v < -32768 {
-			v = -32768
-		}
-		samples[i] = int16(v)
+	if meta.URL != "" {
+		evt["streamUrl"] = meta.URL
+	}
+	if station != "" {
+		evt["station"] = station
+	}
+	if cmd.done.index >= 0 {
+		evt["index"] = cmd.done.index
 	}
+	p.client.sendJSON(evt)
 }
 
-// --- MP3 decode and resample to 16kHz mono ---
+// playClock times one item on a pacer that may be shared by a whole
+// play_queue, so only pauses since the item started count against it
+type playClock struct {
+	pacer  *audio.Pacer
+	start  time.Time
+	paused time.Duration // pacer.PausedFor() when the item started
+}
 
-type resampleState struct {
-	buf  []int16
-	pos  float64 // fractional read head within buf
-	step float64 // srcSR / dstSR
+func newPlayClock(pacer *audio.Pacer) playClock {
+	return playClock{pacer: pacer, start: time.Now(), paused: pacer.PausedFor()}
 }
 
-// push returns as many dst samples as possible after appending in to the buffer
-func (s *resampleState) push(in []int16) []int16 {
-	// Append new samples
-	s.buf = append(s.buf, in...)
-	if len(s.buf) < 2 { // need at least 2 for linear interpolation
-		return nil
-	}
-	out := make([]int16, 0, len(s.buf))
-	for {
-		i := int(s.pos)
-		if i+1 >= len(s.buf) {
-			break
-		}
-		frac := s.pos - float64(i)
-		s0 := float64(s.buf[i])
-		s1 := float64(s.buf[i+1])
-		v := s0 + (s1-s0)*frac
-		if v > 32767 {
-			v = 32767
-		} else if v < -32768 {
-			v = -32768
-		}
-		out = append(out, int16(v))
-		s.pos += s.step
-	}
-	// Drop fully consumed input but keep one sample of lookahead
-	drop := int(s.pos)
-	if drop > 0 {
-		if drop >= len(s.buf) {
-			s.buf = s.buf[:0]
-			s.pos = 0
-		} else {
-			s.buf = s.buf[drop:]
-			s.pos -= float64(drop)
-		}
-	}
-	return out
+// elapsedMs is how long the item has played, not counting its pauses
+func (c playClock) elapsedMs() int {
+	return int((time.Since(c.start) - (c.pacer.PausedFor() - c.paused)).Milliseconds())
 }
 
-func (p *Publisher) streamMP3(ctx context.Context, r io.Reader, cmd PlayURLCmd) {
-	dec, err := mp3.NewDecoder(r)
+// --- MP3 decode and resample to 16kHz mono ---
+
+func (p *Publisher) streamMP3(ctx context.Context, pacer *audio.Pacer, r io.Reader, cmd PlayURLCmd) {
+	dec, err := newMP3Decoder(ctx, r, p.client.config.MP3InitTimeout)
 	if err != nil {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "mp3_decode_error")
+		if errors.Is(err, errMP3InitTimeout) {
+			cmd.complete(false, 0, playerr.DecodeTimeout, "mp3_init_timeout")
+			return
+		}
+		cmd.complete(false, 0, playerr.DecodeFailed, "mp3_decode_error")
 		return
 	}
 	srcSR := dec.SampleRate()
 	if srcSR <= 0 {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "mp3_sr_invalid")
+		cmd.complete(false, 0, playerr.MalformedAudio, "mp3_sr_invalid")
 		return
 	}
 	const dstSR = 16000
-	st := &resampleState{step: float64(srcSR) / float64(dstSR)}
+	st, err := newResampler(srcSR, dstSR)
+	if err != nil {
+		cmd.complete(false, 0, playerr.MalformedAudio, "mp3_sr_invalid")
+		return
+	}
 	bytesPerRead := 4096
 	buf := make([]byte, bytesPerRead)
 	var totalOut int64
-	start := time.Now()
+	clock := newPlayClock(pacer)
 
 	// We assume mp3 decoder yields 16-bit LE PCM mono or stereo interleaved.
 	// go-mp3 outputs stereo as interleaved 2-channel? Its decoder is typically stereo. We'll downmix.
@@ -193,7 +349,7 @@ func (p *Publisher) streamMP3(ctx context.Context, r io.Reader, cmd PlayURLCmd)
 		n, err := dec.Read(buf)
 		if n > 0 {
 			// Convert bytes to int16 samples
-			samples := bytesToI16(buf[:n])
+			samples := audio.BytesToInt16(buf[:n])
 			// Downmix stereo if odd count suggests stereo? We can't know channels from decoder; assume stereo if len%2==0 and srcSR typical 44100.
 			// Simple heuristic: if we have even number of samples and length is large, attempt downmix by averaging pairs.
 			if len(samples) >= 2 {
@@ -207,160 +363,166 @@ func (p *Publisher) streamMP3(ctx context.Context, r io.Reader, cmd PlayURLCmd)
 			}
 
 			// Resample to 16k
-			out := st.push(samples)
-			if len(out) > 0 {
-				if cmd.Volume > 0 && cmd.Volume != 1.0 {
-					applyGain(out, cmd.Volume)
-				}
-				// write in 10ms frames (160 samples)
-				const frameSamp = dstSR / 100
-				for i := 0; i < len(out); i += frameSamp {
-					end := i + frameSamp
-					if end > len(out) {
-						end = len(out)
-					}
-					if err := p.client.writeSamples(out[i:end]); err != nil {
-						log.Printf("writeSamples error: %v", err)
-						// continue
-					}
-				}
-				totalOut += int64(len(out))
+			out := st.Process(samples)
+			if !p.writeOut(ctx, pacer, clock, cmd, out) {
+				return
 			}
+			totalOut += int64(len(out))
 		}
 		if err != nil {
-			if !errors.Is(err, io.EOF) {
-				log.Printf("mp3 read error: %v", err)
+			if errors.Is(err, io.EOF) {
+				// The resampler holds back the last few ms for look-ahead
+				out := st.Flush()
+				if !p.writeOut(ctx, pacer, clock, cmd, out) {
+					return
+				}
+				totalOut += int64(len(out))
+				break
 			}
-			break
+			// Streams without a Content-Length (radio, chunked) only end
+			// this way when the connection drops
+			log.Printf("mp3 read error: %v", err)
+			select {
+			case <-ctx.Done():
+				cmd.complete(false, clock.elapsedMs(), playerr.Cancelled, "cancelled")
+			default:
+				cmd.complete(false, clock.elapsedMs(), playerr.ReadFailed, "mp3_read_error")
+			}
+			return
 		}
 		select {
 		case <-ctx.Done():
 			// cancelled
-			p.client.sendPlayComplete(cmd.RequestID, false, int(time.Since(start).Milliseconds()), "cancelled")
+			cmd.complete(false, clock.elapsedMs(), playerr.Cancelled, "cancelled")
 			return
 		default:
 		}
 	}
 
-	durMs := int(time.Since(start).Milliseconds())
-	p.client.sendPlayComplete(cmd.RequestID, totalOut > 0, durMs, "")
+	durMs := clock.elapsedMs()
+	if totalOut == 0 {
+		cmd.complete(false, durMs, playerr.EmptyAudio, "empty_audio")
+		return
+	}
+	cmd.complete(true, durMs, "", "")
+}
+
+// writeOut applies the play volume to out, holds it to real time (and while
+// paused) and writes it to the track in 10ms frames. On failure it completes
+// cmd and returns false.
+func (p *Publisher) writeOut(ctx context.Context, pacer *audio.Pacer, clock playClock, cmd PlayURLCmd, out []int16) bool {
+	if len(out) == 0 {
+		return true
+	}
+	if cmd.Volume > 0 && cmd.Volume != 1.0 {
+		audio.ApplyGain(out, cmd.Volume)
+	}
+	if err := pacer.Wait(ctx, len(out)); err != nil {
+		cmd.complete(false, clock.elapsedMs(), playerr.Cancelled, "cancelled")
+		return false
+	}
+	const frameSamp = publishSampleRate / 100
+	for i := 0; i < len(out); i += frameSamp {
+		end := min(i+frameSamp, len(out))
+		if err := p.client.writeSamples(out[i:end]); err != nil && !errors.Is(err, errTrackClosed) {
+			log.Printf("writeSamples error: %v", err)
+			cmd.complete(false, clock.elapsedMs(), playerr.WriteFailed, "write_error")
+			return false
+		}
+	}
+	return true
+}
+
+// errMP3InitTimeout is returned when no valid MP3 frame arrives in time
+var errMP3InitTimeout = errors.New("mp3_init_timeout")
+
+// newMP3Decoder wraps mp3.NewDecoder, which blocks reading until it finds the
+// first valid frame, so a slow or garbage stream fails after timeout instead
+// of hanging. On timeout the decoder goroutine stays blocked on r until the
+// caller closes the underlying body.
+func newMP3Decoder(ctx context.Context, r io.Reader, timeout time.Duration) (*mp3.Decoder, error) {
+	type result struct {
+		dec *mp3.Decoder
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		dec, err := mp3.NewDecoder(r)
+		done <- result{dec, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.dec, res.err
+	case <-timer.C:
+		return nil, errMP3InitTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // --- WAV (PCM16) streaming ---
 
-func (p *Publisher) streamWAV(ctx context.Context, r io.Reader, cmd PlayURLCmd) {
+func (p *Publisher) streamWAV(ctx context.Context, pacer *audio.Pacer, r io.Reader, cmd PlayURLCmd) {
 	br := bufio.NewReader(r)
 
-	// Parse RIFF header
-	header := make([]byte, 12)
-	if _, err := io.ReadFull(br, header); err != nil {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_header_read")
+	wav, err := audio.ReadWAVHeader(br)
+	if err != nil {
+		code := "wav_header_read"
+		var wavErr *audio.WAVError
+		if errors.As(err, &wavErr) {
+			code = wavErr.Code
+		}
+		cmd.complete(false, 0, playerr.MalformedAudio, code)
 		return
 	}
-	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_not_riff_wave")
+	if wav.FmtShortBy > 0 {
+		log.Printf("wav fmt chunk is %d bytes short of its declared size; continuing (reqId=%s)", wav.FmtShortBy, cmd.RequestID)
+	}
+	if wav.AudioFormat != 1 { // PCM only
+		cmd.complete(false, 0, playerr.UnsupportedFormat, "wav_fmt_not_pcm")
 		return
 	}
-
-	var (
-		numChans      uint16
-		sampleRate    uint32
-		bitsPerSample uint16
-		dataBytes     uint32
-	)
-
-	// Read chunks until we find fmt and data
-	haveFmt := false
-	haveData := false
-
-	for {
-		// Each chunk: 4-byte id + 4-byte size
-		hdr := make([]byte, 8)
-		if _, err := io.ReadFull(br, hdr); err != nil {
-			p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_chunk_header")
-			return
-		}
-		cid := string(hdr[0:4])
-		size := binary.LittleEndian.Uint32(hdr[4:8])
-		if cid == "fmt " {
-			// We expect at least 16 bytes for PCM
-			buf := make([]byte, size)
-			if _, err := io.ReadFull(br, buf); err != nil {
-				p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_fmt_read")
-				return
-			}
-			// Chunks are padded to even sizes; consume pad byte if present
-			if size%2 == 1 {
-				if _, err := br.ReadByte(); err != nil {
-					p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_fmt_pad")
-					return
-				}
-			}
-			// AudioFormat (2), NumChannels (2), SampleRate (4), ByteRate (4), BlockAlign (2), BitsPerSample (2)
-			if size < 16 {
-				p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_fmt_short")
-				return
-			}
-			audioFormat := binary.LittleEndian.Uint16(buf[0:2])
-			numChans = binary.LittleEndian.Uint16(buf[2:4])
-			sampleRate = binary.LittleEndian.Uint32(buf[4:8])
-			// byteRate := binary.LittleEndian.Uint32(buf[8:12])
-			// blockAlign := binary.LittleEndian.Uint16(buf[12:14])
-			bitsPerSample = binary.LittleEndian.Uint16(buf[14:16])
-			if audioFormat != 1 { // PCM only
-				p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_fmt_not_pcm")
-				return
-			}
-			if bitsPerSample != 16 {
-				p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_bits_not_16")
-				return
-			}
-			if numChans != 1 && numChans != 2 {
-				p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_channels_unsupported")
-				return
-			}
-			haveFmt = true
-		} else if cid == "data" {
-			dataBytes = size
-			haveData = true
-			break // data follows immediately
-		} else {
-			// Skip unknown chunk
-			if _, err := io.CopyN(io.Discard, br, int64(size)); err != nil {
-				p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_skip_chunk")
-				return
-			}
-			if size%2 == 1 { // consume pad byte
-				if _, err := br.ReadByte(); err != nil {
-					p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_skip_pad")
-					return
-				}
-			}
-		}
+	if wav.BitsPerSample != 16 {
+		cmd.complete(false, 0, playerr.UnsupportedFormat, "wav_bits_not_16")
+		return
 	}
-
-	if !haveFmt || !haveData {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_missing_fmt_or_data")
+	if wav.Channels == 0 {
+		cmd.complete(false, 0, playerr.UnsupportedFormat, "wav_channels_unsupported")
+		return
+	}
+	if n := len(cmd.ChannelWeights); n > 0 && n != wav.Channels {
+		cmd.complete(false, 0, playerr.InvalidRequest, "wav_channel_weights_mismatch")
 		return
 	}
 
-	// Stream dataBytes from br
+	// Stream the data chunk from br
 	dstSR := 16000
-	st := &resampleState{step: float64(sampleRate) / float64(dstSR)}
-	bytesPerFrame := int(bitsPerSample/8) * int(numChans)
+	st, err := newResampler(wav.SampleRate, dstSR)
+	if err != nil {
+		cmd.complete(false, 0, playerr.MalformedAudio, "wav_sample_rate")
+		return
+	}
+	bytesPerFrame := wav.BlockAlign()
 	if bytesPerFrame <= 0 {
-		p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_frame_size")
+		cmd.complete(false, 0, playerr.MalformedAudio, "wav_frame_size")
 		return
 	}
 
-	// Read in chunks
-	readLeft := int64(dataBytes)
+	// Read in chunks. Streaming sources that don't know the length up front
+	// write a placeholder size; those are read until EOF.
+	readLeft := wav.DataLength()
+	if readLeft != math.MaxInt64 {
+		cmd.done.progress.setDuration(time.Duration(readLeft/int64(bytesPerFrame)) * time.Second / time.Duration(wav.SampleRate))
+	}
 	buf := make([]byte, 4096-(4096%bytesPerFrame))
 	if len(buf) == 0 {
 		buf = make([]byte, bytesPerFrame)
 	}
 	var totalOut int64
-	start := time.Now()
+	clock := newPlayClock(pacer)
 
 	for readLeft > 0 {
 		toRead := int64(len(buf))
@@ -372,7 +534,7 @@ func (p *Publisher) streamWAV(ctx context.Context, r io.Reader, cmd PlayURLCmd)
 			if err == io.ErrUnexpectedEOF || err == io.EOF {
 				// partial at end; proceed with n
 			} else {
-				p.client.sendPlayComplete(cmd.RequestID, false, 0, "wav_data_read")
+				cmd.complete(false, 0, playerr.ReadFailed, "wav_data_read")
 				return
 			}
 		}
@@ -383,68 +545,36 @@ func (p *Publisher) streamWAV(ctx context.Context, r io.Reader, cmd PlayURLCmd)
 		data := buf[:n]
 
 		// Convert to mono int16 samples
-		if numChans == 1 {
-			samples := bytesToI16(data)
-			// resample if needed
-			out := samples
-			if int(sampleRate) != dstSR {
-				out = st.push(samples)
-			}
-			if len(out) > 0 {
-				if cmd.Volume > 0 && cmd.Volume != 1.0 {
-					applyGain(out, cmd.Volume)
-				}
-				// write 10ms frames (160 samples)
-				frameSamp := dstSR / 100
-				for i := 0; i < len(out); i += frameSamp {
-					end := i + frameSamp
-					if end > len(out) {
-						end = len(out)
-					}
-					if err := p.client.writeSamples(out[i:end]); err != nil {
-						log.Printf("writeSamples error: %v", err)
-					}
-				}
-				totalOut += int64(len(out))
-			}
-		} else {
-			// Downmix stereo to mono first
-			s := bytesToI16(data)
-			mono := make([]int16, len(s)/2)
-			for i := 0; i+1 < len(s); i += 2 {
-				v := int32(s[i]) + int32(s[i+1])
-				mono[i/2] = int16(v / 2)
-			}
-			out := mono
-			if int(sampleRate) != dstSR {
-				out = st.push(mono)
-			}
-			if len(out) > 0 {
-				if cmd.Volume > 0 && cmd.Volume != 1.0 {
-					applyGain(out, cmd.Volume)
-				}
-				frameSamp := dstSR / 100
-				for i := 0; i < len(out); i += frameSamp {
-					end := i + frameSamp
-					if end > len(out) {
-						end = len(out)
-					}
-					if err := p.client.writeSamples(out[i:end]); err != nil {
-						log.Printf("writeSamples error: %v", err)
-					}
-				}
-				totalOut += int64(len(out))
-			}
+		mono := audio.Downmix(audio.BytesToInt16(data), wav.Channels, cmd.ChannelWeights)
+		out := mono
+		if wav.SampleRate != dstSR {
+			out = st.Process(mono)
 		}
+		if !p.writeOut(ctx, pacer, clock, cmd, out) {
+			return
+		}
+		totalOut += int64(len(out))
 
 		select {
 		case <-ctx.Done():
-			p.client.sendPlayComplete(cmd.RequestID, false, int(time.Since(start).Milliseconds()), "cancelled")
+			cmd.complete(false, clock.elapsedMs(), playerr.Cancelled, "cancelled")
 			return
 		default:
 		}
 	}
+	if wav.SampleRate != dstSR {
+		// The resampler holds back the last few ms for look-ahead
+		out := st.Flush()
+		if !p.writeOut(ctx, pacer, clock, cmd, out) {
+			return
+		}
+		totalOut += int64(len(out))
+	}
 
-	durMs := int(time.Since(start).Milliseconds())
-	p.client.sendPlayComplete(cmd.RequestID, totalOut > 0, durMs, "")
+	durMs := clock.elapsedMs()
+	if totalOut == 0 {
+		cmd.complete(false, durMs, playerr.EmptyAudio, "empty_audio")
+		return
+	}
+	cmd.complete(true, durMs, "", "")
 }
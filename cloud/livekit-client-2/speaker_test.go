@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testWAV is a 16kHz mono WAV of n silent samples
+func testWAV(n int) []byte {
+	return append(wavHeader(int64(n*2)), make([]byte, n*2)...)
+}
+
+// readPlayComplete reads events until requestID's play_complete
+func readPlayComplete(t *testing.T, conn *websocket.Conn, requestID string) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var evt map[string]interface{}
+		if err := conn.ReadJSON(&evt); err != nil {
+			t.Fatalf("%s: no play_complete: %v", requestID, err)
+		}
+		if evt["type"] == "play_complete" && evt["requestId"] == requestID {
+			return evt
+		}
+	}
+}
+
+// TestPlayURLCompletesExactlyOnce runs play_url down each of its exit paths
+// and checks every request ends with one play_complete carrying the
+// expected code, and no second one
+func TestPlayURLCompletesExactlyOnce(t *testing.T) {
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/short.wav":
+			w.Header().Set("Content-Type", "audio/wav")
+			w.Write(testWAV(1600))
+		case "/long.wav":
+			w.Header().Set("Content-Type", "audio/wav")
+			w.Write(testWAV(16000 * 30))
+		case "/bad.wav":
+			w.Header().Set("Content-Type", "audio/wav")
+			w.Write([]byte("RIFX not a wav file at all"))
+		case "/8bit.wav":
+			h := wavHeader(100)
+			binary.LittleEndian.PutUint16(h[34:], 8)
+			w.Header().Set("Content-Type", "audio/wav")
+			w.Write(append(h, make([]byte, 100)...))
+		case "/empty.wav":
+			w.Header().Set("Content-Type", "audio/wav")
+		case "/nodata.wav":
+			w.Header().Set("Content-Type", "audio/wav")
+			w.Write(testWAV(0))
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("hello"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer files.Close()
+	defer playClient.CloseIdleConnections()
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.PlayProgressInterval = 0
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	waitForClients(t, service, 1)
+	client, _ := service.clients.Get("user-1")
+
+	play := func(requestID, url string) map[string]interface{} {
+		t.Helper()
+		if err := conn.WriteJSON(map[string]string{"action": "play_url", "requestId": requestID, "url": url}); err != nil {
+			t.Fatal(err)
+		}
+		return readPlayComplete(t, conn, requestID)
+	}
+	check := func(evt map[string]interface{}, success bool, code string) {
+		t.Helper()
+		if evt["success"] != success || (code != "" && evt["code"] != code) {
+			t.Errorf("%s: play_complete = %v, want success=%v code=%q", evt["requestId"], evt, success, code)
+		}
+	}
+
+	// No room to play into
+	check(play("no-room", files.URL+"/short.wav"), false, "track_unavailable")
+
+	track, leave := fakeJoin(client)
+	defer leave()
+
+	tests := []struct {
+		url     string
+		success bool
+		code    string
+	}{
+		{files.URL + "/short.wav", true, ""},
+		{"builtin://chime", true, ""},
+		{"builtin://nope", false, "invalid_url"},
+		{"http://%zz", false, "invalid_url"},
+		{files.URL + "/missing.wav", false, "http_error"},
+		{files.URL + "/empty.wav", false, "empty_audio"},
+		{files.URL + "/nodata.wav", false, "empty_audio"},
+		{files.URL + "/text", false, "unsupported_format"},
+		{files.URL + "/bad.wav", false, "malformed_audio"},
+		{files.URL + "/8bit.wav", false, "unsupported_format"},
+	}
+	for i, tt := range tests {
+		check(play(fmt.Sprintf("p-%d", i), tt.url), tt.success, tt.code)
+	}
+
+	// Writes to the track failing
+	track.write = func() error { return errors.New("track broken") }
+	check(play("write-fails", files.URL+"/short.wav"), false, "write_failed")
+
+	// A panic while playing fails the request, not the bridge
+	track.write = func() error { panic("decoder bug") }
+	check(play("panics", files.URL+"/short.wav"), false, "internal")
+	track.write = nil
+
+	// Stopped part way through
+	if err := conn.WriteJSON(map[string]string{"action": "play_url", "requestId": "stopped", "url": files.URL + "/long.wav"}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := conn.WriteJSON(map[string]string{"action": "stop_playback"}); err != nil {
+		t.Fatal(err)
+	}
+	check(readPlayComplete(t, conn, "stopped"), false, "cancelled")
+
+	// Nothing completes twice
+	conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	for {
+		var evt map[string]interface{}
+		if err := conn.ReadJSON(&evt); err != nil {
+			break
+		}
+		if evt["type"] == "play_complete" {
+			t.Errorf("second play_complete: %v", evt)
+		}
+	}
+}
+
+// TestPlayURLFlushesResampler plays a 44.1kHz WAV and checks the resampled
+// output is as long as the input, tail included
+func TestPlayURLFlushesResampler(t *testing.T) {
+	const srcSR, n = 44100, 4410 // 100ms
+	clip := append(wavHeader(n*2), make([]byte, n*2)...)
+	binary.LittleEndian.PutUint32(clip[24:], srcSR)
+	binary.LittleEndian.PutUint32(clip[28:], srcSR*2)
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Write(clip)
+	}))
+	defer files.Close()
+	defer playClient.CloseIdleConnections()
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.PlayProgressInterval = 0
+	service := NewBridgeService(config)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", service.HandleWebSocket)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	conn := dialClient(t, srv, "user-1")
+	defer conn.Close()
+	waitForClients(t, service, 1)
+	client, _ := service.clients.Get("user-1")
+	track, leave := fakeJoin(client)
+	defer leave()
+
+	if err := conn.WriteJSON(map[string]string{"action": "play_url", "requestId": "r", "url": files.URL + "/clip.wav"}); err != nil {
+		t.Fatal(err)
+	}
+	if evt := readPlayComplete(t, conn, "r"); evt["success"] != true {
+		t.Fatalf("play_complete = %v", evt)
+	}
+
+	var got int
+	for _, f := range track.written() {
+		got += len(f)
+	}
+	if want := n * publishSampleRate / srcSR; got != want {
+		t.Errorf("%d samples published, want %d", got, want)
+	}
+}
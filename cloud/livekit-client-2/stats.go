@@ -0,0 +1,23 @@
+package main
+
+import "expvar"
+
+// Process-wide counters published via expvar (served on /debug/vars when
+// DEBUG_VARS_ENABLED is set). Per-client numbers stay in ClientStats.
+var (
+	statFramesPublished = expvar.NewInt("frames_published")
+	statFramesForwarded = expvar.NewInt("frames_forwarded")
+	statFramesDropped   = expvar.NewInt("frames_dropped")
+	statDataPackets     = expvar.NewInt("data_packets_received")
+)
+
+// publishClientCount exposes the live client count as active_clients, and
+// the registry's size/peak/created metrics as clients
+func publishClientCount(s *BridgeService) {
+	expvar.Publish("active_clients", expvar.Func(func() any {
+		return s.clients.Len()
+	}))
+	expvar.Publish("clients", expvar.Func(func() any {
+		return s.clients.Stats()
+	}))
+}
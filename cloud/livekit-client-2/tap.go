@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// AudioTap receives a copy of the 16kHz mono PCM16 frames flowing through a
+// client, e.g. for in-process transcription. Calls happen on the audio path,
+// so implementations must return quickly; the slices are owned by the tap.
+type AudioTap interface {
+	// OnPublish is called for each 10ms frame written to the LiveKit track
+	OnPublish(userID string, frame []int16)
+	// OnSubscribe is called for each data packet accepted for forwarding
+	OnSubscribe(userID, sender string, frame []int16)
+}
+
+// TapFrame is one tapped chunk delivered by ChannelTap
+type TapFrame struct {
+	UserID    string
+	Sender    string // empty for published audio
+	Direction string // "publish" or "subscribe"
+	Samples   []int16
+}
+
+// ChannelTap is an AudioTap that delivers frames on a buffered channel.
+// Frames are dropped rather than blocking when the consumer falls behind.
+type ChannelTap struct {
+	C chan TapFrame
+}
+
+func NewChannelTap(size int) *ChannelTap {
+	return &ChannelTap{C: make(chan TapFrame, size)}
+}
+
+func (t *ChannelTap) OnPublish(userID string, frame []int16) {
+	t.offer(TapFrame{UserID: userID, Direction: "publish", Samples: frame})
+}
+
+func (t *ChannelTap) OnSubscribe(userID, sender string, frame []int16) {
+	t.offer(TapFrame{UserID: userID, Sender: sender, Direction: "subscribe", Samples: frame})
+}
+
+func (t *ChannelTap) offer(f TapFrame) {
+	select {
+	case t.C <- f:
+	default:
+	}
+}
+
+// tapQueueFrames is how many frames (10ms each) TapForwarder buffers while
+// its endpoint is slow or being redialed
+const tapQueueFrames = 500
+
+// Redial backoff for TapForwarder
+const (
+	tapRedialMin = time.Second
+	tapRedialMax = 30 * time.Second
+)
+
+// tapHeader precedes the samples in each TapForwarder message
+type tapHeader struct {
+	UserID    string `json:"userId"`
+	Sender    string `json:"sender,omitempty"`
+	Direction string `json:"direction"`
+}
+
+// TapForwarder is an AudioTap that streams every frame to a WebSocket
+// endpoint (AUDIO_TAP_URL), e.g. an ASR service. Each frame is one binary
+// message: a 2-byte big-endian header length, a JSON tapHeader, then the
+// samples as PCM16 LE. Frames are dropped while the endpoint is down.
+type TapForwarder struct {
+	*ChannelTap
+	url    string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewTapForwarder(url string) *TapForwarder {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &TapForwarder{
+		ChannelTap: NewChannelTap(tapQueueFrames),
+		url:        url,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	f.wg.Add(1)
+	go f.run()
+	return f
+}
+
+// Close stops forwarding and closes the connection
+func (f *TapForwarder) Close() {
+	f.cancel()
+	f.wg.Wait()
+}
+
+func (f *TapForwarder) run() {
+	defer f.wg.Done()
+	delay := tapRedialMin
+	for {
+		conn, _, err := websocket.DefaultDialer.DialContext(f.ctx, f.url, nil)
+		if err != nil {
+			if f.ctx.Err() != nil {
+				return
+			}
+			log.Printf("Audio tap: dial %s: %v; retrying in %v", f.url, err, delay)
+			if !f.dropFor(delay) {
+				return
+			}
+			delay = min(delay*2, tapRedialMax)
+			continue
+		}
+		delay = tapRedialMin
+		err = f.forward(conn)
+		conn.Close()
+		if f.ctx.Err() != nil {
+			return
+		}
+		log.Printf("Audio tap: %v; redialing", err)
+	}
+}
+
+// forward writes queued frames to conn until a write fails or Close
+func (f *TapForwarder) forward(conn *websocket.Conn) error {
+	for {
+		select {
+		case <-f.ctx.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			return nil
+		case frame := <-f.C:
+			conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if err := conn.WriteMessage(websocket.BinaryMessage, encodeTapFrame(frame)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// dropFor discards frames for d, so the queue holds fresh audio once the
+// endpoint is back. Returns false if the forwarder was closed meanwhile.
+func (f *TapForwarder) dropFor(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-f.ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case <-f.C:
+		}
+	}
+}
+
+func encodeTapFrame(f TapFrame) []byte {
+	header, _ := json.Marshal(tapHeader{UserID: f.UserID, Sender: f.Sender, Direction: f.Direction})
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(header)))
+	out = append(out, header...)
+	for _, s := range f.Samples {
+		out = binary.LittleEndian.AppendUint16(out, uint16(s))
+	}
+	return out
+}
+
+// pcmToInt16 decodes PCM16 LE bytes into a fresh sample slice
+func pcmToInt16(data []byte) []int16 {
+	out := make([]int16, len(data)/2)
+	for i := range out {
+		out[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	lkauth "github.com/livekit/protocol/auth"
+)
+
+// Server-side token minting for join_room_managed. With LIVEKIT_API_KEY and
+// LIVEKIT_API_SECRET configured the bridge signs the room token itself, so
+// callers never hold LiveKit credentials or ship tokens over the socket.
+// Because the bridge's key can sign for any room and identity, managed joins
+// need /ws auth: the identity is the token's subject, the room must match
+// MANAGED_ROOMS, and the connection always goes to LIVEKIT_URL.
+
+var (
+	errNoAPICredentials = errors.New("LIVEKIT_API_KEY and LIVEKIT_API_SECRET are not configured")
+	errManagedNoAuth    = errors.New("requires /ws auth (WS_AUTH_SECRET or WS_AUTH_JWKS_URL)")
+	errManagedURL       = errors.New("url is not accepted; managed joins always use LIVEKIT_URL")
+	errManagedRoom      = errors.New("room is not allowed by MANAGED_ROOMS")
+)
+
+// managedRoomUser in a MANAGED_ROOMS pattern stands for the caller's userId
+const managedRoomUser = "{userId}"
+
+// globEscaper quotes the path.Match metacharacters
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+
+// parseManagedRooms splits the comma-separated MANAGED_ROOMS patterns
+func parseManagedRooms(list string) []string {
+	var patterns []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// managedRoomAllowed reports whether userID may mint a token for roomName:
+// it must match one of patterns (path.Match globs) once {userId} in them is
+// replaced by userID. The userID is substituted literally, so a subject like
+// "*" can't match other users' rooms.
+func managedRoomAllowed(patterns []string, roomName, userID string) bool {
+	literal := globEscaper.Replace(userID)
+	for _, p := range patterns {
+		if ok, err := path.Match(strings.ReplaceAll(p, managedRoomUser, literal), roomName); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkManagedJoin rejects a join_room_managed this client may not make
+func (c *BridgeClient) checkManagedJoin(roomName, url string) error {
+	if !c.authenticated {
+		return errManagedNoAuth
+	}
+	if url != "" {
+		return errManagedURL
+	}
+	if c.config.LiveKitURL == "" {
+		return errors.New("LIVEKIT_URL is not configured")
+	}
+	if roomName == "" {
+		return errors.New("roomName required")
+	}
+	if !managedRoomAllowed(c.config.ManagedRooms, roomName, c.userID) {
+		return fmt.Errorf("%w: %s", errManagedRoom, roomName)
+	}
+	return nil
+}
+
+// mintRoomToken signs a room_join token for identity in roomName
+func (c *BridgeClient) mintRoomToken(roomName, identity string) (string, error) {
+	if c.config.LiveKitAPIKey == "" || c.config.LiveKitAPISecret == "" {
+		return "", errNoAPICredentials
+	}
+	if roomName == "" {
+		return "", errors.New("roomName required")
+	}
+	at := lkauth.NewAccessToken(c.config.LiveKitAPIKey, c.config.LiveKitAPISecret)
+	at.SetIdentity(identity)
+	at.SetName(identity)
+	at.SetValidFor(c.config.ManagedTokenTTL)
+	at.AddGrant(&lkauth.VideoGrant{RoomJoin: true, Room: roomName})
+	token, err := at.ToJWT()
+	if err != nil {
+		return "", fmt.Errorf("mint token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestManagedRoomAllowed(t *testing.T) {
+	patterns := parseManagedRooms("{userId}, lobby-*")
+	cases := []struct {
+		room, user string
+		want       bool
+	}{
+		{"alice", "alice", true},
+		{"bob", "alice", false},
+		{"lobby-1", "alice", true},
+		// Glob metacharacters in the subject are matched literally
+		{"bob", "*", false},
+		{"*", "*", true},
+		{"bob", "b?b", false},
+		{"b?b", "b?b", true},
+		{"bob", "[a-z]ob", false},
+		{"[a-z]ob", "[a-z]ob", true},
+		{`a\b`, `a\b`, true},
+	}
+	for _, c := range cases {
+		if got := managedRoomAllowed(patterns, c.room, c.user); got != c.want {
+			t.Errorf("managedRoomAllowed(%q, user %q) = %v, want %v", c.room, c.user, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/retry"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// Framed multi-track publishing. Joining with "framing": "tracks" makes
+// every inbound binary message a sequence of frames, each with a 5-byte
+// header:
+//
+//	u8  track id   0 = the main publish track, 1 = app_audio, 2 = tts, n = track_<n>
+//	u16 sequence   per track, wrapping; gaps are counted, late frames dropped
+//	u16 length     payload bytes
+//
+// (little-endian) followed by the payload in the join inputFormat and
+// inputRate. Track 0 goes through the normal publish path (processors,
+// clip reports, tap); the other tracks are published on first use and get
+// the audio as sent.
+
+// Values of the join option "framing"
+const (
+	framingRaw    = "raw"
+	framingTracks = "tracks"
+)
+
+const trackFrameHeaderSize = 5
+
+// Well-known track ids, matching the gRPC bridge's track_id scheme
+var namedTracks = map[uint8]string{
+	1: "app_audio",
+	2: "tts",
+}
+
+// trackIDToName names an extra track. Track 0 is the main track and is
+// named by the join trackName instead.
+func trackIDToName(id uint8) string {
+	if name, ok := namedTracks[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("track_%d", id)
+}
+
+var errTruncatedFrame = errors.New("truncated track frame")
+
+// extraTrack is a publish track other than the main one
+type extraTrack struct {
+	name    string
+	track   audioTrack
+	rs      *resample.Resampler // inputRate -> publish rate; nil when equal
+	pending []int16             // sub-frame remainder carried to the next frame
+}
+
+// trackSeq follows one track's sequence numbers
+type trackSeq struct {
+	last uint16
+	seen bool
+	gaps int
+}
+
+// accept reports whether seq should be played. A frame at or behind the
+// last one seen is a duplicate or arrived late.
+func (s *trackSeq) accept(seq uint16) bool {
+	if !s.seen {
+		s.seen = true
+		s.last = seq
+		return true
+	}
+	delta := int16(seq - s.last)
+	if delta <= 0 {
+		return false
+	}
+	if delta > 1 {
+		s.gaps += int(delta) - 1
+	}
+	s.last = seq
+	return true
+}
+
+// handleFramedAudio splits a framed message and routes each frame to its
+// track
+func (c *BridgeClient) handleFramedAudio(data []byte) {
+	for len(data) > 0 {
+		if len(data) < trackFrameHeaderSize {
+			log.Printf("Dropping framed audio from user %s: %v", c.userID, errTruncatedFrame)
+			return
+		}
+		id := data[0]
+		seq := binary.LittleEndian.Uint16(data[1:])
+		n := int(binary.LittleEndian.Uint16(data[3:]))
+		data = data[trackFrameHeaderSize:]
+		if n > len(data) {
+			log.Printf("Dropping framed audio from user %s: %v (track %d wants %d bytes, %d left)", c.userID, errTruncatedFrame, id, n, len(data))
+			return
+		}
+		payload := data[:n]
+		data = data[n:]
+
+		c.mu.Lock()
+		if c.trackSeqs == nil {
+			c.trackSeqs = make(map[uint8]*trackSeq)
+		}
+		st := c.trackSeqs[id]
+		if st == nil {
+			st = &trackSeq{}
+			c.trackSeqs[id] = st
+		}
+		gapsBefore := st.gaps
+		ok := st.accept(seq)
+		gaps := st.gaps
+		c.mu.Unlock()
+		if !ok {
+			continue
+		}
+		if gaps != gapsBefore && (gapsBefore == 0 || gaps/100 != gapsBefore/100) {
+			log.Printf("Track %d for user %s is missing frames (%d lost so far)", id, c.userID, gaps)
+		}
+
+		if id == 0 {
+			c.publishMainAudio(payload)
+		} else {
+			c.publishExtraAudio(id, payload)
+		}
+	}
+}
+
+// publishExtraAudio writes one payload to a non-main track, creating and
+// publishing the track on first use
+func (c *BridgeClient) publishExtraAudio(id uint8, payload []byte) {
+	c.mu.Lock()
+	stopped := c.publishStopped
+	float32In := c.inputFloat32
+	c.mu.Unlock()
+	if stopped || !c.isJoined() {
+		return
+	}
+	t, err := c.ensureExtraTrack(id)
+	if err != nil {
+		log.Printf("Cannot send audio on %s: %v", trackIDToName(id), err)
+		return
+	}
+
+	var samples []int16
+	if float32In {
+		samples = audio.Float32ToInt16(payload)
+	} else {
+		samples = audio.BytesToInt16(payload)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.extraTracks[id] != t {
+		return // closed while converting
+	}
+	if t.rs != nil {
+		samples = t.rs.Process(samples)
+	}
+	samples = append(t.pending, samples...)
+	t.pending = nil
+	frameSamples := publishSampleRate / 100
+	whole := len(samples) - len(samples)%frameSamples
+	for offset := 0; offset < whole; offset += frameSamples {
+		if err := t.track.WriteSample(samples[offset : offset+frameSamples]); err != nil {
+			if !errors.Is(err, errTrackClosed) {
+				log.Printf("Failed to write to %s: %v", t.name, err)
+			}
+			return
+		}
+		statFramesPublished.Add(1)
+		c.metrics.framesIn.Inc()
+	}
+	if whole < len(samples) {
+		t.pending = append([]int16(nil), samples[whole:]...)
+	}
+}
+
+// ensureExtraTrack returns the track for id, publishing it if needed
+func (c *BridgeClient) ensureExtraTrack(id uint8) (*extraTrack, error) {
+	c.publishMu.Lock()
+	defer c.publishMu.Unlock()
+
+	c.mu.Lock()
+	if t := c.extraTracks[id]; t != nil {
+		c.mu.Unlock()
+		return t, nil
+	}
+	room := c.room
+	if !c.connected || room == nil {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("not connected to room")
+	}
+	codec := c.joinOpts.Codec
+	inputRate := c.inputRate
+	c.mu.Unlock()
+
+	name := trackIDToName(id)
+	track, err := newAudioTrack(codec, false)
+	if err != nil {
+		return nil, fmt.Errorf("create %s track: %w", codecName(codec), err)
+	}
+	err = retry.Transient(c.context, publishAttempts, publishRetryDelay, func() error {
+		_, err := room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{Name: name})
+		return err
+	})
+	if err != nil {
+		track.Close()
+		c.sendEvent(Event{Type: "publish_failed", Error: fmt.Sprintf("%s: %v", name, err)})
+		return nil, fmt.Errorf("publish track: %w", err)
+	}
+
+	t := &extraTrack{name: name, track: track}
+	if inputRate != publishSampleRate {
+		if t.rs, err = newResampler(inputRate, publishSampleRate); err != nil {
+			track.Close()
+			return nil, err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.room != room {
+		track.Close()
+		return nil, fmt.Errorf("not connected to room")
+	}
+	if c.extraTracks == nil {
+		c.extraTracks = make(map[uint8]*extraTrack)
+	}
+	c.extraTracks[id] = t
+	log.Printf("Audio track %s published for user %s (codec=%s)", name, c.userID, codecName(codec))
+	return t, nil
+}
+
+// closeExtraTracksLocked closes every non-main track and resets sequence
+// tracking. c.mu must be held.
+func (c *BridgeClient) closeExtraTracksLocked() {
+	for id, t := range c.extraTracks {
+		t.track.Close()
+		delete(c.extraTracks, id)
+	}
+	c.trackSeqs = nil
+}
@@ -20,6 +20,37 @@ type Command struct {
 	SampleRate     int             `json:"sampleRate,omitempty"`
 	Reason         string          `json:"reason,omitempty"`
 	TargetIdentity string          `json:"targetIdentity,omitempty"`
+	Mix            bool            `json:"mix,omitempty"`
+	MetadataFilter string          `json:"metadataFilter,omitempty"`
+	State          string          `json:"state,omitempty"`  // publish_audio: "start" or "stop"; track_levels/audio_levels: "stop"
+	Format         string          `json:"format,omitempty"` // subscribe_enable: "s16le" (default) or "f32le"; start_recording: "wav" (default) or "ogg"
+	MaxBytesPerSec int             `json:"maxBytesPerSec,omitempty"`
+	OutputChannels int             `json:"outputChannels,omitempty"` // subscribe_enable: 2 duplicates mono into interleaved L/R
+	ChannelWeights []float64       `json:"channelWeights,omitempty"` // play_url: multi-channel WAV downmix weights
+	AGC            bool            `json:"agc,omitempty"`            // subscribe_enable: normalize each sender's level
+	AGCTargetDB    *float64        `json:"agcTargetDb,omitempty"`    // subscribe_enable: AGC target RMS in dBFS (default -20; 0 is full scale)
+	SpeakingDB     float64         `json:"speakingDb,omitempty"`     // audio_levels: dBFS RMS at which a sender is speaking (default -45)
+	URLs           []string        `json:"urls,omitempty"`           // play_queue: URLs to play in order
+	Participants   []string        `json:"participants,omitempty"`   // start_recording: senders to record (default all forwarded)
+	RotateSec      int             `json:"rotateSec,omitempty"`      // start_recording: start a new file per sender this often
+}
+
+// JoinOptions are the optional structured join_room settings carried in
+// Command.Config. Zero values keep the defaults.
+type JoinOptions struct {
+	TrackName      string `json:"trackName,omitempty"`      // publish track name (default "microphone")
+	AutoSubscribe  bool   `json:"autoSubscribe,omitempty"`  // enable forwarding as soon as the room is joined
+	TargetIdentity string `json:"targetIdentity,omitempty"` // with autoSubscribe, only forward this participant
+	Mix            bool   `json:"mix,omitempty"`            // with autoSubscribe, mix all senders
+	OutputRate     int    `json:"outputRate,omitempty"`     // sample rate of forwarded audio (default 16000)
+	PacerBitrate   int    `json:"pacerBitrate,omitempty"`   // LiveKit pacer bitrate in bps (default 512000)
+	InputFormat    string `json:"inputFormat,omitempty"`    // inbound binary audio: "s16le" (default) or "f32le"
+	InputRate      int    `json:"inputRate,omitempty"`      // sample rate of inbound audio (default 16000)
+	Force          bool   `json:"force,omitempty"`          // join_room while in a room switches rooms instead of failing
+	OutputFormat   string `json:"outputFormat,omitempty"`   // with autoSubscribe, forwarded audio format
+	OutputChannels int    `json:"outputChannels,omitempty"` // with autoSubscribe, 2 = interleaved stereo
+	Codec          string `json:"codec,omitempty"`          // publish codec: "opus" (default), or "pcmu"/"pcma" for G.711 gateways
+	Framing        string `json:"framing,omitempty"`        // inbound binary messages: "raw" (default) or "tracks" for multi-track frames
 }
 
 // Event represents outgoing status messages
@@ -30,6 +61,26 @@ type Event struct {
 	ParticipantCount int    `json:"participantCount,omitempty"`
 	Error            string `json:"error,omitempty"`
 	State            string `json:"state,omitempty"`
+
+	Capabilities *Capabilities `json:"capabilities,omitempty"` // connected only
+}
+
+// Capabilities describes the audio formats this bridge accepts and produces,
+// sent with the connected event so clients can pick join settings instead of
+// guessing
+type Capabilities struct {
+	InputFormats   []string `json:"inputFormats"`  // join inputFormat values
+	OutputFormats  []string `json:"outputFormats"` // join outputFormat / subscribe_enable format values
+	MinSampleRate  int      `json:"minSampleRate"` // bounds for inputRate and outputRate
+	MaxSampleRate  int      `json:"maxSampleRate"`
+	PublishRate    int      `json:"publishRate"`    // rate audio is published at; other input rates are resampled
+	OutputChannels []int    `json:"outputChannels"` // forwarded channel layouts
+	Codecs         []string `json:"codecs"`         // join codec values
+	MaxFrameBytes  int      `json:"maxFrameBytes"`  // largest inbound WS message accepted
+	FrameMs        int      `json:"frameMs"`        // inbound audio is split into frames of this size
+	CoalesceFrames int      `json:"coalesceFrames"` // frames per forwarded WS message (WS_COALESCE_FRAMES)
+	OpusForwarding bool     `json:"opusForwarding"` // forwarded audio can be Opus instead of PCM
+	Framings       []string `json:"framings"`       // join framing values
 }
 
 type ClientStats struct {
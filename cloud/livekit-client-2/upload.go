@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/retry"
+)
+
+// Recording upload. With RECORD_UPLOAD_BUCKET set, every file start_recording
+// finishes is uploaded to that S3-compatible bucket under
+// RECORD_UPLOAD_PREFIX, and the client gets recording_uploaded with the
+// object's URL (or recording_upload_failed). Files larger than one part go
+// up as a multipart upload, so long sessions don't restart from zero when
+// one request fails. Bodies are streamed from the file, never held in
+// memory. Requests are signed with AWS Signature Version 4; no SDK is
+// needed.
+
+// Attempts per S3 request before an upload fails. Only network errors,
+// 5xx, 408 and 429 are retried; other 4xx won't succeed on a second try.
+const uploadAttempts = 3
+
+var uploadRetryDelay = time.Second // var so tests can shorten it
+
+// maxConcurrentUploads bounds the uploads in flight across all clients
+const maxConcurrentUploads = 4
+
+// Part size bounds for RECORD_UPLOAD_PART_MB: S3's smallest part for all
+// but the last one, and our own cap, which is also the largest single PUT
+const (
+	minUploadPartMB = 5
+	maxUploadPartMB = 64
+)
+
+var errUploaderClosed = errors.New("uploader is shut down")
+
+// RecordingUploader uploads finished recording files to an object store
+type RecordingUploader struct {
+	endpoint    *url.URL
+	bucket      string
+	prefix      string
+	region      string
+	accessKey   string
+	secretKey   string
+	pathStyle   bool // bucket in the path rather than the host name
+	partSize    int64
+	deleteAfter bool
+	client      *http.Client
+	sem         chan struct{}
+
+	// Uploads run under ctx, which Close cancels once its deadline passes
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex // guards closed and wg.Add
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewRecordingUploader creates an uploader from the RECORD_UPLOAD_* config,
+// or returns nil if no bucket is configured
+func NewRecordingUploader(config *Config) *RecordingUploader {
+	if config.RecordUploadBucket == "" {
+		return nil
+	}
+	// AWS gets virtual-hosted URLs; custom endpoints (MinIO, R2, ...)
+	// usually only serve path-style ones
+	endpoint := "https://s3." + config.RecordUploadRegion + ".amazonaws.com"
+	pathStyle := false
+	if config.RecordUploadEndpoint != "" {
+		endpoint = config.RecordUploadEndpoint
+		pathStyle = true
+	}
+	u, _ := url.Parse(endpoint) // checked by Config.validate
+	log.Printf("[Upload] Uploading recordings to bucket %s at %s", config.RecordUploadBucket, u.Host)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RecordingUploader{
+		endpoint:    u,
+		bucket:      config.RecordUploadBucket,
+		prefix:      config.RecordUploadPrefix,
+		region:      config.RecordUploadRegion,
+		accessKey:   config.RecordUploadAccessKey,
+		secretKey:   config.RecordUploadSecretKey,
+		pathStyle:   pathStyle,
+		partSize:    int64(config.RecordUploadPartMB) << 20,
+		deleteAfter: config.RecordUploadDelete,
+		client:      &http.Client{Timeout: 2 * time.Minute},
+		sem:         make(chan struct{}, maxConcurrentUploads),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Upload sends file in the background and calls done with the object's URL
+// or the error. Safe to call on a nil uploader, which does nothing.
+func (u *RecordingUploader) Upload(file string, done func(objectURL string, err error)) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	if u.closed {
+		u.mu.Unlock()
+		log.Printf("[Upload] %s not uploaded: %v", file, errUploaderClosed)
+		done("", errUploaderClosed)
+		return
+	}
+	u.wg.Add(1)
+	u.mu.Unlock()
+
+	go func() {
+		defer u.wg.Done()
+		select {
+		case u.sem <- struct{}{}:
+		case <-u.ctx.Done():
+			done("", u.ctx.Err())
+			return
+		}
+		defer func() { <-u.sem }()
+
+		key := path.Join(u.prefix, filepath.Base(file))
+		err := u.upload(u.ctx, file, key)
+		if err != nil {
+			log.Printf("[Upload] %s failed: %v", file, err)
+			done("", err)
+			return
+		}
+		if u.deleteAfter {
+			if err := os.Remove(file); err != nil {
+				log.Printf("[Upload] Failed to remove %s after upload: %v", file, err)
+			}
+		}
+		done(u.objectURL(key, nil).String(), nil)
+	}()
+}
+
+// Close stops accepting uploads and waits for those in flight until ctx is
+// done, then cancels the rest and waits for them to give up. Multipart
+// uploads cut short this way are aborted. Safe to call on a nil uploader.
+func (u *RecordingUploader) Close(ctx context.Context) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	u.closed = true
+	u.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		u.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		log.Printf("[Upload] Cancelling unfinished uploads at shutdown")
+		u.cancel()
+		<-drained
+	}
+	u.cancel()
+}
+
+func (u *RecordingUploader) upload(ctx context.Context, file, key string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	contentType := "audio/wav"
+	if strings.HasSuffix(file, "."+recordFormatOgg) {
+		contentType = "audio/ogg"
+	}
+
+	if info.Size() <= u.partSize {
+		body := io.NewSectionReader(f, 0, info.Size())
+		return retry.Transient(ctx, uploadAttempts, uploadRetryDelay, func() error {
+			_, _, err := u.do(ctx, "PUT", key, nil, body, contentType)
+			return err
+		})
+	}
+	return u.uploadMultipart(ctx, f, info.Size(), key, contentType)
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// uploadMultipart uploads the size bytes of f in partSize pieces, aborting
+// the upload if a part or the completion fails so the store doesn't keep
+// the pieces
+func (u *RecordingUploader) uploadMultipart(ctx context.Context, f *os.File, size int64, key, contentType string) (err error) {
+	// Not retried: a retry after a lost response would start a second
+	// upload that nothing ever completes or aborts
+	_, body, err := u.do(ctx, "POST", key, url.Values{"uploads": {""}}, nil, contentType)
+	if err != nil {
+		return err
+	}
+	var created struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(body, &created); err != nil || created.UploadID == "" {
+		return fmt.Errorf("CreateMultipartUpload: no UploadId in response: %s", truncateBody(body))
+	}
+	uploadID := created.UploadID
+	defer func() {
+		if err != nil {
+			// ctx may be what failed the upload; the abort still has to go out
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			if _, _, abortErr := u.do(abortCtx, "DELETE", key, url.Values{"uploadId": {uploadID}}, nil, ""); abortErr != nil {
+				log.Printf("[Upload] Failed to abort multipart upload of %s: %v", key, abortErr)
+			}
+		}
+	}()
+
+	var parts []completedPart
+	for number, off := 1, int64(0); off < size; number, off = number+1, off+u.partSize {
+		part := io.NewSectionReader(f, off, min(u.partSize, size-off))
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+		err = retry.Transient(ctx, uploadAttempts, uploadRetryDelay, func() error {
+			header, _, err := u.do(ctx, "PUT", key, query, part, "")
+			if err != nil {
+				return err
+			}
+			parts = append(parts, completedPart{PartNumber: number, ETag: header.Get("ETag")})
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("part %d: %w", number, err)
+		}
+	}
+
+	complete, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	return retry.Transient(ctx, uploadAttempts, uploadRetryDelay, func() error {
+		_, body, err := u.do(ctx, "POST", key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(complete), "application/xml")
+		if err != nil {
+			return err
+		}
+		// Completion can fail after the 200 has been sent
+		var result struct {
+			XMLName xml.Name
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if xml.Unmarshal(body, &result) == nil && result.XMLName.Local == "Error" {
+			return fmt.Errorf("CompleteMultipartUpload: %s: %s", result.Code, result.Message)
+		}
+		return nil
+	})
+}
+
+// payload is a request body that can be read more than once: once to hash
+// it for the signature, then to send it, and again on a retry
+type payload interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// do sends one signed request and returns the response headers and body.
+// body may be nil. Responses outside 2xx are errors; those not worth
+// retrying are marked retry.Permanent.
+func (u *RecordingUploader) do(ctx context.Context, method, key string, query url.Values, body payload, contentType string) (http.Header, []byte, error) {
+	var size int64
+	if body != nil {
+		size = body.Size()
+	}
+	hash := sha256.New()
+	if size > 0 {
+		if _, err := io.Copy(hash, io.NewSectionReader(body, 0, size)); err != nil {
+			return nil, nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.objectURL(key, query).String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if size > 0 {
+		req.Body = io.NopCloser(io.NewSectionReader(body, 0, size))
+		req.ContentLength = size
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	u.sign(req, hex.EncodeToString(hash.Sum(nil)), time.Now())
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= 300 {
+		err := fmt.Errorf("%s %s: status %d: %s", method, key, resp.StatusCode, truncateBody(respBody))
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			err = retry.Permanent(err)
+		}
+		return nil, nil, err
+	}
+	return resp.Header, respBody, nil
+}
+
+// objectURL is the URL of key in the bucket, with query in S3's canonical
+// encoding so the signature covers exactly what is sent
+func (u *RecordingUploader) objectURL(key string, query url.Values) *url.URL {
+	target := *u.endpoint
+	target.Path = "/" + key
+	if u.pathStyle {
+		target.Path = "/" + u.bucket + "/" + key
+	} else {
+		target.Host = u.bucket + "." + target.Host
+	}
+	target.RawPath = s3Escape(target.Path, false)
+	target.RawQuery = canonicalQuery(query)
+	return &target
+}
+
+// sign adds a Signature Version 4 Authorization header covering the host,
+// payload hash and date
+func (u *RecordingUploader) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + u.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+u.secretKey), date)
+	key = hmacSHA256(key, u.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		u.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes query sorted by key, as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape percent-encodes everything but unreserved characters, and '/'
+// unless encodeSlash is set
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func truncateBody(body []byte) string {
+	if len(body) > 512 {
+		body = body[:512]
+	}
+	return string(body)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/retry"
+)
+
+// webhookEventTypes are the lifecycle events relayed to WEBHOOK_URL
+var webhookEventTypes = map[string]bool{
+	"room_joined":     true,
+	"room_left":       true,
+	"track_published": true,
+	"disconnected":    true,
+}
+
+// WebhookEvent is one lifecycle event as POSTed to the webhook
+type WebhookEvent struct {
+	Type             string `json:"type"`
+	UserID           string `json:"userId"`
+	RoomName         string `json:"roomName,omitempty"`
+	ParticipantID    string `json:"participantId,omitempty"`
+	ParticipantCount int    `json:"participantCount,omitempty"`
+	Error            string `json:"error,omitempty"`
+	Timestamp        string `json:"ts"`
+}
+
+// WebhookRelay batches lifecycle events and POSTs them as a JSON array,
+// retrying batches that fail with a network error or a 5xx response with
+// exponential backoff
+type WebhookRelay struct {
+	url           string
+	client        *http.Client
+	batchSize     int
+	maxBuffer     int
+	maxRetries    int
+	flushInterval time.Duration
+	buffer        []WebhookEvent
+	bufferMu      sync.Mutex
+	ctx           context.Context // cancelled by Close; stops retries
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// NewWebhookRelay creates a relay posting to url, or returns nil if url is empty
+func NewWebhookRelay(url string) *WebhookRelay {
+	if url == "" {
+		return nil
+	}
+	r := &WebhookRelay{
+		url: url,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		batchSize:     20,
+		maxBuffer:     1000,
+		maxRetries:    4,
+		flushInterval: 2 * time.Second,
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go r.flushWorker()
+	log.Printf("[Webhook] Relaying lifecycle events to %s", url)
+	return r
+}
+
+// Relay queues event if it is a lifecycle event. Safe to call on a nil relay.
+func (r *WebhookRelay) Relay(userID string, event Event) {
+	if r == nil || !webhookEventTypes[event.Type] {
+		return
+	}
+
+	r.bufferMu.Lock()
+	r.buffer = append(r.buffer, WebhookEvent{
+		Type:             event.Type,
+		UserID:           userID,
+		RoomName:         event.RoomName,
+		ParticipantID:    event.ParticipantID,
+		ParticipantCount: event.ParticipantCount,
+		Error:            event.Error,
+		Timestamp:        time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	// Don't grow without bound while the endpoint is down
+	if len(r.buffer) > r.maxBuffer {
+		r.buffer = r.buffer[len(r.buffer)-r.maxBuffer:]
+	}
+	shouldFlush := len(r.buffer) >= r.batchSize
+	r.bufferMu.Unlock()
+
+	if shouldFlush {
+		r.Flush()
+	}
+}
+
+// Flush sends all buffered events in the background
+func (r *WebhookRelay) Flush() {
+	r.bufferMu.Lock()
+	if len(r.buffer) == 0 {
+		r.bufferMu.Unlock()
+		return
+	}
+	events := make([]WebhookEvent, len(r.buffer))
+	copy(events, r.buffer)
+	r.buffer = r.buffer[:0]
+	r.bufferMu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.sendBatch(events)
+	}()
+}
+
+// sendBatch POSTs events, retrying with backoff on network errors and 5xx
+// responses. Other responses mean the batch will never be accepted.
+func (r *WebhookRelay) sendBatch(events []WebhookEvent) {
+	jsonData, err := json.Marshal(events)
+	if err != nil {
+		log.Printf("[Webhook] Failed to marshal events: %v", err)
+		return
+	}
+
+	err = retry.Transient(r.ctx, r.maxRetries+1, webhookRetryDelay, func() error {
+		return r.post(jsonData)
+	})
+	if err != nil {
+		log.Printf("[Webhook] Dropping %d events: %v", len(events), err)
+	}
+}
+
+// webhookRetryDelay is the first backoff between attempts; a var so tests
+// can shorten it
+var webhookRetryDelay = 500 * time.Millisecond
+
+func (r *WebhookRelay) post(body []byte) error {
+	req, err := http.NewRequest("POST", r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("status %d: %s", resp.StatusCode, string(msg))
+		if resp.StatusCode < 500 {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// flushWorker periodically flushes the buffer
+func (r *WebhookRelay) flushWorker() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush()
+		case <-r.ctx.Done():
+			r.Flush() // Final flush on shutdown, sent without retries
+			return
+		}
+	}
+}
+
+// Close stops the relay, sends what is still buffered once and waits for
+// in-flight batches. Safe to call on a nil relay.
+func (r *WebhookRelay) Close() {
+	if r == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestWebhookRetries checks which responses a batch is retried on
+func TestWebhookRetries(t *testing.T) {
+	defer func(d time.Duration) { webhookRetryDelay = d }(webhookRetryDelay)
+	webhookRetryDelay = time.Millisecond
+
+	tests := []struct {
+		name      string
+		statuses  []int // responses in order; the last repeats
+		wantPosts int32
+	}{
+		{"accepted", []int{http.StatusOK}, 1},
+		{"server error then accepted", []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusNoContent}, 3},
+		{"server error every time", []int{http.StatusInternalServerError}, 5},
+		{"bad request", []int{http.StatusBadRequest}, 1},
+		{"unauthorized", []int{http.StatusUnauthorized}, 1},
+		{"not found", []int{http.StatusNotFound}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(posts.Add(1))
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer srv.Close()
+
+			r := NewWebhookRelay(srv.URL)
+			r.sendBatch([]WebhookEvent{{Type: "room_joined", UserID: "user-1"}})
+			r.Close()
+			if got := posts.Load(); got != tt.wantPosts {
+				t.Errorf("%d posts, want %d", got, tt.wantPosts)
+			}
+		})
+	}
+}
+
+// TestWebhookCloseSendsBuffered checks events still buffered at Close are
+// sent, and that a failed final batch isn't retried
+func TestWebhookCloseSendsBuffered(t *testing.T) {
+	var posts atomic.Int32
+	var got []WebhookEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	r := NewWebhookRelay(srv.URL)
+	r.Relay("user-1", Event{Type: "room_joined", RoomName: "room"})
+	r.Relay("user-1", Event{Type: "play_complete"}) // not a lifecycle event
+	r.Relay("user-1", Event{Type: "disconnected"})
+	r.Close()
+
+	if posts.Load() != 1 {
+		t.Fatalf("%d posts, want 1", posts.Load())
+	}
+	if len(got) != 2 || got[0].Type != "room_joined" || got[1].Type != "disconnected" {
+		t.Errorf("sent %+v, want room_joined and disconnected", got)
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+	"github.com/abema/go-mp4"
+	"github.com/skrashevich/go-aac/pkg/decoder"
+)
+
+// AAC playback.
+//
+// ADTS streams (audio/aac, .aac) carry a header on every frame and are
+// decoded as they arrive. MP4/M4A (audio/mp4, .m4a) keeps its sample table
+// in the moov box, which many encoders write after the audio, so the file is
+// spooled to a temporary file (up to maxMP4Bytes) and demuxed from there.
+// Only AAC-LC is decoded; HE-AAC in ADTS plays its LC core at half the
+// sample rate. A frame that fails to decode is played as silence, up to
+// maxBadAACFrames in a row.
+
+// maxMP4Bytes bounds how much of an MP4 is spooled to disk for demuxing
+const maxMP4Bytes = 64 << 20
+
+// maxBadAACFrames is how many frames in a row may fail to decode before
+// playback gives up; about a second of 48kHz audio
+const maxBadAACFrames = 50
+
+// adtsSyncWindow is how far into an ADTS stream the first frame must start
+const adtsSyncWindow = 64 << 10
+
+// aacStream decodes AAC frames to interleaved PCM16
+type aacStream struct {
+	dec      *decoder.Decoder
+	next     func() ([]byte, error) // next ADTS or raw frame; io.EOF at the end
+	duration time.Duration          // from the MP4 header; 0 for ADTS
+	close    func() error           // releases the spooled MP4; nil for ADTS
+
+	// Bad frame concealment
+	lastLen      int // samples in the last good frame; 0 before the first
+	lastChannels int
+	badRun       int // frames in a row that failed to decode
+	concealed    int // bad frames replaced so far
+}
+
+// Read decodes the next frame and returns its samples and channel count.
+// The sample rate is known once the first frame is read. A frame that
+// doesn't decode is replaced by a frame of silence, or skipped if none has
+// decoded yet. Errors are playErrors, apart from io.EOF after the last frame.
+func (s *aacStream) Read() ([]int16, int, error) {
+	for {
+		frame, err := s.next()
+		if err != nil {
+			return nil, 0, err
+		}
+		pcm, err := s.dec.DecodeFrame(frame)
+		frameLength := s.dec.Config.FrameLength
+		if err == nil && (frameLength == 0 || len(pcm)%frameLength != 0) {
+			err = fmt.Errorf("frame has %d samples", len(pcm))
+		}
+		if err != nil {
+			if s.badRun++; s.badRun > maxBadAACFrames {
+				return nil, 0, playerr.Fail(playerr.DecodeFailed, fmt.Errorf("AAC decode error, %d frames in a row: %w", s.badRun, err))
+			}
+			if s.concealed++; s.concealed == 1 {
+				log.Printf("AAC frame failed to decode, playing silence in its place: %v", err)
+			}
+			if s.lastLen == 0 {
+				continue
+			}
+			return make([]int16, s.lastLen), s.lastChannels, nil
+		}
+		s.badRun = 0
+		samples := make([]int16, len(pcm))
+		for i, v := range pcm {
+			samples[i] = int16(max(-1, min(1, v)) * 32767)
+		}
+		s.lastLen, s.lastChannels = len(pcm), len(pcm)/frameLength
+		return samples, s.lastChannels, nil
+	}
+}
+
+// Close releases the stream's resources
+func (s *aacStream) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// SampleRate is the decoded sample rate (0 before the first ADTS frame)
+func (s *aacStream) SampleRate() int {
+	return s.dec.Config.SampleRate
+}
+
+// newADTSStream decodes an ADTS stream, skipping a leading ID3 tag
+func newADTSStream(r io.Reader) (*aacStream, error) {
+	br := bufio.NewReader(r)
+	if err := skipID3(br); err != nil {
+		return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("failed to skip ID3 tag: %w", err))
+	}
+	scanned := 0
+	next := func() ([]byte, error) {
+		for {
+			hdr, err := br.Peek(7)
+			if len(hdr) < 7 {
+				if err == nil || errors.Is(err, io.EOF) {
+					return nil, io.EOF
+				}
+				return nil, playerr.Fail(playerr.ReadFailed, fmt.Errorf("failed to read AAC: %w", err))
+			}
+			// 12-bit syncword, layer 0
+			if hdr[0] == 0xFF && hdr[1]&0xF6 == 0xF0 {
+				length := int(hdr[3]&0x03)<<11 | int(hdr[4])<<3 | int(hdr[5])>>5
+				if length >= 7 {
+					frame := make([]byte, length)
+					if _, err := io.ReadFull(br, frame); err != nil {
+						if errors.Is(err, io.ErrUnexpectedEOF) {
+							return nil, io.EOF // truncated last frame
+						}
+						return nil, playerr.Fail(playerr.ReadFailed, fmt.Errorf("failed to read AAC: %w", err))
+					}
+					scanned = 0
+					return frame, nil
+				}
+			}
+			// Lost sync; look for the next frame, but not forever
+			if scanned++; scanned > adtsSyncWindow {
+				return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("no ADTS frame within %d bytes", adtsSyncWindow))
+			}
+			br.Discard(1)
+		}
+	}
+	return &aacStream{dec: decoder.New(), next: next}, nil
+}
+
+// skipID3 discards an ID3v2 tag at the start of br, if there is one
+func skipID3(br *bufio.Reader) error {
+	hdr, err := br.Peek(10)
+	if err != nil || string(hdr[:3]) != "ID3" {
+		return nil
+	}
+	// Syncsafe size: 7 bits per byte, excluding the 10-byte header
+	size := int64(hdr[6])<<21 | int64(hdr[7])<<14 | int64(hdr[8])<<7 | int64(hdr[9])
+	if hdr[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	_, err = io.CopyN(io.Discard, br, 10+size)
+	return err
+}
+
+// newMP4Stream demuxes the first AAC track of an MP4/M4A. The stream must
+// be closed to remove the spooled file.
+func newMP4Stream(r io.Reader) (_ *aacStream, err error) {
+	f, err := os.CreateTemp("", "play-*.m4a")
+	if err != nil {
+		return nil, playerr.Fail(playerr.Internal, fmt.Errorf("failed to spool MP4: %w", err))
+	}
+	closeFile := func() error {
+		f.Close()
+		return os.Remove(f.Name())
+	}
+	defer func() {
+		if err != nil {
+			closeFile()
+		}
+	}()
+	size, err := io.Copy(f, io.LimitReader(r, maxMP4Bytes+1))
+	if err != nil {
+		return nil, playerr.Fail(playerr.ReadFailed, fmt.Errorf("failed to read MP4: %w", err))
+	}
+	if size > maxMP4Bytes {
+		return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("MP4 is larger than %d MB", maxMP4Bytes>>20))
+	}
+	rs := io.NewSectionReader(f, 0, size)
+
+	info, err := mp4.Probe(rs)
+	if err != nil {
+		return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid MP4: %w", err))
+	}
+	var track *mp4.Track
+	for _, t := range info.Tracks {
+		if t.Codec == mp4.CodecMP4A {
+			track = t
+			break
+		}
+	}
+	if track == nil {
+		return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("MP4 has no AAC track"))
+	}
+	if len(track.Samples) == 0 {
+		return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("MP4 AAC track has no samples (fragmented MP4 is not supported)"))
+	}
+
+	asc, err := mp4AudioConfig(rs, track.TrackID)
+	if err != nil {
+		return nil, playerr.Fail(playerr.MalformedAudio, err)
+	}
+	dec := decoder.New()
+	if err := dec.SetASC(asc); err != nil {
+		return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("unsupported AAC config: %w", err))
+	}
+
+	// Walk the chunks in order; each holds SamplesPerChunk consecutive
+	// samples back to back
+	chunk, inChunk, sample := 0, uint32(0), 0
+	var offset uint64
+	next := func() ([]byte, error) {
+		if sample >= len(track.Samples) {
+			return nil, io.EOF
+		}
+		for chunk < len(track.Chunks) && inChunk == track.Chunks[chunk].SamplesPerChunk {
+			chunk, inChunk = chunk+1, 0
+		}
+		if chunk >= len(track.Chunks) {
+			return nil, io.EOF
+		}
+		if inChunk == 0 {
+			offset = track.Chunks[chunk].DataOffset
+		}
+		n := uint64(track.Samples[sample].Size)
+		if offset+n > uint64(size) {
+			return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("MP4 sample %d is past the end of the file", sample))
+		}
+		frame := make([]byte, n)
+		if _, err := f.ReadAt(frame, int64(offset)); err != nil {
+			return nil, playerr.Fail(playerr.ReadFailed, fmt.Errorf("failed to read MP4 sample %d: %w", sample, err))
+		}
+		offset += n
+		inChunk++
+		sample++
+		return frame, nil
+	}
+	var duration time.Duration
+	if track.Timescale > 0 {
+		duration = time.Duration(track.Duration) * time.Second / time.Duration(track.Timescale)
+	}
+	return &aacStream{dec: dec, next: next, duration: duration, close: closeFile}, nil
+}
+
+// mp4AudioConfig returns the AudioSpecificConfig from the esds box of the
+// track with trackID
+func mp4AudioConfig(rs io.ReadSeeker, trackID uint32) ([]byte, error) {
+	traks, err := mp4.ExtractBox(rs, nil, mp4.BoxPath{mp4.BoxTypeMoov(), mp4.BoxTypeTrak()})
+	if err != nil {
+		return nil, fmt.Errorf("invalid MP4: %w", err)
+	}
+	for _, trak := range traks {
+		tkhd, err := mp4.ExtractBoxWithPayload(rs, trak, mp4.BoxPath{mp4.BoxTypeTkhd()})
+		if err != nil || len(tkhd) == 0 || tkhd[0].Payload.(*mp4.Tkhd).TrackID != trackID {
+			continue
+		}
+		esds, err := mp4.ExtractBoxWithPayload(rs, trak, mp4.BoxPath{
+			mp4.BoxTypeMdia(), mp4.BoxTypeMinf(), mp4.BoxTypeStbl(),
+			mp4.BoxTypeStsd(), mp4.BoxTypeMp4a(), mp4.BoxTypeEsds(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid MP4: %w", err)
+		}
+		for _, box := range esds {
+			for _, d := range box.Payload.(*mp4.Esds).Descriptors {
+				if d.Tag == mp4.DecSpecificInfoTag && len(d.Data) >= 2 {
+					return d.Data, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("MP4 AAC track has no decoder config")
+}
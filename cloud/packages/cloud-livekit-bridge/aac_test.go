@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+	"github.com/skrashevich/go-aac/pkg/decoder"
+)
+
+// TestAACStreamGivesUpOnBadFrames feeds frames that never decode and checks
+// Read skips them until maxBadAACFrames have failed in a row
+func TestAACStreamGivesUpOnBadFrames(t *testing.T) {
+	reads := 0
+	src := &aacStream{dec: decoder.New(), next: func() ([]byte, error) {
+		reads++
+		return []byte{0xde, 0xad, 0xbe, 0xef}, nil
+	}}
+	_, _, err := src.Read()
+	if playerr.CodeOf(err) != playerr.DecodeFailed {
+		t.Fatalf("err = %v, want decode_failed", err)
+	}
+	if reads != maxBadAACFrames+1 {
+		t.Errorf("read %d frames before giving up, want %d", reads, maxBadAACFrames+1)
+	}
+}
+
+// TestAACStreamConcealsBadFrame checks a bad frame after a good one is
+// played as a frame of silence
+func TestAACStreamConcealsBadFrame(t *testing.T) {
+	frames := [][]byte{{0xde, 0xad}}
+	src := &aacStream{dec: decoder.New(), lastLen: 2048, lastChannels: 2, next: func() ([]byte, error) {
+		if len(frames) == 0 {
+			return nil, io.EOF
+		}
+		f := frames[0]
+		frames = frames[1:]
+		return f, nil
+	}}
+	pcm, channels, err := src.Read()
+	if err != nil || len(pcm) != 2048 || channels != 2 {
+		t.Fatalf("Read = %d samples, %d channels, %v; want 2048 samples of silence in 2 channels", len(pcm), channels, err)
+	}
+	for _, v := range pcm {
+		if v != 0 {
+			t.Fatalf("concealed frame isn't silent")
+		}
+	}
+	if _, _, err := src.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("err = %v, want io.EOF", err)
+	}
+}
+
+// TestMP4StreamRemovesSpoolFile checks a rejected MP4 leaves nothing in the
+// temp directory
+func TestMP4StreamRemovesSpoolFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	if _, err := newMP4Stream(bytes.NewReader([]byte("not an mp4 file"))); err == nil {
+		t.Fatal("newMP4Stream accepted garbage")
+	}
+	left, _ := filepath.Glob(filepath.Join(os.TempDir(), "*"))
+	if len(left) != 0 {
+		t.Errorf("spool files left behind: %v", left)
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Playback cache.
+//
+// Downloads PlayAudio reads to the end are kept on disk, keyed by URL and
+// ETag, and evicted least recently played first once the cache is over
+// PlaybackCacheBytes. A repeat PlayAudio plays the file without touching the
+// network while it is fresh by Cache-Control max-age or Expires, and
+// otherwise revalidates it with If-None-Match/If-Modified-Since, so an
+// unchanged file costs one 304. Responses marked no-store, HLS playlists and
+// files with nothing to revalidate them by are not cached. The index lives
+// in memory; files left from an earlier run are removed at startup.
+
+// File name suffixes in the cache directory
+const (
+	cacheFileSuffix    = ".audio"
+	cachePartialSuffix = ".part"
+)
+
+// cacheEntry is a cached download
+type cacheEntry struct {
+	url          string
+	etag         string
+	lastModified string
+	contentType  string
+	path         string
+	size         int64
+	expires      time.Time // fresh until; zero means revalidate every time
+}
+
+// fresh reports whether the entry can be played without revalidating
+func (e *cacheEntry) fresh(now time.Time) bool {
+	return now.Before(e.expires)
+}
+
+// setConditional makes req revalidate the entry
+func (e *cacheEntry) setConditional(req *http.Request) {
+	if e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
+	}
+	if e.lastModified != "" {
+		req.Header.Set("If-Modified-Since", e.lastModified)
+	}
+}
+
+// audioCache is a size-bounded LRU of downloads on disk
+type audioCache struct {
+	dir      string
+	maxBytes int64
+	maxEntry int64 // no single file may take more than this
+
+	mu      sync.Mutex
+	lru     *list.List               // *cacheEntry, most recently played first
+	entries map[string]*list.Element // by URL
+	size    int64
+}
+
+// newAudioCache returns a cache of up to maxBytes in dir, clearing files
+// left there by an earlier run
+func newAudioCache(dir string, maxBytes int64) (*audioCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	for _, suffix := range []string{cacheFileSuffix, cachePartialSuffix} {
+		stale, _ := filepath.Glob(filepath.Join(dir, "*"+suffix))
+		for _, path := range stale {
+			os.Remove(path)
+		}
+	}
+	return &audioCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		maxEntry: maxBytes / 4,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}, nil
+}
+
+// get returns a copy of url's entry and marks it recently played
+func (c *audioCache) get(url string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[url]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return *elem.Value.(*cacheEntry), true
+}
+
+// open opens a cached file, dropping the entry if the file has gone
+func (c *audioCache) open(entry cacheEntry) (*os.File, error) {
+	f, err := os.Open(entry.path)
+	if err != nil {
+		c.mu.Lock()
+		if elem, ok := c.entries[entry.url]; ok && elem.Value.(*cacheEntry).path == entry.path {
+			c.removeLocked(elem)
+		}
+		c.mu.Unlock()
+	}
+	return f, err
+}
+
+// revalidated refreshes an entry's freshness after a 304
+func (c *audioCache) revalidated(entry cacheEntry, h http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[entry.url]; ok && elem.Value.(*cacheEntry).path == entry.path {
+		elem.Value.(*cacheEntry).expires = cacheExpiry(h, time.Now())
+	}
+}
+
+// fill wraps r, resp's body, to copy what is read into the cache, or
+// returns nil if resp isn't cacheable. The copy is committed once the whole
+// body has been read; Close drops it otherwise.
+func (c *audioCache) fill(url string, resp *http.Response, r io.Reader) *cacheFill {
+	if resp.StatusCode != http.StatusOK || resp.ContentLength > c.maxEntry {
+		return nil
+	}
+	if strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store") {
+		return nil
+	}
+	entry := &cacheEntry{
+		url:          url,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		contentType:  resp.Header.Get("Content-Type"),
+		expires:      cacheExpiry(resp.Header, time.Now()),
+	}
+	if entry.etag == "" && entry.lastModified == "" && entry.expires.IsZero() {
+		return nil // nothing to tell a stale copy by
+	}
+	sum := sha256.Sum256([]byte(url + "\x00" + entry.etag))
+	entry.path = filepath.Join(c.dir, hex.EncodeToString(sum[:16])+cacheFileSuffix)
+
+	f, err := os.CreateTemp(c.dir, "*"+cachePartialSuffix)
+	if err != nil {
+		log.Printf("Playback cache: %v", err)
+		return nil
+	}
+	return &cacheFill{cache: c, entry: entry, r: r, f: f, want: resp.ContentLength}
+}
+
+// add commits a filled entry, replacing any older one for the URL, and
+// evicts until the cache fits
+func (c *audioCache) add(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[entry.url]; ok {
+		old := c.lru.Remove(elem).(*cacheEntry)
+		c.size -= old.size
+		if old.path != entry.path { // same URL and ETag: renamed over already
+			os.Remove(old.path)
+		}
+	}
+	c.entries[entry.url] = c.lru.PushFront(entry)
+	c.size += entry.size
+	for c.size > c.maxBytes && c.lru.Len() > 1 {
+		c.removeLocked(c.lru.Back())
+	}
+}
+
+// removeLocked drops an entry and its file. Players already reading it keep
+// their open handle. c.mu must be held.
+func (c *audioCache) removeLocked(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.url)
+	c.size -= entry.size
+	os.Remove(entry.path)
+}
+
+// cacheFill tees a download into a partial file
+type cacheFill struct {
+	cache *audioCache
+	entry *cacheEntry
+	r     io.Reader
+	f     *os.File // nil once committed or abandoned
+	n     int64
+	want  int64 // Content-Length, or -1
+}
+
+func (w *cacheFill) Read(p []byte) (int, error) {
+	n, err := w.r.Read(p)
+	if w.f != nil {
+		if n > 0 {
+			if _, werr := w.f.Write(p[:n]); werr != nil {
+				log.Printf("Playback cache: %v", werr)
+				w.abandon()
+				return n, err
+			}
+			w.n += int64(n)
+			if w.n > w.cache.maxEntry {
+				w.abandon()
+				return n, err
+			}
+		}
+		// Decoders may stop at the end of the audio without seeing EOF, so
+		// a body of the announced length is complete too
+		if w.n > 0 && (err == io.EOF || (w.want >= 0 && w.n == w.want)) {
+			w.commit()
+		} else if err != nil {
+			w.abandon()
+		}
+	}
+	return n, err
+}
+
+func (w *cacheFill) commit() {
+	f := w.f
+	w.f = nil
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), w.entry.path); err != nil {
+		log.Printf("Playback cache: %v", err)
+		os.Remove(f.Name())
+		return
+	}
+	w.entry.size = w.n
+	w.cache.add(w.entry)
+}
+
+func (w *cacheFill) abandon() {
+	if w.f != nil {
+		w.f.Close()
+		os.Remove(w.f.Name())
+		w.f = nil
+	}
+}
+
+// Close drops a partial copy; playback stopped before the end
+func (w *cacheFill) Close() error {
+	w.abandon()
+	return nil
+}
+
+// cacheExpiry is when a response stops being fresh: its max-age (s-maxage
+// first), else Expires. Zero means it must be revalidated on every play.
+func cacheExpiry(h http.Header, now time.Time) time.Time {
+	cc := strings.ToLower(h.Get("Cache-Control"))
+	if strings.Contains(cc, "no-cache") {
+		return time.Time{}
+	}
+	for _, directive := range []string{"s-maxage=", "max-age="} {
+		for _, part := range strings.Split(cc, ",") {
+			value, ok := strings.CutPrefix(strings.TrimSpace(part), directive)
+			if !ok {
+				continue
+			}
+			if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+				return now.Add(time.Duration(secs) * time.Second)
+			}
+			return time.Time{}
+		}
+	}
+	if expires, err := http.ParseTime(h.Get("Expires")); err == nil && expires.After(now) {
+		return expires
+	}
+	return time.Time{}
+}
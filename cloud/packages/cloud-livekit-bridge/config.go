@@ -1,7 +1,15 @@
 package main
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/envconfig"
 )
 
 // Config holds the service configuration
@@ -12,26 +20,316 @@ type Config struct {
 	LiveKitAPISecret string
 	LogLevel         string
 	PublishGain      float64
+	AudioProcessors  []string // applied in order to every frame written to a track
+
+	// Liveness watermark tone used by the "watermark" processor
+	WatermarkHz      float64
+	WatermarkLevelDB float64
+
+	// Audible feedback when PlayAudio fails: "none", "tone" or "fade"
+	PlaybackErrorFeedback string
+	ErrorToneHz           int
+	ErrorFeedbackMs       int
+
+	// Fade-out written by StopAudio before closing the track (0 = cut immediately)
+	StopFadeMs int
+
+	// Crossfade when a PlayAudio replaces the one playing on its track
+	// (0 = cut the old one off)
+	CrossfadeMs int
+
+	// How often PlayAudio sends PROGRESS events (0 = never)
+	PlaybackProgressInterval time.Duration
+
+	// How long PlayAudio waits for the first valid MP3 frame
+	MP3InitTimeout time.Duration
+
+	// Sample magnitude counted as clipped in PlayAudio completion metadata
+	ClipLevel int
+
+	// How far StreamAudio writes may run ahead of real time per track
+	// (0 = unbounded), and whether excess frames are paced or dropped
+	MaxTrackAhead time.Duration
+	DropWhenAhead bool
+
+	// HealthCheck reports NOT_SERVING when the share of LiveKit packets
+	// dropped across sessions over HealthDropWindow exceeds this (0 = off)
+	HealthMaxDropRate float64
+	HealthDropWindow  time.Duration
+
+	// Warn when a streaming session gets no LiveKit audio for this long
+	// (0 = off); AudioStallAction "reconnect" also reconnects the room
+	AudioStallTimeout time.Duration
+	AudioStallAction  string
+
+	// Re-join the room after LiveKit drops the connection, backing off
+	// exponentially from ReconnectBaseDelay up to ReconnectMaxDelay
+	// (0 attempts = off)
+	ReconnectMaxAttempts int
+	ReconnectBaseDelay   time.Duration
+	ReconnectMaxDelay    time.Duration
+
+	// Filter quality for resampling played audio to 16kHz
+	ResampleQuality resample.Quality
+
+	// Range requests PlayAudio may make to resume a download that dropped
+	// mid-file (0 = fail on the first drop)
+	PlaybackResumeAttempts int
+
+	// Attenuation of speaker/app_audio while tts plays (0 = off), and the
+	// ramps into and out of it
+	DuckDB      float64
+	DuckAttack  time.Duration
+	DuckRelease time.Duration
+
+	// Size bound and directory of the on-disk cache of played URLs
+	// (0 bytes = off)
+	PlaybackCacheBytes int64
+	PlaybackCacheDir   string
+
+	// HLS playback fails after this many segments or playlist reloads fail
+	// in a row; fewer are skipped
+	HLSMaxSegmentErrors int
 }
 
 // loadConfig loads configuration from environment variables
-func loadConfig() *Config {
+func loadConfig() (*Config, error) {
 	config := &Config{
-		Port:             getEnv("PORT", "9090"),
-		LiveKitURL:       getEnv("LIVEKIT_URL", ""),
-		LiveKitAPIKey:    getEnv("LIVEKIT_API_KEY", ""),
-		LiveKitAPISecret: getEnv("LIVEKIT_API_SECRET", ""),
-		LogLevel:         getEnv("LOG_LEVEL", "info"),
+		Port:             envconfig.Get("PORT", "9090"),
+		LiveKitURL:       envconfig.Get("LIVEKIT_URL", ""),
+		LiveKitAPIKey:    envconfig.Get("LIVEKIT_API_KEY", ""),
+		LiveKitAPISecret: envconfig.Get("LIVEKIT_API_SECRET", ""),
+		LogLevel:         envconfig.Get("LOG_LEVEL", "info"),
 		PublishGain:      1.0,
+		AudioProcessors:  parseProcessorNames(envconfig.Get("AUDIO_PROCESSORS", "gain")),
+		WatermarkHz:      7000,
+		WatermarkLevelDB: -60,
+
+		PlaybackErrorFeedback: envconfig.Get("PLAYBACK_ERROR_FEEDBACK", "none"),
+		ErrorToneHz:           440,
+		ErrorFeedbackMs:       250,
+		CrossfadeMs:           200,
+		MP3InitTimeout:        5 * time.Second,
+		ClipLevel:             32767,
+		DropWhenAhead:         envconfig.Get("TRACK_AHEAD_POLICY", "pace") == "drop",
+		HealthDropWindow:      60 * time.Second,
+		AudioStallAction:      envconfig.Get("AUDIO_STALL_ACTION", "warn"),
+		ReconnectMaxAttempts:  8,
+		ReconnectBaseDelay:    500 * time.Millisecond,
+		ReconnectMaxDelay:     30 * time.Second,
+
+		DuckDB:      12,
+		DuckAttack:  50 * time.Millisecond,
+		DuckRelease: 400 * time.Millisecond,
+
+		PlaybackProgressInterval: time.Second,
+
+		PlaybackResumeAttempts: 3,
+		HLSMaxSegmentErrors:    5,
+		PlaybackCacheBytes:     64 << 20,
+		PlaybackCacheDir:       envconfig.Get("PLAYBACK_CACHE_DIR", filepath.Join(os.TempDir(), "livekit-bridge-playback")),
+	}
+
+	// Credentials can come from mounted secret files instead of the environment
+	for _, secret := range []struct {
+		key string
+		dst *string
+	}{
+		{"LIVEKIT_API_KEY", &config.LiveKitAPIKey},
+		{"LIVEKIT_API_SECRET", &config.LiveKitAPISecret},
+	} {
+		value, err := envconfig.Secret(secret.key)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			*secret.dst = value
+		}
+	}
+
+	if qualityStr := envconfig.Lookup("RESAMPLE_QUALITY"); qualityStr != "" {
+		quality, err := resample.ParseQuality(qualityStr)
+		if err != nil {
+			return nil, fmt.Errorf("RESAMPLE_QUALITY: %w", err)
+		}
+		config.ResampleQuality = quality
+	}
+
+	if gainStr := envconfig.Lookup("PUBLISH_GAIN"); gainStr != "" {
+		gain, err := strconv.ParseFloat(gainStr, 64)
+		if err != nil || gain <= 0 {
+			return nil, fmt.Errorf("PUBLISH_GAIN must be a number > 0, got %q", gainStr)
+		}
+		config.PublishGain = gain
+	}
+
+	if hzStr := envconfig.Lookup("WATERMARK_HZ"); hzStr != "" {
+		if hz, err := strconv.ParseFloat(hzStr, 64); err == nil && hz > 0 && hz < 8000 {
+			config.WatermarkHz = hz
+		}
+	}
+
+	if levelStr := envconfig.Lookup("WATERMARK_LEVEL_DB"); levelStr != "" {
+		if db, err := strconv.ParseFloat(levelStr, 64); err == nil && db < 0 {
+			config.WatermarkLevelDB = db
+		}
+	}
+
+	if hzStr := envconfig.Lookup("PLAYBACK_ERROR_TONE_HZ"); hzStr != "" {
+		if hz, err := strconv.Atoi(hzStr); err == nil && hz > 0 && hz < 8000 {
+			config.ErrorToneHz = hz
+		}
+	}
+
+	if msStr := envconfig.Lookup("PLAYBACK_ERROR_FEEDBACK_MS"); msStr != "" {
+		if ms, err := strconv.Atoi(msStr); err == nil && ms > 0 && ms <= 2000 {
+			config.ErrorFeedbackMs = ms
+		}
 	}
 
-	return config
+	if fadeStr := envconfig.Lookup("STOP_FADE_MS"); fadeStr != "" {
+		if ms, err := strconv.Atoi(fadeStr); err == nil && ms >= 0 && ms <= 2000 {
+			config.StopFadeMs = ms
+		}
+	}
+
+	if fadeStr := envconfig.Lookup("PLAYBACK_CROSSFADE_MS"); fadeStr != "" {
+		if ms, err := strconv.Atoi(fadeStr); err == nil && ms >= 0 && ms <= 2000 {
+			config.CrossfadeMs = ms
+		}
+	}
+
+	if progressStr := envconfig.Lookup("PLAYBACK_PROGRESS_MS"); progressStr != "" {
+		if ms, err := strconv.Atoi(progressStr); err == nil && ms >= 0 {
+			config.PlaybackProgressInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if levelStr := envconfig.Lookup("CLIP_LEVEL"); levelStr != "" {
+		if level, err := strconv.Atoi(levelStr); err == nil && level > 0 && level <= 32767 {
+			config.ClipLevel = level
+		}
+	}
+
+	if aheadStr := envconfig.Lookup("TRACK_MAX_AHEAD_MS"); aheadStr != "" {
+		if ms, err := strconv.Atoi(aheadStr); err == nil && ms >= 0 {
+			config.MaxTrackAhead = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if rateStr := envconfig.Lookup("HEALTH_MAX_DROP_RATE"); rateStr != "" {
+		if rate, err := strconv.ParseFloat(rateStr, 64); err == nil && rate >= 0 && rate <= 1 {
+			config.HealthMaxDropRate = rate
+		}
+	}
+
+	if windowStr := envconfig.Lookup("HEALTH_DROP_WINDOW_S"); windowStr != "" {
+		if sec, err := strconv.Atoi(windowStr); err == nil && sec > 0 {
+			config.HealthDropWindow = time.Duration(sec) * time.Second
+		}
+	}
+
+	if stallStr := envconfig.Lookup("AUDIO_STALL_TIMEOUT_MS"); stallStr != "" {
+		if ms, err := strconv.Atoi(stallStr); err == nil && ms >= 0 {
+			config.AudioStallTimeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if attemptsStr := envconfig.Lookup("RECONNECT_MAX_ATTEMPTS"); attemptsStr != "" {
+		if n, err := strconv.Atoi(attemptsStr); err == nil && n >= 0 {
+			config.ReconnectMaxAttempts = n
+		}
+	}
+
+	if baseStr := envconfig.Lookup("RECONNECT_BASE_DELAY_MS"); baseStr != "" {
+		if ms, err := strconv.Atoi(baseStr); err == nil && ms > 0 {
+			config.ReconnectBaseDelay = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if maxStr := envconfig.Lookup("RECONNECT_MAX_DELAY_MS"); maxStr != "" {
+		if ms, err := strconv.Atoi(maxStr); err == nil && ms > 0 {
+			config.ReconnectMaxDelay = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if timeoutStr := envconfig.Lookup("MP3_INIT_TIMEOUT_MS"); timeoutStr != "" {
+		if ms, err := strconv.Atoi(timeoutStr); err == nil && ms > 0 {
+			config.MP3InitTimeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if dbStr := envconfig.Lookup("DUCK_TTS_DB"); dbStr != "" {
+		if db, err := strconv.ParseFloat(dbStr, 64); err == nil && db >= 0 && db <= 60 {
+			config.DuckDB = db
+		}
+	}
+
+	if attackStr := envconfig.Lookup("DUCK_ATTACK_MS"); attackStr != "" {
+		if ms, err := strconv.Atoi(attackStr); err == nil && ms >= 0 && ms <= 5000 {
+			config.DuckAttack = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if releaseStr := envconfig.Lookup("DUCK_RELEASE_MS"); releaseStr != "" {
+		if ms, err := strconv.Atoi(releaseStr); err == nil && ms >= 0 && ms <= 5000 {
+			config.DuckRelease = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if attemptsStr := envconfig.Lookup("PLAYBACK_RESUME_ATTEMPTS"); attemptsStr != "" {
+		if n, err := strconv.Atoi(attemptsStr); err == nil && n >= 0 {
+			config.PlaybackResumeAttempts = n
+		}
+	}
+
+	if cacheStr := envconfig.Lookup("PLAYBACK_CACHE_MB"); cacheStr != "" {
+		if mb, err := strconv.Atoi(cacheStr); err == nil && mb >= 0 {
+			config.PlaybackCacheBytes = int64(mb) << 20
+		}
+	}
+
+	if errorsStr := envconfig.Lookup("HLS_MAX_SEGMENT_ERRORS"); errorsStr != "" {
+		if n, err := strconv.Atoi(errorsStr); err == nil && n > 0 {
+			config.HLSMaxSegmentErrors = n
+		}
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
-// getEnv gets an environment variable with a default fallback
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// validate checks the configured fields, so a bad deployment fails at
+// startup rather than on the first JoinRoom. LIVEKIT_URL is optional here
+// since each JoinRoom carries its own URL.
+func (c *Config) validate() error {
+	if _, err := strconv.Atoi(c.Port); err != nil {
+		return fmt.Errorf("PORT must be a number, got %q", c.Port)
+	}
+	if c.LiveKitURL != "" {
+		if err := validateLiveKitURL(c.LiveKitURL); err != nil {
+			return err
+		}
+	}
+	if c.AudioStallAction != "warn" && c.AudioStallAction != "reconnect" {
+		return fmt.Errorf("AUDIO_STALL_ACTION must be warn or reconnect, got %q", c.AudioStallAction)
+	}
+	return nil
+}
+
+// validateLiveKitURL requires a ws:// or wss:// URL with a host
+func validateLiveKitURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("LIVEKIT_URL is not a valid URL: %v", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return fmt.Errorf("LIVEKIT_URL must use ws:// or wss://, got %q", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("LIVEKIT_URL has no host: %q", raw)
 	}
-	return defaultValue
+	return nil
 }
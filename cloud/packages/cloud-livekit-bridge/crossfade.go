@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// Crossfade: a PlayAudio that replaces the one playing on its track doesn't
+// cut it off. The old playback keeps writing until the new one has audio;
+// from then its next samples are taken instead of written, mixed under the
+// start of the new playback on equal-power ramps, and the old playback is
+// cancelled once the fade has all it needs. A slow fetch of the new audio
+// therefore never leaves a gap. A PlayAudio arriving mid-fade chains a new
+// fade after the pending one rather than cutting it short: the playback
+// being faded in keeps fading in, and that faded audio is what the newest
+// playback fades in over.
+
+// errPlaybackReplaced is the cancel cause of a playback that a newer
+// PlayAudio on the same track took over from
+var errPlaybackReplaced = errors.New("replaced by a newer PlayAudio")
+
+// crossfadeStall is how long the new playback waits for the old one's audio
+// before fading in over silence instead
+const crossfadeStall = 100 * time.Millisecond
+
+// crossfade hands the end of a replaced playback to the one replacing it.
+// Playbacks are told apart by their pacers.
+type crossfade struct {
+	out, in   *audio.Pacer
+	cancelOut context.CancelCauseFunc
+	total     int        // length of the fade in samples
+	prev      *crossfade // fade still bringing out in, if any; guarded by RoomSession.mu
+
+	mu      sync.Mutex
+	started bool    // the new playback has written
+	tail    []int16 // outgoing audio taken so far
+	mixed   int     // samples of the fade written
+	outDone bool    // no more outgoing audio is coming
+	more    chan struct{}
+}
+
+func newCrossfade(out, in *audio.Pacer, cancelOut context.CancelCauseFunc, samples int) *crossfade {
+	return &crossfade{
+		out:       out,
+		in:        in,
+		cancelOut: cancelOut,
+		total:     samples,
+		tail:      make([]int16, 0, samples),
+		more:      make(chan struct{}, 1),
+	}
+}
+
+// take absorbs samples the outgoing playback is about to write. It returns
+// false before the fade starts, when they should be written as usual.
+func (x *crossfade) take(samples []int16) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.started {
+		return false
+	}
+	if !x.outDone {
+		n := min(len(samples), x.total-len(x.tail))
+		x.tail = append(x.tail, samples[:n]...)
+		if len(x.tail) == x.total {
+			x.finishLocked()
+		}
+		x.signal()
+	}
+	return true
+}
+
+// mix fades samples in over the outgoing audio, in place. When the outgoing
+// playback falls behind it waits up to crossfadeStall for more.
+func (x *crossfade) mix(samples []int16) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.started {
+		x.started = true
+		if x.outDone && len(x.tail) == 0 {
+			x.mixed = x.total // the old playback already ended; nothing to fade
+		}
+	}
+	for i := range samples {
+		if x.mixed >= x.total {
+			return
+		}
+		if x.mixed >= len(x.tail) && !x.outDone {
+			x.waitLocked()
+		}
+
+		t := float64(x.mixed) / float64(x.total) * math.Pi / 2
+		v := float64(samples[i]) * math.Sin(t)
+		if x.mixed < len(x.tail) {
+			v += float64(x.tail[x.mixed]) * math.Cos(t)
+		}
+		samples[i] = int16(max(-32768, min(32767, v)))
+		x.mixed++
+	}
+}
+
+// waitLocked waits for outgoing audio past what has been mixed, ending the
+// outgoing playback if none arrives within crossfadeStall. x.mu must be held.
+func (x *crossfade) waitLocked() {
+	timer := time.NewTimer(crossfadeStall)
+	defer timer.Stop()
+	for x.mixed >= len(x.tail) && !x.outDone {
+		x.mu.Unlock()
+		select {
+		case <-x.more:
+			x.mu.Lock()
+		case <-timer.C:
+			x.mu.Lock()
+			if x.mixed >= len(x.tail) {
+				x.finishLocked()
+			}
+		}
+	}
+}
+
+// outEnded records that the outgoing playback stopped on its own
+func (x *crossfade) outEnded() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.outDone = true
+	x.signal()
+}
+
+// finish cancels the outgoing playback if the fade hasn't already
+func (x *crossfade) finish() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.finishLocked()
+}
+
+func (x *crossfade) finishLocked() {
+	if !x.outDone {
+		x.outDone = true
+		x.cancelOut(errPlaybackReplaced)
+	}
+}
+
+// signal wakes a mix waiting for outgoing audio. x.mu must be held.
+func (x *crossfade) signal() {
+	select {
+	case x.more <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/livekit/media-sdk"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/pion/webrtc/v4"
+)
+
+// recordTrack stands in for a published track and keeps what was written
+type recordTrack struct {
+	webrtc.TrackLocal
+
+	mu      sync.Mutex
+	samples []int16
+}
+
+func (t *recordTrack) WriteSample(chunk media.PCM16Sample) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.samples = append(t.samples, chunk...)
+	return nil
+}
+
+func (t *recordTrack) Close() {}
+
+func constPCM(v int16, n int) []byte {
+	s := make([]int16, n)
+	for i := range s {
+		s[i] = v
+	}
+	return audio.Int16ToBytes(s)
+}
+
+// TestCrossfadeChainsMidFade starts a third playback halfway through the
+// fade from the first to the second and checks the second keeps fading in
+// rather than jumping to full level under the third
+func TestCrossfadeChainsMidFade(t *testing.T) {
+	const fade = 100
+	const level = 20000
+	s := NewRoomSession("u", nil)
+	track := &recordTrack{}
+	s.room = &lksdk.Room{}
+	s.tracks["speaker"] = track
+	s.crossfadeSamples = fade
+	defer func() {
+		s.room = nil
+		s.Close()
+	}()
+
+	begin := func() (*audio.Pacer, context.Context) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		pacer, ok := s.beginPlayback(cancel, "speaker")
+		if !ok {
+			t.Fatal("beginPlayback refused")
+		}
+		return pacer, ctx
+	}
+	// write runs a write that may wait on another playback's audio
+	write := func(pacer *audio.Pacer, pcm []byte) chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if err := s.writePlayback(pacer, pcm, "speaker"); err != nil {
+				t.Error(err)
+			}
+		}()
+		return done
+	}
+
+	// started waits until the newest fade's incoming playback has written,
+	// so the outgoing playback's next audio is taken for it
+	started := func() {
+		deadline := time.Now().Add(time.Second)
+		for {
+			s.mu.RLock()
+			xf := s.crossfades["speaker"]
+			s.mu.RUnlock()
+			xf.mu.Lock()
+			ok := xf.started
+			xf.mu.Unlock()
+			if ok {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("fade never started")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	a, aCtx := begin()
+	b, bCtx := begin()
+	// B fades in over silence from A; stop halfway
+	mixed := write(b, constPCM(level, fade/2))
+	started()
+	<-write(a, constPCM(0, fade))
+	<-mixed
+	if aCtx.Err() == nil {
+		t.Error("A not cancelled once its fade-out was taken")
+	}
+
+	c, _ := begin()
+	mixed = write(c, constPCM(0, fade))
+	started()
+	<-write(b, constPCM(level, fade))
+	<-mixed
+	if bCtx.Err() == nil {
+		t.Error("B not cancelled once its fade-out was taken")
+	}
+
+	// C's first sample is B's next one: B halfway through fading in
+	track.mu.Lock()
+	got := track.samples[len(track.samples)-fade]
+	track.mu.Unlock()
+	want := int16(level * math.Sin(math.Pi/4))
+	if got < want-2 || got > want+2 {
+		t.Errorf("B under C starts at %d, want %d (half faded in)", got, want)
+	}
+
+	for _, p := range []*audio.Pacer{a, b, c} {
+		s.endPlayback(p)
+	}
+	s.mu.RLock()
+	left := len(s.crossfades)
+	s.mu.RUnlock()
+	if left != 0 {
+		t.Errorf("%d crossfades left after every playback ended", left)
+	}
+}
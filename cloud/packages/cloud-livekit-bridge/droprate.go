@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// dropMeter tracks received vs dropped LiveKit packets across all sessions
+// over a sliding window of one-second buckets
+type dropMeter struct {
+	mu      sync.Mutex
+	buckets []dropBucket
+}
+
+type dropBucket struct {
+	second   int64
+	received int64
+	dropped  int64
+}
+
+// minPacketsForRate keeps a handful of drops on an idle instance from
+// flipping health
+const minPacketsForRate = 500
+
+func newDropMeter(window time.Duration) *dropMeter {
+	n := int(window / time.Second)
+	if n < 1 {
+		n = 1
+	}
+	return &dropMeter{buckets: make([]dropBucket, n)}
+}
+
+// add records one received packet, and whether it was dropped
+func (m *dropMeter) add(dropped bool) {
+	sec := time.Now().Unix()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b := &m.buckets[sec%int64(len(m.buckets))]
+	if b.second != sec {
+		*b = dropBucket{second: sec}
+	}
+	b.received++
+	if dropped {
+		b.dropped++
+	}
+}
+
+// rate returns the drop fraction over the window and the packets it covers
+func (m *dropMeter) rate() (float64, int64) {
+	oldest := time.Now().Unix() - int64(len(m.buckets)) + 1
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var received, dropped int64
+	for _, b := range m.buckets {
+		if b.second >= oldest {
+			received += b.received
+			dropped += b.dropped
+		}
+	}
+	if received == 0 {
+		return 0, 0
+	}
+	return float64(dropped) / float64(received), received
+}
+
+// degraded reports whether the drop rate is over maxRate (0 = never)
+func (m *dropMeter) degraded(maxRate float64) bool {
+	if maxRate <= 0 {
+		return false
+	}
+	rate, n := m.rate()
+	return n >= minPacketsForRate && rate > maxRate
+}
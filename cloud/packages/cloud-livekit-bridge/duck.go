@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Ducking: while the tts track is playing, the speaker and app_audio tracks
+// are attenuated so spoken prompts aren't drowned out. TTS counts as playing
+// until the audio written to it has played out, plus duckHold so the gaps
+// between sentences don't pump the other tracks up and down. Gain ramps
+// down over the attack time and back up over the release time. The user's
+// microphone isn't published by the bridge, so there is no mic track to
+// duck here.
+
+// duckedTracks are attenuated while tts plays
+var duckedTracks = map[string]bool{"speaker": true, "app_audio": true}
+
+// duckHold keeps tracks ducked through short pauses in TTS
+const duckHold = 300 * time.Millisecond
+
+// ducker tracks TTS activity for a session and applies the ducking gain
+type ducker struct {
+	floor    float64 // linear gain while ducked
+	downStep float64 // gain change per sample while ramping down
+	upStep   float64 // and back up
+
+	mu       sync.Mutex
+	ttsUntil time.Time          // when the TTS written so far finishes playing
+	gains    map[string]float64 // current gain per ducked track
+}
+
+// newDucker attenuates by db, ramping over attack and release
+func newDucker(db float64, attack, release time.Duration) *ducker {
+	floor := math.Pow(10, -db/20)
+	return &ducker{
+		floor:    floor,
+		downStep: rampStep(1-floor, attack),
+		upStep:   rampStep(1-floor, release),
+		gains:    make(map[string]float64),
+	}
+}
+
+// rampStep is the per-sample change that covers span in d at the track rate
+func rampStep(span float64, d time.Duration) float64 {
+	samples := d.Seconds() * trackSampleRate
+	if samples < 1 {
+		return span
+	}
+	return span / samples
+}
+
+// noteTTS accounts for n samples written to the tts track
+func (d *ducker) noteTTS(n int, now time.Time) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ttsUntil.Before(now) {
+		d.ttsUntil = now
+	}
+	d.ttsUntil = d.ttsUntil.Add(time.Duration(n) * time.Second / trackSampleRate)
+}
+
+// apply ducks a frame about to be written to trackName, in place
+func (d *ducker) apply(trackName string, frame []int16, now time.Time) {
+	if d == nil || !duckedTracks[trackName] {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	target := 1.0
+	if now.Before(d.ttsUntil.Add(duckHold)) {
+		target = d.floor
+	}
+	gain, ok := d.gains[trackName]
+	if !ok {
+		gain = 1.0
+	}
+	if gain == target && target == 1.0 {
+		return
+	}
+	for i, s := range frame {
+		if gain > target {
+			gain = math.Max(target, gain-d.downStep)
+		} else if gain < target {
+			gain = math.Min(target, gain+d.upStep)
+		}
+		frame[i] = int16(float64(s) * gain)
+	}
+	d.gains[trackName] = gain
+}
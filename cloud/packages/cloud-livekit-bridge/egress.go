@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// Room egress: full room recordings run by LiveKit's egress service rather
+// than the bridge, so the server can trigger them through the same
+// connection it already uses for audio. Requests are signed with the
+// bridge's API key and secret.
+
+// egressTimeout bounds each call to the LiveKit Egress API
+const egressTimeout = 10 * time.Second
+
+var errEgressCredentials = errors.New("room egress needs LIVEKIT_API_KEY and LIVEKIT_API_SECRET")
+
+// egressClient returns a client for the LIVEKIT_URL server. Requests are
+// signed with the bridge's own key, so they never go to a caller-chosen host.
+func (s *LiveKitBridgeService) egressClient() (*lksdk.EgressClient, error) {
+	if s.config.LiveKitAPIKey == "" || s.config.LiveKitAPISecret == "" {
+		return nil, errEgressCredentials
+	}
+	if s.config.LiveKitURL == "" {
+		return nil, errors.New("room egress needs LIVEKIT_URL")
+	}
+	if err := validateLiveKitURL(s.config.LiveKitURL); err != nil {
+		return nil, err
+	}
+	return lksdk.NewEgressClient(s.config.LiveKitURL, s.config.LiveKitAPIKey, s.config.LiveKitAPISecret), nil
+}
+
+// StartRoomEgress starts a room composite recording to a file
+func (s *LiveKitBridgeService) StartRoomEgress(
+	ctx context.Context,
+	req *pb.StartRoomEgressRequest,
+) (*pb.StartRoomEgressResponse, error) {
+	log.Printf("StartRoomEgress request: room=%s, audioOnly=%v, fileType=%s", req.RoomName, req.AudioOnly, req.FileType)
+
+	if req.RoomName == "" {
+		return &pb.StartRoomEgressResponse{
+			Success: false,
+			Error:   "room_name is required",
+		}, nil
+	}
+	fileType := livekit.EncodedFileType_DEFAULT_FILETYPE
+	if req.FileType != "" {
+		// EncodedFileType also has MP3, which isn't offered here
+		switch strings.ToLower(req.FileType) {
+		case "mp4":
+			fileType = livekit.EncodedFileType_MP4
+		case "ogg":
+			fileType = livekit.EncodedFileType_OGG
+		default:
+			return &pb.StartRoomEgressResponse{
+				Success: false,
+				Error:   fmt.Sprintf("file_type must be mp4 or ogg, got %q", req.FileType),
+			}, nil
+		}
+	}
+	client, err := s.egressClient()
+	if err != nil {
+		return &pb.StartRoomEgressResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, egressTimeout)
+	defer cancel()
+	info, err := client.StartRoomCompositeEgress(ctx, &livekit.RoomCompositeEgressRequest{
+		RoomName:  req.RoomName,
+		Layout:    req.Layout,
+		AudioOnly: req.AudioOnly,
+		FileOutputs: []*livekit.EncodedFileOutput{{
+			FileType: fileType,
+			Filepath: req.Filepath,
+		}},
+	})
+	if err != nil {
+		s.bsLogger.LogWarn("StartRoomEgress failed", map[string]interface{}{
+			"room_name": req.RoomName,
+			"error":     err.Error(),
+		})
+		return &pb.StartRoomEgressResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	s.bsLogger.LogInfo("Room egress started", map[string]interface{}{
+		"room_name": req.RoomName,
+		"egress_id": info.EgressId,
+	})
+	return &pb.StartRoomEgressResponse{
+		Success:  true,
+		EgressId: info.EgressId,
+		Status:   info.Status.String(),
+	}, nil
+}
+
+// StopRoomEgress stops a recording started by StartRoomEgress
+func (s *LiveKitBridgeService) StopRoomEgress(
+	ctx context.Context,
+	req *pb.StopRoomEgressRequest,
+) (*pb.StopRoomEgressResponse, error) {
+	log.Printf("StopRoomEgress request: egressId=%s", req.EgressId)
+
+	if req.EgressId == "" {
+		return &pb.StopRoomEgressResponse{
+			Success: false,
+			Error:   "egress_id is required",
+		}, nil
+	}
+	client, err := s.egressClient()
+	if err != nil {
+		return &pb.StopRoomEgressResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, egressTimeout)
+	defer cancel()
+	info, err := client.StopEgress(ctx, &livekit.StopEgressRequest{EgressId: req.EgressId})
+	if err != nil {
+		s.bsLogger.LogWarn("StopRoomEgress failed", map[string]interface{}{
+			"egress_id": req.EgressId,
+			"error":     err.Error(),
+		})
+		return &pb.StopRoomEgressResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	resp := &pb.StopRoomEgressResponse{
+		Success: true,
+		Status:  info.Status.String(),
+	}
+	if len(info.FileResults) > 0 {
+		resp.FileLocation = info.FileResults[0].Location
+	}
+	return resp, nil
+}
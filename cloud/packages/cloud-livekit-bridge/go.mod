@@ -1,14 +1,25 @@
 module github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge
 
-go 1.24.2
-
-toolchain go1.24.6
+// github.com/skrashevich/go-aac (AAC/M4A playback) declares go 1.25.6, so
+// the bridge can't build with an older go line. The Dockerfile's
+// golang:1.25 image satisfies it; the WebSocket client stays on 1.24.
+go 1.25.6
 
 require (
+	github.com/Mentra-Community/MentraOS/cloud/pkg/audio v0.0.0-00010101000000-000000000000
+	github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil v0.0.0-00010101000000-000000000000
+	github.com/Mentra-Community/MentraOS/cloud/pkg/g711track v0.0.0-00010101000000-000000000000
+	github.com/abema/go-mp4 v1.4.1
 	github.com/gorilla/websocket v1.5.3
 	github.com/hajimehoshi/go-mp3 v0.3.4
+	github.com/livekit/media-sdk v0.0.0-20250518151703-b07af88637c5
 	github.com/livekit/mediatransportutil v0.0.0-20250519131108-fb90f5acfded
+	github.com/livekit/protocol v1.39.4-0.20250807105828-ccbae8154e54
 	github.com/livekit/server-sdk-go/v2 v2.10.0
+	github.com/pion/webrtc/v4 v4.1.3
+	github.com/skrashevich/go-aac v0.1.0
+	google.golang.org/grpc v1.74.2
+	google.golang.org/protobuf v1.36.7
 )
 
 require (
@@ -36,8 +47,6 @@ require (
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/lithammer/shortuuid/v4 v4.2.0 // indirect
 	github.com/livekit/mageutil v0.0.0-20250511045019-0f1ff63f7731 // indirect
-	github.com/livekit/media-sdk v0.0.0-20250518151703-b07af88637c5 // indirect
-	github.com/livekit/protocol v1.39.4-0.20250807105828-ccbae8154e54 // indirect
 	github.com/livekit/psrpc v0.6.1-0.20250726180611-3915e005e741 // indirect
 	github.com/magefile/mage v1.15.0 // indirect
 	github.com/nats-io/nats.go v1.44.0 // indirect
@@ -58,7 +67,6 @@ require (
 	github.com/pion/stun/v3 v3.0.0 // indirect
 	github.com/pion/transport/v3 v3.0.7 // indirect
 	github.com/pion/turn/v4 v4.0.2 // indirect
-	github.com/pion/webrtc/v4 v4.1.3 // indirect
 	github.com/puzpuzpuz/xsync/v3 v3.5.1 // indirect
 	github.com/redis/go-redis/v9 v9.12.0 // indirect
 	github.com/stoewer/go-strcase v1.3.1 // indirect
@@ -78,8 +86,12 @@ require (
 	golang.org/x/text v0.27.0 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250804133106-a7a43d27e69b // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
-	google.golang.org/grpc v1.74.2 // indirect
-	google.golang.org/protobuf v1.36.7 // indirect
 	gopkg.in/hraban/opus.v2 v2.0.0-20230925203106-0188a62cb302 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/Mentra-Community/MentraOS/cloud/pkg/audio => ../../pkg/audio
+
+replace github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil => ../../pkg/bridgeutil
+
+replace github.com/Mentra-Community/MentraOS/cloud/pkg/g711track => ../../pkg/g711track
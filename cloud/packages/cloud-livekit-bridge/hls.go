@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/retry"
+	"google.golang.org/protobuf/proto"
+)
+
+// HLS playback.
+//
+// An m3u8 URL is played segment by segment. A master playlist resolves to
+// its lowest-bandwidth variant, since playback ends up 16kHz mono anyway.
+// Segments are packed audio (ADTS AAC or MP3, usually behind an ID3
+// timestamp tag) or MPEG-TS carrying either; each is fetched whole and its
+// audio joined into one elementary stream for playAAC or playMP3.
+//
+// Live playlists (no EXT-X-ENDLIST) start three segments from the live edge
+// and are reloaded until StopAudio. A segment that still fails after retries
+// is skipped; playback only fails after HLSMaxSegmentErrors failed segments
+// or playlist reloads in a row. Encrypted and fMP4 segments are unsupported.
+
+// Size limits for fetched playlists and segments
+const (
+	maxPlaylistBytes   = 1 << 20
+	maxHLSSegmentBytes = 16 << 20
+)
+
+// Fetch attempts per segment or playlist reload before it counts as failed
+const (
+	hlsFetchAttempts   = 3
+	hlsFetchRetryDelay = 250 * time.Millisecond
+)
+
+// hlsMinFetchTimeout bounds a single fetch; longer target durations get twice
+// their length
+const hlsMinFetchTimeout = 10 * time.Second
+
+// liveEdgeSegments is how far from the end of a live playlist playback starts
+const liveEdgeSegments = 3
+
+// isHLS reports whether a response is an HLS playlist
+func isHLS(contentType, url string) bool {
+	return strings.Contains(contentType, "mpegurl") || strings.HasSuffix(url, ".m3u8")
+}
+
+type hlsSegment struct {
+	seq      int64
+	uri      *url.URL
+	duration time.Duration
+}
+
+type hlsVariant struct {
+	uri       *url.URL
+	bandwidth int64
+}
+
+// hlsPlaylist is a parsed media or master playlist
+type hlsPlaylist struct {
+	targetDuration time.Duration
+	segments       []hlsSegment
+	ended          bool
+	variants       []hlsVariant // master playlists only
+}
+
+// parsePlaylist parses an m3u8 playlist, resolving URIs against base
+func parsePlaylist(base *url.URL, r io.Reader) (*hlsPlaylist, error) {
+	scanner := bufio.NewScanner(io.LimitReader(r, maxPlaylistBytes))
+	scanner.Buffer(make([]byte, 0, 4096), maxPlaylistBytes)
+
+	pl := &hlsPlaylist{}
+	var (
+		header   bool
+		seq      int64
+		duration time.Duration
+		variant  *hlsVariant
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !header {
+			if line != "#EXTM3U" {
+				return nil, fmt.Errorf("not an m3u8 playlist")
+			}
+			header = true
+			continue
+		}
+
+		tag, value, _ := strings.Cut(line, ":")
+		switch tag {
+		case "#EXT-X-TARGETDURATION":
+			secs, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid EXT-X-TARGETDURATION %q", value)
+			}
+			pl.targetDuration = time.Duration(secs * float64(time.Second))
+		case "#EXT-X-MEDIA-SEQUENCE":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid EXT-X-MEDIA-SEQUENCE %q", value)
+			}
+			seq = n
+		case "#EXTINF":
+			secs, _, _ := strings.Cut(value, ",")
+			d, err := strconv.ParseFloat(secs, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid EXTINF %q", value)
+			}
+			duration = time.Duration(d * float64(time.Second))
+		case "#EXT-X-ENDLIST":
+			pl.ended = true
+		case "#EXT-X-KEY":
+			if method := parseAttributes(value)["METHOD"]; method != "NONE" {
+				return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("encrypted HLS (%s) is not supported", method))
+			}
+		case "#EXT-X-MAP":
+			return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("fMP4 HLS segments are not supported"))
+		case "#EXT-X-STREAM-INF":
+			bandwidth, _ := strconv.ParseInt(parseAttributes(value)["BANDWIDTH"], 10, 64)
+			variant = &hlsVariant{bandwidth: bandwidth}
+		default:
+			if strings.HasPrefix(line, "#") {
+				continue // comment or a tag we don't need
+			}
+			uri, err := base.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid URI %q: %w", line, err)
+			}
+			if variant != nil {
+				variant.uri = uri
+				pl.variants = append(pl.variants, *variant)
+				variant = nil
+				continue
+			}
+			pl.segments = append(pl.segments, hlsSegment{seq: seq, uri: uri, duration: duration})
+			seq++
+			duration = 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("empty playlist")
+	}
+	return pl, nil
+}
+
+// parseAttributes parses an attribute list (KEY=value,KEY="quoted, value")
+func parseAttributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	for s != "" {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+			rest = strings.TrimPrefix(rest, ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		attrs[strings.TrimSpace(key)] = value
+		s = rest
+	}
+	return attrs
+}
+
+// hlsStream reads the joined audio of a media playlist's segments
+type hlsStream struct {
+	ctx       context.Context
+	url       *url.URL // media playlist, for live reloads
+	playlist  *hlsPlaylist
+	nextSeq   int64
+	pending   []byte // rest of the current segment
+	failures  int    // segments or reloads failed in a row
+	maxErrors int
+
+	lastReload time.Time
+	grew       bool // the last reload added segments
+}
+
+// Read returns audio from the current segment, fetching the next one (and
+// reloading a live playlist) as needed
+func (h *hlsStream) Read(p []byte) (int, error) {
+	for len(h.pending) == 0 {
+		if err := h.nextSegment(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, h.pending)
+	h.pending = h.pending[n:]
+	return n, nil
+}
+
+func (h *hlsStream) nextSegment() error {
+	for {
+		seg, ok := h.nextInPlaylist()
+		if !ok {
+			if h.playlist.ended {
+				return io.EOF
+			}
+			if err := h.reload(); err != nil {
+				return err
+			}
+			continue
+		}
+		if seg.seq > h.nextSeq {
+			log.Printf("HLS skipped %d segments that left the playlist: url=%s", seg.seq-h.nextSeq, h.url)
+		}
+		h.nextSeq = seg.seq + 1
+
+		var data []byte
+		err := retry.Transient(h.ctx, hlsFetchAttempts, hlsFetchRetryDelay, func() error {
+			var err error
+			data, err = h.fetch(seg.uri, maxHLSSegmentBytes)
+			return err
+		})
+		if err == nil {
+			data, err = segmentAudio(data)
+		}
+		if err != nil {
+			if h.ctx.Err() != nil {
+				return h.ctx.Err()
+			}
+			if err := h.failed(fmt.Errorf("segment %d: %w", seg.seq, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		h.failures = 0
+		h.pending = data
+		return nil
+	}
+}
+
+// nextInPlaylist returns the first segment at or after nextSeq
+func (h *hlsStream) nextInPlaylist() (hlsSegment, bool) {
+	for _, seg := range h.playlist.segments {
+		if seg.seq >= h.nextSeq {
+			return seg, true
+		}
+	}
+	return hlsSegment{}, false
+}
+
+// reload waits out the reload interval and refetches a live playlist: the
+// target duration after it grew, half of it otherwise
+func (h *hlsStream) reload() error {
+	wait := h.playlist.targetDuration
+	if !h.grew {
+		wait /= 2
+	}
+	if wait <= 0 {
+		wait = time.Second
+	}
+	timer := time.NewTimer(time.Until(h.lastReload.Add(wait)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-h.ctx.Done():
+		return h.ctx.Err()
+	}
+
+	h.lastReload = time.Now()
+	var pl *hlsPlaylist
+	err := retry.Transient(h.ctx, hlsFetchAttempts, hlsFetchRetryDelay, func() error {
+		data, err := h.fetch(h.url, maxPlaylistBytes)
+		if err != nil {
+			return err
+		}
+		pl, err = parsePlaylist(h.url, bytes.NewReader(data))
+		return err
+	})
+	if err != nil {
+		if h.ctx.Err() != nil {
+			return h.ctx.Err()
+		}
+		return h.failed(fmt.Errorf("playlist reload: %w", err))
+	}
+	last := h.playlist.segments
+	h.grew = len(pl.segments) > 0 && (len(last) == 0 || pl.segments[len(pl.segments)-1].seq > last[len(last)-1].seq)
+	h.playlist = pl
+	return nil
+}
+
+// failed counts a failed segment or reload, and gives up once too many have
+// failed in a row
+func (h *hlsStream) failed(err error) error {
+	h.failures++
+	log.Printf("HLS %v (%d/%d in a row): url=%s", err, h.failures, h.maxErrors, h.url)
+	if h.failures >= h.maxErrors {
+		return playerr.Fail(playerr.FetchFailed, fmt.Errorf("HLS stream failed %d times in a row: %w", h.failures, err))
+	}
+	return nil
+}
+
+// fetch GETs u in full, up to limit bytes
+func (h *hlsStream) fetch(u *url.URL, limit int64) ([]byte, error) {
+	return fetchHLS(h.ctx, u, limit, h.playlist.targetDuration)
+}
+
+// fetchHLS GETs u in full, up to limit bytes, timing out after twice the
+// target duration (at least hlsMinFetchTimeout)
+func fetchHLS(ctx context.Context, u *url.URL, limit int64, targetDuration time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, max(hlsMinFetchTimeout, 2*targetDuration))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, playerr.Fail(playerr.InvalidURL, fmt.Errorf("invalid URL: %w", err))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, playerr.Fail(playerr.FetchFailed, fmt.Errorf("failed to fetch %s: %w", u, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, playerr.Fail(playerr.HTTPStatus, fmt.Errorf("HTTP error fetching %s: %d %s", u, resp.StatusCode, resp.Status))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, playerr.Fail(playerr.FetchFailed, fmt.Errorf("failed to read %s: %w", u, err))
+	}
+	if int64(len(data)) > limit {
+		return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("%s is larger than %d bytes", u, limit))
+	}
+	return data, nil
+}
+
+// segmentAudio returns a segment's elementary audio stream: demuxed from
+// MPEG-TS, or packed audio with its ID3 tag removed
+func segmentAudio(data []byte) ([]byte, error) {
+	if isMPEGTS(data) {
+		return demuxTSAudio(data)
+	}
+	br := bufio.NewReader(bytes.NewReader(data))
+	if err := skipID3(br); err != nil {
+		return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("failed to skip ID3 tag: %w", err))
+	}
+	return io.ReadAll(br)
+}
+
+// playHLS plays the HLS playlist read from body, fetched from base
+func (s *LiveKitBridgeService) playHLS(
+	ctx context.Context,
+	base *url.URL,
+	body io.Reader,
+	req *pb.PlayAudioRequest,
+	session *RoomSession,
+	trackName string,
+	pacer *audio.Pacer,
+) (int64, error) {
+	playlist, err := parsePlaylist(base, body)
+	if err != nil {
+		return 0, asPlayError(playerr.MalformedAudio, fmt.Errorf("invalid HLS playlist: %w", err))
+	}
+
+	// A master playlist names variants; take the lightest
+	mediaURL := base
+	if len(playlist.variants) > 0 {
+		variant := playlist.variants[0]
+		for _, v := range playlist.variants[1:] {
+			if v.bandwidth < variant.bandwidth {
+				variant = v
+			}
+		}
+		mediaURL = variant.uri
+		data, err := fetchHLS(ctx, mediaURL, maxPlaylistBytes, 0)
+		if err != nil {
+			return 0, err
+		}
+		if playlist, err = parsePlaylist(mediaURL, bytes.NewReader(data)); err != nil {
+			return 0, asPlayError(playerr.MalformedAudio, fmt.Errorf("invalid HLS playlist: %w", err))
+		}
+		if len(playlist.variants) > 0 {
+			return 0, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("HLS variant %s is another master playlist", mediaURL))
+		}
+	}
+	if len(playlist.segments) == 0 && playlist.ended {
+		return 0, errEmptyAudio
+	}
+
+	h := &hlsStream{
+		ctx:        ctx,
+		url:        mediaURL,
+		playlist:   playlist,
+		maxErrors:  s.config.HLSMaxSegmentErrors,
+		lastReload: time.Now(),
+		grew:       true,
+	}
+	if len(playlist.segments) > 0 {
+		h.nextSeq = playlist.segments[0].seq
+	}
+
+	// A VOD playlist lists all of the audio
+	if playlist.ended {
+		var total time.Duration
+		for _, seg := range playlist.segments {
+			total += seg.duration
+		}
+		setPlayDuration(ctx, total)
+	}
+
+	if !playlist.ended {
+		if req.StartOffsetMs > 0 {
+			return 0, playerr.Fail(playerr.InvalidRequest, fmt.Errorf("start_offset_ms is not supported for live HLS streams"))
+		}
+		if n := len(playlist.segments); n > liveEdgeSegments {
+			h.nextSeq = playlist.segments[n-liveEdgeSegments].seq
+		}
+	} else if req.StartOffsetMs > 0 {
+		// Skip whole segments; the decoder drops the rest of the offset
+		offset := time.Duration(req.StartOffsetMs) * time.Millisecond
+		i := 0
+		for i < len(playlist.segments) && playlist.segments[i].duration <= offset {
+			offset -= playlist.segments[i].duration
+			i++
+		}
+		if i == len(playlist.segments) {
+			return 0, playerr.Fail(playerr.InvalidRequest, fmt.Errorf("start_offset_ms %d is past the end of the audio", req.StartOffsetMs))
+		}
+		h.nextSeq = playlist.segments[i].seq
+		req = proto.Clone(req).(*pb.PlayAudioRequest)
+		req.StartOffsetMs = offset.Milliseconds()
+	}
+
+	log.Printf("Playing HLS: url=%s, live=%v, segments=%d", mediaURL, !playlist.ended, len(playlist.segments))
+
+	// The first segment's audio tells the codec
+	r := bufio.NewReader(h)
+	hdr, err := r.Peek(2)
+	if len(hdr) < 2 {
+		if errors.Is(err, io.EOF) {
+			return 0, errEmptyAudio
+		}
+		return 0, err
+	}
+	switch {
+	case hdr[0] == 0xFF && hdr[1]&0xF6 == 0xF0: // ADTS
+		src, err := newADTSStream(r)
+		if err != nil {
+			return 0, err
+		}
+		return s.playAAC(ctx, src, req, session, trackName, pacer)
+	case hdr[0] == 0xFF && hdr[1]&0xE0 == 0xE0 && hdr[1]&0x06 != 0: // MPEG audio
+		return s.playMP3(ctx, r, req, session, trackName, pacer)
+	}
+	return 0, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("HLS segments are neither AAC nor MP3"))
+}
+
+// asPlayError tags err with code unless it already carries one
+func asPlayError(code playerr.Code, err error) error {
+	var pe *playerr.Error
+	if errors.As(err, &pe) {
+		return err
+	}
+	return playerr.Fail(code, err)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Speaking indicators for StreamAudioLevels.
+//
+// A level stream meters both kinds of room audio: data packets from every
+// sender, and published tracks, which it subscribes and decodes the same way
+// a SubscribeAudio stream does. Every interval the RMS per participant is
+// reported with a speaking flag.
+
+// Bounds for the StreamAudioLevels interval, in ms
+const (
+	minLevelIntervalMs     = 50
+	defaultLevelIntervalMs = 250
+)
+
+// addLevelMeter registers a meter to receive the room's data-packet audio
+func (s *RoomSession) addLevelMeter(meter *audio.LevelMeter) {
+	s.mu.Lock()
+	s.levelMeters[meter] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *RoomSession) removeLevelMeter(meter *audio.LevelMeter) {
+	s.mu.Lock()
+	delete(s.levelMeters, meter)
+	s.mu.Unlock()
+}
+
+// meterDataPacket feeds a data packet's audio to the open level streams
+func (s *RoomSession) meterDataPacket(identity string, packet lksdk.DataPacket) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.levelMeters) == 0 {
+		return
+	}
+	userPacket, ok := packet.(*lksdk.UserDataPacket)
+	if !ok {
+		return
+	}
+	pcm := userPacket.Payload
+	if len(pcm)%2 == 1 {
+		pcm = pcm[1:]
+	}
+	samples := audio.BytesToInt16(pcm)
+	for meter := range s.levelMeters {
+		meter.Add(identity, samples)
+	}
+}
+
+// StreamAudioLevels streams per-participant audio levels and speaking flags
+func (s *LiveKitBridgeService) StreamAudioLevels(
+	req *pb.StreamAudioLevelsRequest,
+	stream pb.LiveKitBridge_StreamAudioLevelsServer,
+) error {
+	log.Printf("StreamAudioLevels request: userId=%s, identities=%v, intervalMs=%d, speakingDb=%.1f",
+		req.UserId, req.Identities, req.IntervalMs, req.SpeakingThresholdDb)
+
+	interval := int(req.IntervalMs)
+	if interval == 0 {
+		interval = defaultLevelIntervalMs
+	}
+	if interval < minLevelIntervalMs {
+		return status.Errorf(codes.InvalidArgument, "interval_ms must be at least %d", minLevelIntervalMs)
+	}
+	threshold := float64(req.SpeakingThresholdDb)
+	if threshold == 0 {
+		threshold = audio.DefaultSpeakingDB
+	}
+	if threshold < audio.SilenceDB || threshold > 0 {
+		return status.Errorf(codes.InvalidArgument, "speaking_threshold_db must be between %d and 0", int(audio.SilenceDB))
+	}
+
+	session, ok := s.sessions.Get(req.UserId)
+	if !ok {
+		return status.Errorf(codes.NotFound, "session not found for user %s", req.UserId)
+	}
+
+	meter := &audio.LevelMeter{}
+	session.addLevelMeter(meter)
+	defer session.removeLevelMeter(meter)
+	// Track audio arrives through a subscriber like SubscribeAudio's
+	sub := session.addSubscriber(req.Identities, nil, false)
+	defer session.removeSubscriber(sub)
+
+	detector := &audio.SpeakingDetector{ThresholdDB: threshold, Hold: audio.DefaultSpeakingHold}
+	ticker := time.NewTicker(time.Duration(interval) * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case frame := <-sub.frames:
+			if frame.TrackEvent != nil {
+				continue
+			}
+			meter.Add(frame.ParticipantIdentity, audio.BytesToInt16(frame.PcmData))
+		case now := <-ticker.C:
+			levels := meter.Drain()
+			for identity := range levels {
+				if !sub.wants(identity) {
+					delete(levels, identity)
+				}
+			}
+			sources := detector.Update(levels, now)
+			if len(sources) == 0 {
+				continue
+			}
+			event := &pb.AudioLevelEvent{
+				Levels:      make([]*pb.ParticipantAudioLevel, len(sources)),
+				TimestampMs: now.UnixMilli(),
+			}
+			for i, src := range sources {
+				event.Levels[i] = &pb.ParticipantAudioLevel{
+					Identity: src.Source,
+					LevelDb:  float32(src.LevelDB),
+					Speaking: src.Speaking,
+				}
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			log.Printf("StreamAudioLevels ended: userId=%s", req.UserId)
+			return nil
+		case <-session.ctx.Done():
+			log.Printf("StreamAudioLevels ended, session closed: userId=%s", req.UserId)
+			return nil
+		}
+	}
+}
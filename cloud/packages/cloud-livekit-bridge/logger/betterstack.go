@@ -7,9 +7,10 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"sync"
 	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/envconfig"
 )
 
 // BetterStackLogger sends logs to Better Stack HTTP endpoint
@@ -236,8 +237,8 @@ func (l *BetterStackLogger) Close() {
 
 // NewFromEnv creates a BetterStackLogger from environment variables
 func NewFromEnv() *BetterStackLogger {
-	token := os.Getenv("BETTERSTACK_SOURCE_TOKEN")
-	host := os.Getenv("BETTERSTACK_INGESTING_HOST")
+	token := envconfig.Lookup("BETTERSTACK_SOURCE_TOKEN")
+	host := envconfig.Lookup("BETTERSTACK_INGESTING_HOST")
 	enabled := token != "" && host != ""
 
 	if !enabled {
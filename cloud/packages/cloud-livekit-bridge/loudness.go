@@ -0,0 +1,225 @@
+package main
+
+import (
+	"math"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// Loudness normalization for PlayAudio (ITU-R BS.1770 style measurement).
+//
+// Playback is streamed, so integrated loudness can't be known up front. The
+// normalizer holds back the first 400ms as look-ahead, measures it, and from
+// then on keeps a running integrated loudness over 400ms blocks (100ms hop)
+// and eases the gain toward target - measured. Only the absolute -70 LUFS
+// gate is applied; the relative gate needs the whole programme.
+const (
+	lufsAbsoluteGate = -70.0
+	lufsMaxBoostDB   = 20.0
+	lufsPeakCeiling  = 0.89 // -1 dBFS, for the look-ahead audio we can see
+	lufsGainEase     = 0.2  // fraction of the gain error corrected per block
+)
+
+// biquad is a direct form I second-order IIR filter
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting returns the BS.1770 pre-filter (high shelf + high-pass),
+// derived for sampleRate rather than the 48kHz table values
+func kWeighting(sampleRate float64) (shelf, highpass biquad) {
+	k := math.Tan(math.Pi * 1681.974450955533 / sampleRate)
+	q := 0.7071752369554196
+	vh := math.Pow(10, 3.999843853973347/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf = biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * 38.13547087602444 / sampleRate)
+	q = 0.5003270373238773
+	a0 = 1 + k/q + k*k
+	highpass = biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return shelf, highpass
+}
+
+// loudnessNormalizer applies a gain to mono PCM16 to reach a target LUFS
+type loudnessNormalizer struct {
+	target          float64
+	shelf, highpass biquad
+	hopSamples      int
+
+	hopSum   float64    // K-weighted sum of squares in the current hop
+	hopN     int        // samples in the current hop
+	hops     [4]float64 // mean squares of the last four hops (one block)
+	hopCount int
+
+	gatedSum    float64 // sum of block mean squares above the absolute gate
+	gatedBlocks int
+
+	gain     float64
+	haveGain bool
+	pending  []int16 // look-ahead held until the first block is measured
+}
+
+// newLoudnessNormalizer returns nil when targetLUFS is 0 (disabled)
+func newLoudnessNormalizer(targetLUFS float32, sampleRate int) *loudnessNormalizer {
+	if targetLUFS == 0 {
+		return nil
+	}
+	shelf, highpass := kWeighting(float64(sampleRate))
+	return &loudnessNormalizer{
+		target:     float64(targetLUFS),
+		shelf:      shelf,
+		highpass:   highpass,
+		hopSamples: sampleRate / 10,
+		gain:       1,
+	}
+}
+
+// process measures in and returns normalized audio. While the look-ahead is
+// filling it returns nil and releases the held audio once measured.
+func (n *loudnessNormalizer) process(in []int16) []int16 {
+	if n == nil {
+		return in
+	}
+	n.measure(in)
+
+	if !n.haveGain {
+		n.pending = append(n.pending, in...)
+		if n.hopCount < len(n.hops) {
+			return nil
+		}
+		return n.release()
+	}
+
+	n.updateGain()
+	audio.ApplyGain(in, n.gain)
+	return in
+}
+
+// flush returns whatever is still held in the look-ahead. For clips shorter
+// than one block the gain comes from the partial measurement, bounded by the
+// clip's peak.
+func (n *loudnessNormalizer) flush() []int16 {
+	if n == nil || n.haveGain || len(n.pending) == 0 {
+		return nil
+	}
+	return n.release()
+}
+
+// release sets the initial gain from what has been measured so far and
+// returns the look-ahead audio with that gain applied
+func (n *loudnessNormalizer) release() []int16 {
+	ms := 0.0
+	if n.gatedBlocks > 0 {
+		ms = n.gatedSum / float64(n.gatedBlocks)
+	} else if total := n.hopCount*n.hopSamples + n.hopN; total > 0 {
+		for i := len(n.hops) - n.hopCount; i < len(n.hops); i++ {
+			ms += n.hops[i] * float64(n.hopSamples)
+		}
+		ms = (ms + n.hopSum) / float64(total)
+	}
+	n.gain = n.targetGain(ms)
+
+	// The look-ahead's peak is known, so don't push it into clipping
+	var peak int32
+	for _, s := range n.pending {
+		v := int32(s)
+		if v < 0 {
+			v = -v
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+	if peak > 0 {
+		if maxGain := lufsPeakCeiling * 32767 / float64(peak); n.gain > maxGain {
+			n.gain = maxGain
+		}
+	}
+
+	n.haveGain = true
+	out := n.pending
+	n.pending = nil
+	audio.ApplyGain(out, n.gain)
+	return out
+}
+
+// updateGain eases the gain toward the running integrated loudness target
+func (n *loudnessNormalizer) updateGain() {
+	if n.gatedBlocks == 0 {
+		return
+	}
+	want := n.targetGain(n.gatedSum / float64(n.gatedBlocks))
+	n.gain += (want - n.gain) * lufsGainEase
+}
+
+// targetGain is the linear gain taking mean square ms to the target. Silence
+// (below the absolute gate) is left alone.
+func (n *loudnessNormalizer) targetGain(ms float64) float64 {
+	if ms <= 0 {
+		return 1
+	}
+	lufs := -0.691 + 10*math.Log10(ms)
+	if lufs < lufsAbsoluteGate {
+		return 1
+	}
+	db := n.target - lufs
+	if db > lufsMaxBoostDB {
+		db = lufsMaxBoostDB
+	}
+	return math.Pow(10, db/20)
+}
+
+// measure runs samples through the K-weighting filter and accumulates 100ms
+// hops into overlapping 400ms gating blocks
+func (n *loudnessNormalizer) measure(samples []int16) {
+	for _, s := range samples {
+		y := n.highpass.process(n.shelf.process(float64(s) / 32768))
+		n.hopSum += y * y
+		n.hopN++
+		if n.hopN < n.hopSamples {
+			continue
+		}
+
+		copy(n.hops[:], n.hops[1:])
+		n.hops[len(n.hops)-1] = n.hopSum / float64(n.hopN)
+		n.hopSum, n.hopN = 0, 0
+		if n.hopCount < len(n.hops) {
+			n.hopCount++
+		}
+		if n.hopCount < len(n.hops) {
+			continue
+		}
+
+		var block float64
+		for _, h := range n.hops {
+			block += h
+		}
+		block /= float64(len(n.hops))
+		if -0.691+10*math.Log10(block) > lufsAbsoluteGate {
+			n.gatedSum += block
+			n.gatedBlocks++
+		}
+	}
+}
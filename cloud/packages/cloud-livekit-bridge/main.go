@@ -7,9 +7,11 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/logger"
 	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/envconfig"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -17,6 +19,11 @@ import (
 )
 
 func main() {
+	// CONFIG_FILE supplies defaults for every setting, the logger's included
+	if err := envconfig.Load(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	// Initialize Better Stack logger
 	bsLogger := logger.NewFromEnv()
 	defer bsLogger.Close()
@@ -27,7 +34,11 @@ func main() {
 	})
 
 	// Load configuration
-	config := loadConfig()
+	config, err := loadConfig()
+	if err != nil {
+		bsLogger.LogError("Invalid configuration", err, nil)
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 	log.Printf("Configuration loaded: Port=%s, LiveKitURL=%s", config.Port, config.LiveKitURL)
 	bsLogger.LogInfo("Configuration loaded", map[string]interface{}{
 		"port":        config.Port,
@@ -49,14 +60,28 @@ func main() {
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 	healthServer.SetServingStatus("mentra.livekit.bridge.LiveKitBridge", grpc_health_v1.HealthCheckResponse_SERVING)
 
+	// Mirror drop-rate degradation into the standard health service
+	if config.HealthMaxDropRate > 0 {
+		go func() {
+			ticker := time.NewTicker(5 * time.Second)
+			defer ticker.Stop()
+			for range ticker.C {
+				serving := grpc_health_v1.HealthCheckResponse_SERVING
+				if bridgeService.Degraded() {
+					serving = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+				}
+				healthServer.SetServingStatus("mentra.livekit.bridge.LiveKitBridge", serving)
+			}
+		}()
+	}
+
 	// Register reflection service (for debugging with grpcurl)
 	reflection.Register(grpcServer)
 
 	// Determine if we should use Unix socket or TCP
 	var lis net.Listener
-	var err error
 
-	socketPath := os.Getenv("LIVEKIT_GRPC_SOCKET")
+	socketPath := envconfig.Lookup("LIVEKIT_GRPC_SOCKET")
 	if socketPath != "" {
 		// Use Unix domain socket
 		// Remove existing socket file if it exists
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+)
+
+// MPEG-TS demuxing for HLS segments. Only what audio-only (or audio plus
+// video) HLS needs: the PAT and PMT to find the first AAC or MP3 stream,
+// then that stream's PES payloads in order. PSI sections are assumed to fit
+// in one packet, as segmenters write them.
+
+const tsPacketSize = 188
+
+// PMT stream types carrying audio we can decode
+const (
+	tsStreamMPEG1Audio = 0x03
+	tsStreamMPEG2Audio = 0x04
+	tsStreamADTS       = 0x0F
+)
+
+// isMPEGTS reports whether data starts like a transport stream
+func isMPEGTS(data []byte) bool {
+	if len(data) < tsPacketSize || data[0] != 0x47 {
+		return false
+	}
+	return len(data) < 2*tsPacketSize || data[tsPacketSize] == 0x47
+}
+
+// demuxTSAudio returns the payload of the first AAC or MP3 stream in data
+func demuxTSAudio(data []byte) ([]byte, error) {
+	pmtPID, audioPID := -1, -1
+	var out []byte
+	for off := 0; off+tsPacketSize <= len(data); off += tsPacketSize {
+		pkt := data[off : off+tsPacketSize]
+		if pkt[0] != 0x47 {
+			return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("lost MPEG-TS sync at byte %d", off))
+		}
+		pid := int(pkt[1]&0x1F)<<8 | int(pkt[2])
+		start := pkt[1]&0x40 != 0
+		payload := tsPayload(pkt)
+		if payload == nil {
+			continue
+		}
+
+		switch {
+		case pid == 0 && start && pmtPID < 0:
+			pmtPID = patProgramMap(payload)
+		case pid == pmtPID && start && audioPID < 0:
+			var err error
+			if audioPID, err = pmtAudioPID(payload); err != nil {
+				return nil, err
+			}
+		case pid == audioPID && audioPID >= 0:
+			if start {
+				// Skip the PES header: 6 fixed bytes, 3 of flags and length,
+				// then the optional fields
+				if len(payload) < 9 || payload[0] != 0 || payload[1] != 0 || payload[2] != 1 {
+					return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid PES header"))
+				}
+				hdrLen := 9 + int(payload[8])
+				if hdrLen > len(payload) {
+					return nil, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid PES header"))
+				}
+				payload = payload[hdrLen:]
+			}
+			out = append(out, payload...)
+		}
+	}
+	if audioPID < 0 {
+		return nil, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("MPEG-TS segment has no AAC or MP3 stream"))
+	}
+	return out, nil
+}
+
+// tsPayload returns the packet's payload after any adaptation field, or nil
+func tsPayload(pkt []byte) []byte {
+	control := pkt[3] >> 4 & 0x3
+	if control&0x1 == 0 {
+		return nil
+	}
+	off := 4
+	if control&0x2 != 0 {
+		off += 1 + int(pkt[4])
+	}
+	if off >= len(pkt) {
+		return nil
+	}
+	return pkt[off:]
+}
+
+// psiSection returns the section body (after the 8-byte long-form header,
+// before the CRC) of a PSI payload that starts a section
+func psiSection(payload []byte) []byte {
+	if len(payload) < 1 {
+		return nil
+	}
+	payload = payload[1:] // pointer field
+	if len(payload) < 3 {
+		return nil
+	}
+	length := int(payload[1]&0x0F)<<8 | int(payload[2])
+	if length < 9 || 3+length > len(payload) {
+		return nil
+	}
+	return payload[8 : 3+length-4]
+}
+
+// patProgramMap returns the PMT PID of the first program in a PAT, or -1
+func patProgramMap(payload []byte) int {
+	section := psiSection(payload)
+	for i := 0; i+4 <= len(section); i += 4 {
+		program := int(section[i])<<8 | int(section[i+1])
+		if program != 0 { // 0 is the network PID
+			return int(section[i+2]&0x1F)<<8 | int(section[i+3])
+		}
+	}
+	return -1
+}
+
+// pmtAudioPID returns the PID of the first decodable audio stream in a PMT
+func pmtAudioPID(payload []byte) (int, error) {
+	section := psiSection(payload)
+	if len(section) < 4 {
+		return -1, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid MPEG-TS PMT"))
+	}
+	infoLen := int(section[2]&0x0F)<<8 | int(section[3])
+	streams := section[min(4+infoLen, len(section)):]
+	for i := 0; i+5 <= len(streams); {
+		streamType := streams[i]
+		pid := int(streams[i+1]&0x1F)<<8 | int(streams[i+2])
+		switch streamType {
+		case tsStreamADTS, tsStreamMPEG1Audio, tsStreamMPEG2Audio:
+			return pid, nil
+		}
+		i += 5 + (int(streams[i+3]&0x0F)<<8 | int(streams[i+4]))
+	}
+	return -1, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("MPEG-TS segment has no AAC or MP3 stream"))
+}
@@ -3,68 +3,199 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
 	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/builtin"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
 	mp3 "github.com/hajimehoshi/go-mp3"
 )
 
+// errEmptyAudio is returned when playback finishes without producing any
+// samples (empty body or zero-length data chunk)
+var errEmptyAudio = errors.New("empty_audio")
+
+// playbackLead is how far playback may run ahead of real time. It bounds
+// how much already-queued audio plays after StopAudio or PauseAudio.
+const playbackLead = 200 * time.Millisecond
+
 // playAudioFile handles downloading and playing audio files
 func (s *LiveKitBridgeService) playAudioFile(
 	req *pb.PlayAudioRequest,
 	session *RoomSession,
 	stream pb.LiveKitBridge_PlayAudioServer,
 	trackName string,
-) (int64, error) {
+) (duration int64, err error) {
 	// Create cancellable context for playback
-	ctx, cancel := context.WithCancel(stream.Context())
-	defer cancel()
+	ctx, cancel := context.WithCancelCause(stream.Context())
+	defer cancel(nil)
+
+	// Store cancel function and pacer in session for StopAudio and
+	// PauseAudio, and register with the session so Close waits for us
+	// before closing tracks
+	pacer, ok := session.beginPlayback(cancel, trackName)
+	if !ok {
+		return 0, playerr.Fail(playerr.TrackUnavailable, fmt.Errorf("session is closing"))
+	}
+	defer session.endPlayback(pacer)
+
+	// When stopped, fade out before endPlayback lets StopAudio close the
+	// track. A replaced playback has been faded out by its successor.
+	defer func() {
+		if errors.Is(context.Cause(ctx), errPlaybackReplaced) {
+			err = errPlaybackReplaced
+			return
+		}
+		if ctx.Err() != nil {
+			if err := session.fadeOut(trackName, s.config.StopFadeMs); err != nil {
+				log.Printf("Failed to write stop fade-out: %v", err)
+			}
+		}
+	}()
 
-	// Store cancel function in session for StopAudio
-	session.mu.Lock()
-	session.playbackCancel = cancel
-	session.mu.Unlock()
+	if req.StartOffsetMs < 0 {
+		return 0, playerr.Fail(playerr.InvalidRequest, fmt.Errorf("start_offset_ms must not be negative"))
+	}
+
+	// Report the position while playing; decoders that learn the total
+	// length report it through ctx
+	if s.config.PlaybackProgressInterval > 0 {
+		progress := &playProgress{offset: time.Duration(req.StartOffsetMs) * time.Millisecond, pacer: pacer}
+		ctx = withProgress(ctx, progress)
+		defer reportProgress(stream, req.RequestId, progress, s.config.PlaybackProgressInterval)()
+	}
+
+	// Embedded diagnostic assets play without touching the network
+	if strings.HasPrefix(req.AudioUrl, builtin.Scheme) {
+		f, err := builtin.Open(req.AudioUrl)
+		if err != nil {
+			return 0, playerr.Fail(playerr.InvalidURL, err)
+		}
+		defer f.Close()
+		log.Printf("Playing audio: url=%s (builtin)", req.AudioUrl)
+		return s.playWAV(ctx, f, req, session, trackName, pacer)
+	}
 
 	// Fetch audio file
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.AudioUrl, nil)
 	if err != nil {
-		return 0, fmt.Errorf("invalid URL: %w", err)
+		return 0, playerr.Fail(playerr.InvalidURL, fmt.Errorf("invalid URL: %w", err))
+	}
+
+	// A cached copy is opened first so eviction can't pull it away, then
+	// played without a download while fresh, or once the server answers 304
+	var cached *os.File
+	var entry cacheEntry
+	if s.cache != nil {
+		if e, ok := s.cache.get(req.AudioUrl); ok {
+			if f, err := s.cache.open(e); err == nil {
+				defer f.Close()
+				if e.fresh(time.Now()) {
+					log.Printf("Playing audio: url=%s (cached)", req.AudioUrl)
+					return s.playBody(ctx, bufio.NewReader(f), e.contentType, httpReq.URL, req, session, trackName, pacer)
+				}
+				cached, entry = f, e
+				e.setConditional(httpReq)
+			}
+		}
 	}
 
 	resp, err := http.DefaultClient.Do(httpReq)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch audio: %w", err)
+		return 0, playerr.Fail(playerr.FetchFailed, fmt.Errorf("failed to fetch audio: %w", err))
+	}
+	// A download that drops mid-file is resumed with a Range request
+	download := newResumableBody(ctx, resp, s.config.PlaybackResumeAttempts)
+	defer download.Close()
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		s.cache.revalidated(entry, resp.Header)
+		log.Printf("Playing audio: url=%s (cached, revalidated)", req.AudioUrl)
+		return s.playBody(ctx, bufio.NewReader(cached), entry.contentType, httpReq.URL, req, session, trackName, pacer)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return 0, fmt.Errorf("HTTP error: %d %s", resp.StatusCode, resp.Status)
+		return 0, playerr.Fail(playerr.HTTPStatus, fmt.Errorf("HTTP error: %d %s", resp.StatusCode, resp.Status))
 	}
 
+	contentType := resp.Header.Get("Content-Type")
+	log.Printf("Playing audio: url=%s, contentType=%s", req.AudioUrl, contentType)
+
+	// Keep a copy of what plays through, for the next PlayAudio of this URL
+	var src io.Reader = download
+	if s.cache != nil && !isHLS(strings.ToLower(contentType), strings.ToLower(req.AudioUrl)) {
+		if fill := s.cache.fill(req.AudioUrl, resp, download); fill != nil {
+			defer fill.Close()
+			src = fill
+		}
+	}
+
+	return s.playBody(ctx, bufio.NewReader(src), contentType, resp.Request.URL, req, session, trackName, pacer)
+}
+
+// playBody plays a downloaded or cached file, picking the decoder by
+// content type or URL extension. base resolves HLS playlist entries.
+func (s *LiveKitBridgeService) playBody(
+	ctx context.Context,
+	body *bufio.Reader,
+	contentType string,
+	base *url.URL,
+	req *pb.PlayAudioRequest,
+	session *RoomSession,
+	trackName string,
+	pacer *audio.Pacer,
+) (int64, error) {
 	// Detect content type
-	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
+	contentType = strings.ToLower(contentType)
 	url := strings.ToLower(req.AudioUrl)
 
-	log.Printf("Playing audio: url=%s, contentType=%s", req.AudioUrl, contentType)
+	// A 200 with no body would otherwise surface as a decoder error
+	if _, err := body.Peek(1); err == io.EOF {
+		return 0, errEmptyAudio
+	}
 
 	// Route to appropriate decoder
-	if strings.Contains(contentType, "audio/mpeg") || strings.HasSuffix(url, ".mp3") {
-		return s.playMP3(ctx, resp.Body, req, session, trackName)
+	if isHLS(contentType, url) {
+		return s.playHLS(ctx, base, body, req, session, trackName, pacer)
+	} else if strings.Contains(contentType, "audio/mpeg") || strings.HasSuffix(url, ".mp3") {
+		return s.playMP3(ctx, body, req, session, trackName, pacer)
+	} else if strings.Contains(contentType, "audio/aac") ||
+		strings.Contains(contentType, "audio/x-aac") ||
+		strings.HasSuffix(url, ".aac") {
+		src, err := newADTSStream(body)
+		if err != nil {
+			return 0, err
+		}
+		return s.playAAC(ctx, src, req, session, trackName, pacer)
+	} else if strings.Contains(contentType, "audio/mp4") ||
+		strings.Contains(contentType, "audio/x-m4a") ||
+		strings.Contains(contentType, "audio/m4a") ||
+		strings.HasSuffix(url, ".m4a") {
+		src, err := newMP4Stream(body)
+		if err != nil {
+			return 0, err
+		}
+		defer src.Close()
+		return s.playAAC(ctx, src, req, session, trackName, pacer)
 	} else if strings.Contains(contentType, "audio/wav") ||
 		strings.Contains(contentType, "audio/x-wav") ||
 		strings.Contains(contentType, "audio/wave") ||
 		strings.HasSuffix(url, ".wav") {
-		return s.playWAV(ctx, resp.Body, req, session, trackName)
+		return s.playWAV(ctx, body, req, session, trackName, pacer)
 	}
 
-	return 0, fmt.Errorf("unsupported audio format: %s", contentType)
+	return 0, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("unsupported audio format: %s", contentType))
 }
 
 // playMP3 decodes and plays MP3 audio
@@ -74,20 +205,35 @@ func (s *LiveKitBridgeService) playMP3(
 	req *pb.PlayAudioRequest,
 	session *RoomSession,
 	trackName string,
+	pacer *audio.Pacer,
 ) (int64, error) {
 	// Create MP3 decoder
-	dec, err := mp3.NewDecoder(r)
+	dec, err := newMP3Decoder(ctx, r, s.config.MP3InitTimeout)
 	if err != nil {
-		return 0, fmt.Errorf("MP3 decode error: %w", err)
+		if errors.Is(err, errMP3InitTimeout) {
+			return 0, playerr.Fail(playerr.DecodeTimeout, fmt.Errorf("%w: no valid frame within %v", err, s.config.MP3InitTimeout))
+		}
+		return 0, playerr.Fail(playerr.DecodeFailed, fmt.Errorf("MP3 decode error: %w", err))
 	}
 
 	srcSR := dec.SampleRate()
 	if srcSR <= 0 {
-		return 0, fmt.Errorf("invalid MP3 sample rate")
+		return 0, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid MP3 sample rate"))
 	}
 
-	const dstSR = 16000
-	resampler := &resampleState{step: float64(srcSR) / float64(dstSR)}
+	// go-mp3 always decodes to 16-bit stereo, so the offset is found by
+	// decoding up to it
+	if err := skipToOffset(dec, req.StartOffsetMs*int64(srcSR)/1000*4, req.StartOffsetMs); err != nil {
+		return 0, err
+	}
+
+	const dstSR = trackSampleRate
+	resampler, err := resample.New(srcSR, dstSR, s.config.ResampleQuality)
+	if err != nil {
+		return 0, playerr.Fail(playerr.MalformedAudio, err)
+	}
+	normalizer := newLoudnessNormalizer(req.TargetLufs, dstSR)
+	volume := session.playbackVolume(trackName, req.Volume)
 
 	buf := make([]byte, 4096)
 	var totalSamples int64
@@ -104,7 +250,7 @@ func (s *LiveKitBridgeService) playMP3(
 		n, err := dec.Read(buf)
 		if n > 0 {
 			// Convert bytes to int16 samples
-			samples := bytesToInt16(buf[:n])
+			samples := audio.BytesToInt16(buf[:n])
 
 			// Downmix stereo to mono (MP3 is typically stereo)
 			if len(samples) >= 2 {
@@ -117,16 +263,19 @@ func (s *LiveKitBridgeService) playMP3(
 			}
 
 			// Resample to 16kHz
-			resampled := resampler.push(samples)
+			resampled := normalizer.process(resampler.Process(samples))
 			if len(resampled) > 0 {
 				// Apply volume
-				if req.Volume > 0 && req.Volume != 1.0 {
-					applyGain(resampled, float64(req.Volume))
+				if volume != 1.0 {
+					audio.ApplyGain(resampled, volume)
 				}
 
-				// Write to LiveKit in 10ms chunks
-				if err := session.writeAudioToTrack(int16ToBytes(resampled), trackName); err != nil {
-					return 0, fmt.Errorf("failed to write audio: %w", err)
+				// Write to LiveKit in 10ms chunks, held to real time
+				if err := pacer.Wait(ctx, len(resampled)); err != nil {
+					return 0, err
+				}
+				if err := session.writePlayback(pacer, audio.Int16ToBytes(resampled), trackName); err != nil {
+					return 0, playerr.Fail(playerr.WriteFailed, fmt.Errorf("failed to write audio: %w", err))
 				}
 
 				totalSamples += int64(len(resampled))
@@ -135,116 +284,230 @@ func (s *LiveKitBridgeService) playMP3(
 
 		if err != nil {
 			if !errors.Is(err, io.EOF) {
-				return 0, fmt.Errorf("MP3 read error: %w", err)
+				return 0, playerr.Fail(playerr.ReadFailed, fmt.Errorf("MP3 read error: %w", err))
 			}
 			break
 		}
 	}
 
-	duration := time.Since(startTime).Milliseconds()
+	// The resampler holds back the last few ms for look-ahead
+	tail := append(normalizer.process(resampler.Flush()), normalizer.flush()...)
+	if len(tail) > 0 {
+		audio.ApplyGain(tail, volume)
+		if err := pacer.Wait(ctx, len(tail)); err != nil {
+			return 0, err
+		}
+		if err := session.writePlayback(pacer, audio.Int16ToBytes(tail), trackName); err != nil {
+			return 0, playerr.Fail(playerr.WriteFailed, fmt.Errorf("failed to write audio: %w", err))
+		}
+		totalSamples += int64(len(tail))
+	}
+
+	duration := (time.Since(startTime) - pacer.PausedFor()).Milliseconds()
 	log.Printf("MP3 playback complete: samples=%d, duration=%dms", totalSamples, duration)
 
+	if totalSamples == 0 {
+		return duration, errEmptyAudio
+	}
+
 	return duration, nil
 }
 
-// playWAV decodes and plays WAV audio
-func (s *LiveKitBridgeService) playWAV(
+// playAAC decodes and plays AAC from an ADTS or MP4 stream
+func (s *LiveKitBridgeService) playAAC(
 	ctx context.Context,
-	r io.Reader,
+	src *aacStream,
 	req *pb.PlayAudioRequest,
 	session *RoomSession,
 	trackName string,
+	pacer *audio.Pacer,
 ) (int64, error) {
-	br := bufio.NewReader(r)
-
-	// Parse RIFF header
-	header := make([]byte, 12)
-	if _, err := io.ReadFull(br, header); err != nil {
-		return 0, fmt.Errorf("failed to read WAV header: %w", err)
+	// The first frame settles the sample rate
+	pcm, channels, err := src.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, errEmptyAudio
 	}
+	if err != nil {
+		return 0, err
+	}
+	srcSR := src.SampleRate()
+	if srcSR <= 0 {
+		return 0, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid AAC sample rate"))
+	}
+	setPlayDuration(ctx, src.duration)
 
-	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
-		return 0, fmt.Errorf("not a valid WAV file")
+	const dstSR = trackSampleRate
+	resampler, err := resample.New(srcSR, dstSR, s.config.ResampleQuality)
+	if err != nil {
+		return 0, playerr.Fail(playerr.MalformedAudio, err)
 	}
+	normalizer := newLoudnessNormalizer(req.TargetLufs, dstSR)
+	volume := session.playbackVolume(trackName, req.Volume)
 
-	var numChannels uint16
-	var sampleRate uint32
-	var bitsPerSample uint16
-	var dataBytes uint32
+	// Decoded audio before start_offset_ms is dropped
+	skip := req.StartOffsetMs * int64(srcSR) / 1000
 
-	haveFmt := false
-	haveData := false
+	var totalSamples int64
+	startTime := time.Now()
 
-	// Read chunks until we find fmt and data
 	for {
-		hdr := make([]byte, 8)
-		if _, err := io.ReadFull(br, hdr); err != nil {
-			return 0, fmt.Errorf("failed to read chunk header: %w", err)
+		// Check for cancellation
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
 		}
 
-		chunkID := string(hdr[0:4])
-		size := binary.LittleEndian.Uint32(hdr[4:8])
+		mono := audio.Downmix[float32](pcm, channels, nil)
+		if skip > 0 {
+			n := min(skip, int64(len(mono)))
+			mono, skip = mono[n:], skip-n
+		}
 
-		if chunkID == "fmt " {
-			buf := make([]byte, size)
-			if _, err := io.ReadFull(br, buf); err != nil {
-				return 0, fmt.Errorf("failed to read fmt chunk: %w", err)
+		resampled := normalizer.process(resampler.Process(mono))
+		if len(resampled) > 0 {
+			if volume != 1.0 {
+				audio.ApplyGain(resampled, volume)
 			}
 
-			// Consume padding byte if odd size
-			if size%2 == 1 {
-				br.ReadByte()
+			// Write to LiveKit, held to real time
+			if err := pacer.Wait(ctx, len(resampled)); err != nil {
+				return 0, err
 			}
-
-			if size < 16 {
-				return 0, fmt.Errorf("fmt chunk too short")
+			if err := session.writePlayback(pacer, audio.Int16ToBytes(resampled), trackName); err != nil {
+				return 0, playerr.Fail(playerr.WriteFailed, fmt.Errorf("failed to write audio: %w", err))
 			}
 
-			audioFormat := binary.LittleEndian.Uint16(buf[0:2])
-			numChannels = binary.LittleEndian.Uint16(buf[2:4])
-			sampleRate = binary.LittleEndian.Uint32(buf[4:8])
-			bitsPerSample = binary.LittleEndian.Uint16(buf[14:16])
+			totalSamples += int64(len(resampled))
+		}
 
-			if audioFormat != 1 {
-				return 0, fmt.Errorf("only PCM WAV supported")
-			}
-			if bitsPerSample != 16 {
-				return 0, fmt.Errorf("only 16-bit WAV supported")
-			}
-			if numChannels != 1 && numChannels != 2 {
-				return 0, fmt.Errorf("only mono/stereo WAV supported")
-			}
+		pcm, channels, err = src.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
 
-			haveFmt = true
+	if skip > 0 {
+		return 0, playerr.Fail(playerr.InvalidRequest, fmt.Errorf("start_offset_ms %d is past the end of the audio", req.StartOffsetMs))
+	}
 
-		} else if chunkID == "data" {
-			dataBytes = size
-			haveData = true
-			break
-		} else {
-			// Skip unknown chunk
-			if _, err := io.CopyN(io.Discard, br, int64(size)); err != nil {
-				return 0, fmt.Errorf("failed to skip chunk: %w", err)
-			}
-			if size%2 == 1 {
-				br.ReadByte()
-			}
+	// The resampler holds back the last few ms for look-ahead
+	tail := append(normalizer.process(resampler.Flush()), normalizer.flush()...)
+	if len(tail) > 0 {
+		audio.ApplyGain(tail, volume)
+		if err := pacer.Wait(ctx, len(tail)); err != nil {
+			return 0, err
+		}
+		if err := session.writePlayback(pacer, audio.Int16ToBytes(tail), trackName); err != nil {
+			return 0, playerr.Fail(playerr.WriteFailed, fmt.Errorf("failed to write audio: %w", err))
 		}
+		totalSamples += int64(len(tail))
 	}
 
-	if !haveFmt || !haveData {
-		return 0, fmt.Errorf("missing fmt or data chunk")
+	duration := (time.Since(startTime) - pacer.PausedFor()).Milliseconds()
+	log.Printf("AAC playback complete: samples=%d, duration=%dms, concealedFrames=%d", totalSamples, duration, src.concealed)
+
+	if totalSamples == 0 {
+		return duration, errEmptyAudio
 	}
 
-	const dstSR = 16000
-	resampler := &resampleState{step: float64(sampleRate) / float64(dstSR)}
+	return duration, nil
+}
+
+// errMP3InitTimeout is returned when no valid MP3 frame arrives in time
+var errMP3InitTimeout = errors.New("mp3_init_timeout")
+
+// newMP3Decoder wraps mp3.NewDecoder, which blocks reading until it finds the
+// first valid frame, so a slow or garbage stream fails after timeout instead
+// of hanging. On timeout the decoder goroutine stays blocked on r until the
+// caller closes the underlying body.
+func newMP3Decoder(ctx context.Context, r io.Reader, timeout time.Duration) (*mp3.Decoder, error) {
+	type result struct {
+		dec *mp3.Decoder
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		dec, err := mp3.NewDecoder(r)
+		done <- result{dec, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return res.dec, res.err
+	case <-timer.C:
+		return nil, errMP3InitTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// playWAV decodes and plays WAV audio
+func (s *LiveKitBridgeService) playWAV(
+	ctx context.Context,
+	r io.Reader,
+	req *pb.PlayAudioRequest,
+	session *RoomSession,
+	trackName string,
+	pacer *audio.Pacer,
+) (int64, error) {
+	br := bufio.NewReader(r)
+
+	wav, err := audio.ReadWAVHeader(br)
+	if err != nil {
+		return 0, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid WAV header: %w", err))
+	}
+	if wav.FmtShortBy > 0 {
+		log.Printf("WAV fmt chunk is %d bytes short of its declared size; continuing", wav.FmtShortBy)
+	}
+	if wav.AudioFormat != 1 {
+		return 0, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("only PCM WAV supported"))
+	}
+	if wav.BitsPerSample != 16 {
+		return 0, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("only 16-bit WAV supported"))
+	}
+	if wav.Channels == 0 {
+		return 0, playerr.Fail(playerr.UnsupportedFormat, fmt.Errorf("WAV has no channels"))
+	}
+	if n := len(req.ChannelWeights); n > 0 && n != wav.Channels {
+		return 0, playerr.Fail(playerr.InvalidRequest, fmt.Errorf("channel_weights has %d entries, WAV has %d channels", n, wav.Channels))
+	}
+
+	const dstSR = trackSampleRate
+	resampler, err := resample.New(wav.SampleRate, dstSR, s.config.ResampleQuality)
+	if err != nil {
+		return 0, playerr.Fail(playerr.MalformedAudio, err)
+	}
+	normalizer := newLoudnessNormalizer(req.TargetLufs, dstSR)
+	volume := session.playbackVolume(trackName, req.Volume)
 
-	bytesPerFrame := int(bitsPerSample/8) * int(numChannels)
+	bytesPerFrame := wav.BlockAlign()
 	if bytesPerFrame <= 0 {
-		return 0, fmt.Errorf("invalid frame size")
+		return 0, playerr.Fail(playerr.MalformedAudio, fmt.Errorf("invalid frame size"))
+	}
+
+	// Chunked/streaming sources may not know the length up front and write a
+	// placeholder data size; those are read until EOF
+	readLeft := wav.DataLength()
+	if readLeft != math.MaxInt64 {
+		setPlayDuration(ctx, time.Duration(readLeft/int64(bytesPerFrame))*time.Second/time.Duration(wav.SampleRate))
 	}
 
-	readLeft := int64(dataBytes)
+	// PCM seeks to a byte offset: whole frames into the data chunk
+	if skip := req.StartOffsetMs * int64(wav.SampleRate) / 1000 * int64(bytesPerFrame); skip > 0 {
+		if skip >= readLeft {
+			return 0, playerr.Fail(playerr.InvalidRequest, fmt.Errorf("start_offset_ms %d is past the end of the audio", req.StartOffsetMs))
+		}
+		if err := skipToOffset(br, skip, req.StartOffsetMs); err != nil {
+			return 0, err
+		}
+		readLeft -= skip
+	}
 	buf := make([]byte, 4096-(4096%bytesPerFrame))
 	if len(buf) == 0 {
 		buf = make([]byte, bytesPerFrame)
@@ -268,7 +531,7 @@ func (s *LiveKitBridgeService) playWAV(
 
 		n, err := io.ReadFull(br, buf[:toRead])
 		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
-			return 0, fmt.Errorf("failed to read audio data: %w", err)
+			return 0, playerr.Fail(playerr.ReadFailed, fmt.Errorf("failed to read audio data: %w", err))
 		}
 		if n <= 0 {
 			break
@@ -278,113 +541,105 @@ func (s *LiveKitBridgeService) playWAV(
 		data := buf[:n]
 
 		// Convert to mono int16 samples
-		samples := bytesToInt16(data)
-		var mono []int16
-
-		if numChannels == 1 {
-			mono = samples
-		} else {
-			// Downmix stereo to mono
-			mono = make([]int16, len(samples)/2)
-			for i := 0; i+1 < len(samples); i += 2 {
-				v := int32(samples[i]) + int32(samples[i+1])
-				mono[i/2] = int16(v / 2)
-			}
-		}
+		mono := audio.Downmix(audio.BytesToInt16(data), wav.Channels, req.ChannelWeights)
 
 		// Resample if needed
 		var output []int16
-		if int(sampleRate) != dstSR {
-			output = resampler.push(mono)
+		if wav.SampleRate != dstSR {
+			output = resampler.Process(mono)
 		} else {
 			output = mono
 		}
+		output = normalizer.process(output)
 
 		if len(output) > 0 {
 			// Apply volume
-			if req.Volume > 0 && req.Volume != 1.0 {
-				applyGain(output, float64(req.Volume))
+			if volume != 1.0 {
+				audio.ApplyGain(output, volume)
 			}
 
-			// Write to LiveKit
-			if err := session.writeAudioToTrack(int16ToBytes(output), trackName); err != nil {
-				return 0, fmt.Errorf("failed to write audio: %w", err)
+			// Write to LiveKit, held to real time
+			if err := pacer.Wait(ctx, len(output)); err != nil {
+				return 0, err
+			}
+			if err := session.writePlayback(pacer, audio.Int16ToBytes(output), trackName); err != nil {
+				return 0, playerr.Fail(playerr.WriteFailed, fmt.Errorf("failed to write audio: %w", err))
 			}
 
 			totalSamples += int64(len(output))
 		}
 	}
 
-	duration := time.Since(startTime).Milliseconds()
-	log.Printf("WAV playback complete: samples=%d, duration=%dms", totalSamples, duration)
+	// The resampler holds back the last few ms for look-ahead
+	tail := append(normalizer.process(resampler.Flush()), normalizer.flush()...)
+	if len(tail) > 0 {
+		audio.ApplyGain(tail, volume)
+		if err := pacer.Wait(ctx, len(tail)); err != nil {
+			return 0, err
+		}
+		if err := session.writePlayback(pacer, audio.Int16ToBytes(tail), trackName); err != nil {
+			return 0, playerr.Fail(playerr.WriteFailed, fmt.Errorf("failed to write audio: %w", err))
+		}
+		totalSamples += int64(len(tail))
+	}
 
-	return duration, nil
-}
+	duration := (time.Since(startTime) - pacer.PausedFor()).Milliseconds()
+	log.Printf("WAV playback complete: samples=%d, duration=%dms", totalSamples, duration)
 
-// applyGain applies volume scaling to audio samples
-func applyGain(samples []int16, gain float64) {
-	if gain == 1.0 {
-		return
-	}
-	for i := range samples {
-		v := float64(samples[i]) * gain
-		if v > 32767 {
-			v = 32767
-		} else if v < -32768 {
-			v = -32768
-		}
-		samples[i] = int16(v)
+	if totalSamples == 0 {
+		return duration, errEmptyAudio
 	}
-}
 
-// resampleState holds state for audio resampling
-type resampleState struct {
-	buf  []int16
-	pos  float64
-	step float64
+	return duration, nil
 }
 
-// push adds samples to the resampler and returns resampled output
-func (r *resampleState) push(in []int16) []int16 {
-	r.buf = append(r.buf, in...)
-	if len(r.buf) < 2 {
+// skipToOffset discards the first n bytes of decoded audio for a
+// start_offset_ms of offsetMs
+func skipToOffset(r io.Reader, n int64, offsetMs int64) error {
+	if n <= 0 {
 		return nil
 	}
+	skipped, err := io.CopyN(io.Discard, r, n)
+	if skipped == n {
+		return nil
+	}
+	if err == nil || errors.Is(err, io.EOF) {
+		return playerr.Fail(playerr.InvalidRequest, fmt.Errorf("start_offset_ms %d is past the end of the audio", offsetMs))
+	}
+	return playerr.Fail(playerr.ReadFailed, fmt.Errorf("failed to skip to start offset: %w", err))
+}
 
-	var out []int16
-	for {
-		i := int(r.pos)
-		if i+1 >= len(r.buf) {
-			break
-		}
-
-		// Linear interpolation
-		frac := r.pos - float64(i)
-		s0 := float64(r.buf[i])
-		s1 := float64(r.buf[i+1])
-		v := s0 + (s1-s0)*frac
-
-		if v > 32767 {
-			v = 32767
-		} else if v < -32768 {
-			v = -32768
-		}
-
-		out = append(out, int16(v))
-		r.pos += r.step
+// playErrorFeedback writes a short error tone or a fade-out of the last
+// frame to the track after a failed playback, as configured
+func (s *LiveKitBridgeService) playErrorFeedback(session *RoomSession, trackName string) {
+	var err error
+	switch s.config.PlaybackErrorFeedback {
+	case "tone":
+		err = session.writeAudioToTrack(audio.Int16ToBytes(errorTone(s.config.ErrorToneHz, s.config.ErrorFeedbackMs)), trackName)
+	case "fade":
+		err = session.fadeOut(trackName, s.config.ErrorFeedbackMs)
 	}
+	if err != nil {
+		log.Printf("Failed to write playback error feedback: %v", err)
+	}
+}
 
-	// Keep unconsumed samples
-	drop := int(r.pos)
-	if drop > 0 {
-		if drop >= len(r.buf) {
-			r.buf = r.buf[:0]
-			r.pos = 0
-		} else {
-			r.buf = r.buf[drop:]
-			r.pos -= float64(drop)
+// errorTone generates a quiet 16kHz sine with 5ms ramps so the tone itself
+// doesn't click
+func errorTone(freqHz, ms int) []int16 {
+	const sampleRate = 16000
+	n := sampleRate * ms / 1000
+	ramp := sampleRate / 200
+	out := make([]int16, n)
+	for i := range out {
+		env := 1.0
+		if i < ramp {
+			env = float64(i) / float64(ramp)
+		} else if n-i < ramp {
+			env = float64(n-i) / float64(ramp)
 		}
+		v := math.Sin(2*math.Pi*float64(freqHz)*float64(i)/sampleRate) * env * 0.3
+		out[i] = int16(v * 32767)
 	}
-
 	return out
 }
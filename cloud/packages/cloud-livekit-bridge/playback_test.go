@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// monoWAV is a 16-bit mono WAV of n silent samples at rate
+func monoWAV(rate, n int) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+n*2))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(1), uint32(rate), uint32(rate * 2), uint16(2), uint16(16)} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(n*2))
+	b.Write(make([]byte, n*2))
+	return b.Bytes()
+}
+
+// TestPlayWAVFlushesResampler plays a 44.1kHz WAV and checks the resampled
+// output is as long as the input, tail included
+func TestPlayWAVFlushesResampler(t *testing.T) {
+	const srcSR, n = 44100, 4410 // 100ms
+	s := NewRoomSession("u", nil)
+	track := &recordTrack{}
+	s.room = &lksdk.Room{}
+	s.tracks["speaker"] = track
+	defer func() {
+		s.room = nil
+		s.Close()
+	}()
+	svc := &LiveKitBridgeService{config: &Config{ResampleQuality: resample.Medium}}
+
+	_, cancel := context.WithCancelCause(context.Background())
+	pacer, ok := s.beginPlayback(cancel, "speaker")
+	if !ok {
+		t.Fatal("beginPlayback refused")
+	}
+	defer s.endPlayback(pacer)
+	if _, err := svc.playWAV(context.Background(), bytes.NewReader(monoWAV(srcSR, n)), &pb.PlayAudioRequest{}, s, "speaker", pacer); err != nil {
+		t.Fatal(err)
+	}
+
+	track.mu.Lock()
+	got := len(track.samples)
+	track.mu.Unlock()
+	if want := n * trackSampleRate / srcSR; got != want {
+		t.Errorf("%d samples written, want %d", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// Progress events.
+//
+// While PlayAudio plays, a PROGRESS event goes out every
+// PlaybackProgressInterval with position_ms, how far into the audio the
+// listener is, and duration_ms once the decoder knows the total length: from
+// the WAV data size, the MP4 header or an HLS VOD playlist. Position counts
+// from the start of the audio, so start_offset_ms is included, and leaves
+// out what is still queued ahead of real time. Nothing is sent while the
+// position doesn't move, e.g. during the fetch or while paused.
+
+// playProgress is what a playback's PROGRESS events report
+type playProgress struct {
+	offset   time.Duration
+	pacer    *audio.Pacer
+	duration atomic.Int64 // ms; 0 until known
+}
+
+type progressKey struct{}
+
+// withProgress makes p the progress the decoders under ctx report to
+func withProgress(ctx context.Context, p *playProgress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// setPlayDuration records the total length of the audio, if the playback
+// under ctx reports progress
+func setPlayDuration(ctx context.Context, d time.Duration) {
+	if p, ok := ctx.Value(progressKey{}).(*playProgress); ok && d > 0 {
+		p.duration.Store(d.Milliseconds())
+	}
+}
+
+// reportProgress sends PROGRESS events every interval until the returned
+// stop is called. stop waits for a send in flight, since the stream can't
+// be sent on concurrently.
+func reportProgress(stream pb.LiveKitBridge_PlayAudioServer, requestID string, p *playProgress, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var last int64
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			played := p.pacer.Position()
+			if played == 0 {
+				continue
+			}
+			position := (p.offset + played).Milliseconds()
+			if position == last {
+				continue
+			}
+			last = position
+			stream.Send(&pb.PlayAudioEvent{
+				Type:       pb.PlayAudioEvent_PROGRESS,
+				RequestId:  requestID,
+				PositionMs: position,
+				DurationMs: p.duration.Load(),
+			})
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
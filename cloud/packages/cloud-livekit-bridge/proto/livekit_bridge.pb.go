@@ -21,6 +21,57 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Codec for the session's published tracks. G.711 is for SIP gateways
+// that don't take Opus; audio is downsampled to 8kHz for it.
+type JoinRoomRequest_Codec int32
+
+const (
+	JoinRoomRequest_OPUS JoinRoomRequest_Codec = 0
+	JoinRoomRequest_PCMU JoinRoomRequest_Codec = 1 // G.711 mu-law
+	JoinRoomRequest_PCMA JoinRoomRequest_Codec = 2 // G.711 A-law
+)
+
+// Enum value maps for JoinRoomRequest_Codec.
+var (
+	JoinRoomRequest_Codec_name = map[int32]string{
+		0: "OPUS",
+		1: "PCMU",
+		2: "PCMA",
+	}
+	JoinRoomRequest_Codec_value = map[string]int32{
+		"OPUS": 0,
+		"PCMU": 1,
+		"PCMA": 2,
+	}
+)
+
+func (x JoinRoomRequest_Codec) Enum() *JoinRoomRequest_Codec {
+	p := new(JoinRoomRequest_Codec)
+	*p = x
+	return p
+}
+
+func (x JoinRoomRequest_Codec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JoinRoomRequest_Codec) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_livekit_bridge_proto_enumTypes[0].Descriptor()
+}
+
+func (JoinRoomRequest_Codec) Type() protoreflect.EnumType {
+	return &file_proto_livekit_bridge_proto_enumTypes[0]
+}
+
+func (x JoinRoomRequest_Codec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JoinRoomRequest_Codec.Descriptor instead.
+func (JoinRoomRequest_Codec) EnumDescriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{1, 0}
+}
+
 // Event type
 type PlayAudioEvent_EventType int32
 
@@ -58,11 +109,11 @@ func (x PlayAudioEvent_EventType) String() string {
 }
 
 func (PlayAudioEvent_EventType) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_livekit_bridge_proto_enumTypes[0].Descriptor()
+	return file_proto_livekit_bridge_proto_enumTypes[1].Descriptor()
 }
 
 func (PlayAudioEvent_EventType) Type() protoreflect.EnumType {
-	return &file_proto_livekit_bridge_proto_enumTypes[0]
+	return &file_proto_livekit_bridge_proto_enumTypes[1]
 }
 
 func (x PlayAudioEvent_EventType) Number() protoreflect.EnumNumber {
@@ -74,6 +125,55 @@ func (PlayAudioEvent_EventType) EnumDescriptor() ([]byte, []int) {
 	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{6, 0}
 }
 
+type SubscribedTrackEvent_EventType int32
+
+const (
+	SubscribedTrackEvent_ADDED       SubscribedTrackEvent_EventType = 0 // Decoding started; audio frames follow
+	SubscribedTrackEvent_REMOVED     SubscribedTrackEvent_EventType = 1 // Track unpublished or unsubscribed
+	SubscribedTrackEvent_UNSUPPORTED SubscribedTrackEvent_EventType = 2 // Codec can't be decoded; no frames will follow
+)
+
+// Enum value maps for SubscribedTrackEvent_EventType.
+var (
+	SubscribedTrackEvent_EventType_name = map[int32]string{
+		0: "ADDED",
+		1: "REMOVED",
+		2: "UNSUPPORTED",
+	}
+	SubscribedTrackEvent_EventType_value = map[string]int32{
+		"ADDED":       0,
+		"REMOVED":     1,
+		"UNSUPPORTED": 2,
+	}
+)
+
+func (x SubscribedTrackEvent_EventType) Enum() *SubscribedTrackEvent_EventType {
+	p := new(SubscribedTrackEvent_EventType)
+	*p = x
+	return p
+}
+
+func (x SubscribedTrackEvent_EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SubscribedTrackEvent_EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_livekit_bridge_proto_enumTypes[2].Descriptor()
+}
+
+func (SubscribedTrackEvent_EventType) Type() protoreflect.EnumType {
+	return &file_proto_livekit_bridge_proto_enumTypes[2]
+}
+
+func (x SubscribedTrackEvent_EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SubscribedTrackEvent_EventType.Descriptor instead.
+func (SubscribedTrackEvent_EventType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{15, 0}
+}
+
 // Service status
 type HealthCheckResponse_ServingStatus int32
 
@@ -111,11 +211,11 @@ func (x HealthCheckResponse_ServingStatus) String() string {
 }
 
 func (HealthCheckResponse_ServingStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_livekit_bridge_proto_enumTypes[1].Descriptor()
+	return file_proto_livekit_bridge_proto_enumTypes[3].Descriptor()
 }
 
 func (HealthCheckResponse_ServingStatus) Type() protoreflect.EnumType {
-	return &file_proto_livekit_bridge_proto_enumTypes[1]
+	return &file_proto_livekit_bridge_proto_enumTypes[3]
 }
 
 func (x HealthCheckResponse_ServingStatus) Number() protoreflect.EnumNumber {
@@ -124,7 +224,7 @@ func (x HealthCheckResponse_ServingStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthCheckResponse_ServingStatus.Descriptor instead.
 func (HealthCheckResponse_ServingStatus) EnumDescriptor() ([]byte, []int) {
-	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{10, 0}
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{28, 0}
 }
 
 // Audio chunk (PCM16 mono)
@@ -149,7 +249,11 @@ type AudioChunk struct {
 	// 1: app_audio (app-specific audio)
 	// 2: tts (text-to-speech audio)
 	// >2: custom app tracks
-	TrackId       int32 `protobuf:"varint,6,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	TrackId int32 `protobuf:"varint,6,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	// Room connection status, set only on Go → TypeScript chunks that carry
+	// no audio: "reconnecting" when LiveKit dropped the room and it is being
+	// re-joined, then "reconnected" or "reconnect_failed"
+	Status        string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -226,6 +330,13 @@ func (x *AudioChunk) GetTrackId() int32 {
 	return 0
 }
 
+func (x *AudioChunk) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
 // Join LiveKit room request
 type JoinRoomRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -239,7 +350,8 @@ type JoinRoomRequest struct {
 	LivekitUrl string `protobuf:"bytes,4,opt,name=livekit_url,json=livekitUrl,proto3" json:"livekit_url,omitempty"`
 	// Optional: Identity to subscribe to (typically user_id for self-audio)
 	// If set, bridge will subscribe to this participant's DataChannel packets
-	TargetIdentity string `protobuf:"bytes,5,opt,name=target_identity,json=targetIdentity,proto3" json:"target_identity,omitempty"`
+	TargetIdentity string                `protobuf:"bytes,5,opt,name=target_identity,json=targetIdentity,proto3" json:"target_identity,omitempty"`
+	Codec          JoinRoomRequest_Codec `protobuf:"varint,6,opt,name=codec,proto3,enum=mentra.livekit.bridge.JoinRoomRequest_Codec" json:"codec,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -309,6 +421,13 @@ func (x *JoinRoomRequest) GetTargetIdentity() string {
 	return ""
 }
 
+func (x *JoinRoomRequest) GetCodec() JoinRoomRequest_Codec {
+	if x != nil {
+		return x.Codec
+	}
+	return JoinRoomRequest_OPUS
+}
+
 // Join room response
 type JoinRoomResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -501,23 +620,40 @@ func (x *LeaveRoomResponse) GetError() string {
 
 // Play audio from URL request
 //
-// Downloads audio file (MP3/WAV), decodes, resamples to 16kHz,
+// Downloads audio file (MP3/WAV/AAC), decodes, resamples to 16kHz,
 // and publishes to LiveKit room as audio track.
 type PlayAudioRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Unique request ID (for tracking events)
 	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	// URL to audio file (HTTP/HTTPS)
-	// Supports: MP3 (audio/mpeg), WAV (audio/wav, audio/x-wav)
+	// Supports: MP3 (audio/mpeg), WAV (audio/wav, audio/x-wav), AAC-LC as
+	// ADTS (audio/aac) or M4A (audio/mp4). M4A is buffered whole (up to 64MB)
+	// and must not be fragmented. HLS playlists (.m3u8) of AAC or MP3
+	// segments, packed or in MPEG-TS; live playlists play until StopAudio.
+	// builtin://chime plays an embedded diagnostic WAV without network access.
 	AudioUrl string `protobuf:"bytes,2,opt,name=audio_url,json=audioUrl,proto3" json:"audio_url,omitempty"`
-	// Volume level (0.0 = mute, 1.0 = full volume, >1.0 = boost)
+	// Volume level (0.0 = mute, 1.0 = full volume, >1.0 = boost).
+	// Unset (0) uses the track's SetTrackVolume default, if any.
 	Volume float32 `protobuf:"fixed32,3,opt,name=volume,proto3" json:"volume,omitempty"`
 	// Whether to stop other audio playback before starting
 	StopOther bool `protobuf:"varint,4,opt,name=stop_other,json=stopOther,proto3" json:"stop_other,omitempty"`
 	// User ID (for routing to correct room session)
 	UserId string `protobuf:"bytes,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	// Track ID (optional, defaults to 0 = "speaker")
-	TrackId       int32 `protobuf:"varint,6,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	TrackId int32 `protobuf:"varint,6,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	// Per-channel weights for downmixing multi-channel WAV to mono.
+	// Empty averages all channels; otherwise one entry per channel.
+	ChannelWeights []float32 `protobuf:"fixed32,7,rep,packed,name=channel_weights,json=channelWeights,proto3" json:"channel_weights,omitempty"`
+	// Normalize playback loudness to this integrated level in LUFS (e.g. -16).
+	// 0 disables normalization. Measured on the fly with a 400ms look-ahead.
+	TargetLufs float32 `protobuf:"fixed32,8,opt,name=target_lufs,json=targetLufs,proto3" json:"target_lufs,omitempty"`
+	// Start this many milliseconds into the audio, e.g. to resume a clip.
+	// WAV skips straight to the byte offset in the data chunk; MP3 and AAC
+	// are decoded from the start and the audio before the offset discarded;
+	// HLS skips whole segments first. Live HLS and an offset past the end
+	// fail with invalid_request.
+	StartOffsetMs int64 `protobuf:"varint,9,opt,name=start_offset_ms,json=startOffsetMs,proto3" json:"start_offset_ms,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -594,6 +730,27 @@ func (x *PlayAudioRequest) GetTrackId() int32 {
 	return 0
 }
 
+func (x *PlayAudioRequest) GetChannelWeights() []float32 {
+	if x != nil {
+		return x.ChannelWeights
+	}
+	return nil
+}
+
+func (x *PlayAudioRequest) GetTargetLufs() float32 {
+	if x != nil {
+		return x.TargetLufs
+	}
+	return 0
+}
+
+func (x *PlayAudioRequest) GetStartOffsetMs() int64 {
+	if x != nil {
+		return x.StartOffsetMs
+	}
+	return 0
+}
+
 // Play audio event (streaming response)
 //
 // Emitted during audio playback lifecycle.
@@ -602,13 +759,25 @@ type PlayAudioEvent struct {
 	Type  PlayAudioEvent_EventType `protobuf:"varint,1,opt,name=type,proto3,enum=mentra.livekit.bridge.PlayAudioEvent_EventType" json:"type,omitempty"`
 	// Request ID (matches PlayAudioRequest.request_id)
 	RequestId string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	// Total duration in milliseconds (if known)
+	// PROGRESS: total length of the audio in milliseconds, 0 if unknown
+	// (MP3, ADTS and live HLS). COMPLETED: how long playback took, less
+	// time spent paused.
 	DurationMs int64 `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
-	// Current playback position in milliseconds
+	// PROGRESS: how far into the audio the listener is, in milliseconds,
+	// counted from the start of the file (start_offset_ms included). Sent
+	// every PLAYBACK_PROGRESS_MS while the position moves.
 	PositionMs int64 `protobuf:"varint,4,opt,name=position_ms,json=positionMs,proto3" json:"position_ms,omitempty"`
 	// Error message (if type = FAILED)
 	Error string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
-	// Additional metadata
+	// Additional metadata. COMPLETED sets "outcome" to "played",
+	// "empty_audio" when the source produced no samples, or "replaced" when
+	// a newer PlayAudio on the same track took over. A played COMPLETED
+	// also carries "clipped_samples" and "clip_percent" (samples at or above
+	// CLIP_LEVEL). FAILED sets "code" to a stable reason (track_unavailable,
+	// invalid_url, fetch_failed, http_error, unsupported_format,
+	// malformed_audio, decode_timeout, decode_error, read_failed,
+	// invalid_request, write_failed, cancelled, internal) and "message" to
+	// a human-readable description.
 	Metadata      map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -821,29 +990,29 @@ func (x *StopAudioResponse) GetStoppedRequestId() string {
 	return ""
 }
 
-// Health check request
-type HealthCheckRequest struct {
+// Pause audio playback request
+type PauseAudioRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Optional service name to check (empty = check all)
-	Service       string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// User ID (for routing)
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckRequest) Reset() {
-	*x = HealthCheckRequest{}
+func (x *PauseAudioRequest) Reset() {
+	*x = PauseAudioRequest{}
 	mi := &file_proto_livekit_bridge_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckRequest) String() string {
+func (x *PauseAudioRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
+func (*PauseAudioRequest) ProtoMessage() {}
 
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+func (x *PauseAudioRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_livekit_bridge_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -855,48 +1024,42 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PauseAudioRequest.ProtoReflect.Descriptor instead.
+func (*PauseAudioRequest) Descriptor() ([]byte, []int) {
 	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *HealthCheckRequest) GetService() string {
+func (x *PauseAudioRequest) GetUserId() string {
 	if x != nil {
-		return x.Service
+		return x.UserId
 	}
 	return ""
 }
 
-// Health check response
-type HealthCheckResponse struct {
-	state  protoimpl.MessageState            `protogen:"open.v1"`
-	Status HealthCheckResponse_ServingStatus `protobuf:"varint,1,opt,name=status,proto3,enum=mentra.livekit.bridge.HealthCheckResponse_ServingStatus" json:"status,omitempty"`
-	// Total active sessions
-	ActiveSessions int32 `protobuf:"varint,2,opt,name=active_sessions,json=activeSessions,proto3" json:"active_sessions,omitempty"`
-	// Total active streams
-	ActiveStreams int32 `protobuf:"varint,3,opt,name=active_streams,json=activeStreams,proto3" json:"active_streams,omitempty"`
-	// Uptime in seconds
-	UptimeSeconds int64 `protobuf:"varint,4,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
-	// Additional diagnostics
-	Metadata      map[string]string `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+// Pause audio response
+type PauseAudioResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Set when nothing is playing or playback is already paused
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
+func (x *PauseAudioResponse) Reset() {
+	*x = PauseAudioResponse{}
 	mi := &file_proto_livekit_bridge_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckResponse) String() string {
+func (x *PauseAudioResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (*PauseAudioResponse) ProtoMessage() {}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+func (x *PauseAudioResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_livekit_bridge_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -908,76 +1071,96 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use PauseAudioResponse.ProtoReflect.Descriptor instead.
+func (*PauseAudioResponse) Descriptor() ([]byte, []int) {
 	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_ServingStatus {
+func (x *PauseAudioResponse) GetSuccess() bool {
 	if x != nil {
-		return x.Status
+		return x.Success
 	}
-	return HealthCheckResponse_UNKNOWN
+	return false
 }
 
-func (x *HealthCheckResponse) GetActiveSessions() int32 {
+func (x *PauseAudioResponse) GetError() string {
 	if x != nil {
-		return x.ActiveSessions
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-func (x *HealthCheckResponse) GetActiveStreams() int32 {
-	if x != nil {
-		return x.ActiveStreams
-	}
-	return 0
+// Resume audio playback request
+type ResumeAudioRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User ID (for routing)
+	UserId        string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) GetUptimeSeconds() int64 {
+func (x *ResumeAudioRequest) Reset() {
+	*x = ResumeAudioRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeAudioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeAudioRequest) ProtoMessage() {}
+
+func (x *ResumeAudioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[11]
 	if x != nil {
-		return x.UptimeSeconds
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *HealthCheckResponse) GetMetadata() map[string]string {
+// Deprecated: Use ResumeAudioRequest.ProtoReflect.Descriptor instead.
+func (*ResumeAudioRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ResumeAudioRequest) GetUserId() string {
 	if x != nil {
-		return x.Metadata
+		return x.UserId
 	}
-	return nil
+	return ""
 }
 
-// Statistics message (for future monitoring/debugging)
-type SessionStats struct {
-	state               protoimpl.MessageState `protogen:"open.v1"`
-	UserId              string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	AudioFramesSent     int64                  `protobuf:"varint,2,opt,name=audio_frames_sent,json=audioFramesSent,proto3" json:"audio_frames_sent,omitempty"`
-	AudioFramesReceived int64                  `protobuf:"varint,3,opt,name=audio_frames_received,json=audioFramesReceived,proto3" json:"audio_frames_received,omitempty"`
-	BytesSent           int64                  `protobuf:"varint,4,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
-	BytesReceived       int64                  `protobuf:"varint,5,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
-	SessionDurationMs   int64                  `protobuf:"varint,6,opt,name=session_duration_ms,json=sessionDurationMs,proto3" json:"session_duration_ms,omitempty"`
-	RoomName            string                 `protobuf:"bytes,7,opt,name=room_name,json=roomName,proto3" json:"room_name,omitempty"`
-	ParticipantCount    int32                  `protobuf:"varint,8,opt,name=participant_count,json=participantCount,proto3" json:"participant_count,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+// Resume audio response
+type ResumeAudioResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Set when nothing is playing or playback isn't paused
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SessionStats) Reset() {
-	*x = SessionStats{}
-	mi := &file_proto_livekit_bridge_proto_msgTypes[11]
+func (x *ResumeAudioResponse) Reset() {
+	*x = ResumeAudioResponse{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SessionStats) String() string {
+func (x *ResumeAudioResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SessionStats) ProtoMessage() {}
+func (*ResumeAudioResponse) ProtoMessage() {}
 
-func (x *SessionStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_livekit_bridge_proto_msgTypes[11]
+func (x *ResumeAudioResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -988,72 +1171,1219 @@ func (x *SessionStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SessionStats.ProtoReflect.Descriptor instead.
-func (*SessionStats) Descriptor() ([]byte, []int) {
-	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use ResumeAudioResponse.ProtoReflect.Descriptor instead.
+func (*ResumeAudioResponse) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *SessionStats) GetUserId() string {
+func (x *ResumeAudioResponse) GetSuccess() bool {
 	if x != nil {
-		return x.UserId
+		return x.Success
 	}
-	return ""
+	return false
 }
 
-func (x *SessionStats) GetAudioFramesSent() int64 {
+func (x *ResumeAudioResponse) GetError() string {
 	if x != nil {
-		return x.AudioFramesSent
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-func (x *SessionStats) GetAudioFramesReceived() int64 {
-	if x != nil {
-		return x.AudioFramesReceived
-	}
-	return 0
+// Subscribe audio request
+type SubscribeAudioRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User ID (for routing)
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Participant identities to receive (empty = everyone in the room)
+	Identities []string `protobuf:"bytes,2,rep,name=identities,proto3" json:"identities,omitempty"`
+	// Track SIDs to receive (empty = all of their audio tracks). SIDs come
+	// from the ADDED events on a stream without this filter. Close the
+	// stream to stop receiving the tracks.
+	TrackSids []string `protobuf:"bytes,3,rep,name=track_sids,json=trackSids,proto3" json:"track_sids,omitempty"`
+	// Decode without the jitter buffer, for local low-latency testing. Frames
+	// arrive as soon as packets do, but late or reordered packets are
+	// decoded as they come and network jitter is heard as glitches. Decoders
+	// are shared: a track skips the jitter buffer only if every open stream
+	// that wants it set this when its decoding started.
+	DisableJitterBuffer bool `protobuf:"varint,4,opt,name=disable_jitter_buffer,json=disableJitterBuffer,proto3" json:"disable_jitter_buffer,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
-func (x *SessionStats) GetBytesSent() int64 {
+func (x *SubscribeAudioRequest) Reset() {
+	*x = SubscribeAudioRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeAudioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeAudioRequest) ProtoMessage() {}
+
+func (x *SubscribeAudioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[13]
 	if x != nil {
-		return x.BytesSent
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *SessionStats) GetBytesReceived() int64 {
+// Deprecated: Use SubscribeAudioRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeAudioRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SubscribeAudioRequest) GetUserId() string {
 	if x != nil {
-		return x.BytesReceived
+		return x.UserId
 	}
-	return 0
+	return ""
 }
 
-func (x *SessionStats) GetSessionDurationMs() int64 {
+func (x *SubscribeAudioRequest) GetIdentities() []string {
 	if x != nil {
-		return x.SessionDurationMs
+		return x.Identities
 	}
-	return 0
+	return nil
 }
 
-func (x *SessionStats) GetRoomName() string {
+func (x *SubscribeAudioRequest) GetTrackSids() []string {
 	if x != nil {
-		return x.RoomName
+		return x.TrackSids
 	}
-	return ""
+	return nil
 }
 
-func (x *SessionStats) GetParticipantCount() int32 {
+func (x *SubscribeAudioRequest) GetDisableJitterBuffer() bool {
 	if x != nil {
-		return x.ParticipantCount
+		return x.DisableJitterBuffer
 	}
-	return 0
+	return false
 }
 
-var File_proto_livekit_bridge_proto protoreflect.FileDescriptor
+// One decoded frame of a remote participant's audio track, or an event
+// about the track
+type SubscribedAudioFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Raw PCM16 LE data (16-bit signed little-endian, mono)
+	PcmData []byte `protobuf:"bytes,1,opt,name=pcm_data,json=pcmData,proto3" json:"pcm_data,omitempty"`
+	// Sample rate in Hz (always 16000)
+	SampleRate int32 `protobuf:"varint,2,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	// Identity of the participant that published the track
+	ParticipantIdentity string `protobuf:"bytes,3,opt,name=participant_identity,json=participantIdentity,proto3" json:"participant_identity,omitempty"`
+	// LiveKit track SID, to tell apart several tracks from one participant
+	TrackSid string `protobuf:"bytes,4,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	// Timestamp in milliseconds since epoch when the frame was decoded
+	TimestampMs int64 `protobuf:"varint,5,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	// Set instead of pcm_data when a track starts or stops being forwarded
+	TrackEvent    *SubscribedTrackEvent `protobuf:"bytes,6,opt,name=track_event,json=trackEvent,proto3" json:"track_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_proto_livekit_bridge_proto_rawDesc = "" +
+func (x *SubscribedAudioFrame) Reset() {
+	*x = SubscribedAudioFrame{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribedAudioFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribedAudioFrame) ProtoMessage() {}
+
+func (x *SubscribedAudioFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribedAudioFrame.ProtoReflect.Descriptor instead.
+func (*SubscribedAudioFrame) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SubscribedAudioFrame) GetPcmData() []byte {
+	if x != nil {
+		return x.PcmData
+	}
+	return nil
+}
+
+func (x *SubscribedAudioFrame) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *SubscribedAudioFrame) GetParticipantIdentity() string {
+	if x != nil {
+		return x.ParticipantIdentity
+	}
+	return ""
+}
+
+func (x *SubscribedAudioFrame) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *SubscribedAudioFrame) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *SubscribedAudioFrame) GetTrackEvent() *SubscribedTrackEvent {
+	if x != nil {
+		return x.TrackEvent
+	}
+	return nil
+}
+
+// A remote track starting or stopping on a SubscribeAudio stream. Only
+// Opus tracks are decoded; others are reported as UNSUPPORTED and skipped.
+type SubscribedTrackEvent struct {
+	state protoimpl.MessageState         `protogen:"open.v1"`
+	Type  SubscribedTrackEvent_EventType `protobuf:"varint,1,opt,name=type,proto3,enum=mentra.livekit.bridge.SubscribedTrackEvent_EventType" json:"type,omitempty"`
+	// Codec MIME type of the remote track, e.g. "audio/opus" or "audio/PCMU"
+	Codec string `protobuf:"bytes,2,opt,name=codec,proto3" json:"codec,omitempty"`
+	// The track's native format before decoding to 16kHz mono: codec clock
+	// rate in Hz (48000 for Opus) and channel count
+	ClockRate     int32 `protobuf:"varint,3,opt,name=clock_rate,json=clockRate,proto3" json:"clock_rate,omitempty"`
+	Channels      int32 `protobuf:"varint,4,opt,name=channels,proto3" json:"channels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribedTrackEvent) Reset() {
+	*x = SubscribedTrackEvent{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribedTrackEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribedTrackEvent) ProtoMessage() {}
+
+func (x *SubscribedTrackEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribedTrackEvent.ProtoReflect.Descriptor instead.
+func (*SubscribedTrackEvent) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SubscribedTrackEvent) GetType() SubscribedTrackEvent_EventType {
+	if x != nil {
+		return x.Type
+	}
+	return SubscribedTrackEvent_ADDED
+}
+
+func (x *SubscribedTrackEvent) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *SubscribedTrackEvent) GetClockRate() int32 {
+	if x != nil {
+		return x.ClockRate
+	}
+	return 0
+}
+
+func (x *SubscribedTrackEvent) GetChannels() int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+// Stream audio levels request
+type StreamAudioLevelsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User ID (for routing)
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Participant identities to report (empty = everyone in the room)
+	Identities []string `protobuf:"bytes,2,rep,name=identities,proto3" json:"identities,omitempty"`
+	// Report interval in milliseconds (0 = 250, minimum 50)
+	IntervalMs int32 `protobuf:"varint,3,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	// RMS level in dBFS at which a participant counts as speaking (0 = -45)
+	SpeakingThresholdDb float32 `protobuf:"fixed32,4,opt,name=speaking_threshold_db,json=speakingThresholdDb,proto3" json:"speaking_threshold_db,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *StreamAudioLevelsRequest) Reset() {
+	*x = StreamAudioLevelsRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAudioLevelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAudioLevelsRequest) ProtoMessage() {}
+
+func (x *StreamAudioLevelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAudioLevelsRequest.ProtoReflect.Descriptor instead.
+func (*StreamAudioLevelsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StreamAudioLevelsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *StreamAudioLevelsRequest) GetIdentities() []string {
+	if x != nil {
+		return x.Identities
+	}
+	return nil
+}
+
+func (x *StreamAudioLevelsRequest) GetIntervalMs() int32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *StreamAudioLevelsRequest) GetSpeakingThresholdDb() float32 {
+	if x != nil {
+		return x.SpeakingThresholdDb
+	}
+	return 0
+}
+
+// Audio levels over the last interval
+type AudioLevelEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Participants heard in the interval, plus any that stopped sending while
+	// marked speaking (at -100 dBFS until speaking clears). Sorted by identity.
+	Levels []*ParticipantAudioLevel `protobuf:"bytes,1,rep,name=levels,proto3" json:"levels,omitempty"`
+	// Timestamp in milliseconds since epoch when the levels were measured
+	TimestampMs   int64 `protobuf:"varint,2,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AudioLevelEvent) Reset() {
+	*x = AudioLevelEvent{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioLevelEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioLevelEvent) ProtoMessage() {}
+
+func (x *AudioLevelEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioLevelEvent.ProtoReflect.Descriptor instead.
+func (*AudioLevelEvent) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AudioLevelEvent) GetLevels() []*ParticipantAudioLevel {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
+func (x *AudioLevelEvent) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+// One participant's level
+type ParticipantAudioLevel struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Identity string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	// RMS level in dBFS (-100 = silence)
+	LevelDb float32 `protobuf:"fixed32,2,opt,name=level_db,json=levelDb,proto3" json:"level_db,omitempty"`
+	// Speaking from the threshold until 500ms below it
+	Speaking      bool `protobuf:"varint,3,opt,name=speaking,proto3" json:"speaking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParticipantAudioLevel) Reset() {
+	*x = ParticipantAudioLevel{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParticipantAudioLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParticipantAudioLevel) ProtoMessage() {}
+
+func (x *ParticipantAudioLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParticipantAudioLevel.ProtoReflect.Descriptor instead.
+func (*ParticipantAudioLevel) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ParticipantAudioLevel) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *ParticipantAudioLevel) GetLevelDb() float32 {
+	if x != nil {
+		return x.LevelDb
+	}
+	return 0
+}
+
+func (x *ParticipantAudioLevel) GetSpeaking() bool {
+	if x != nil {
+		return x.Speaking
+	}
+	return false
+}
+
+// Set track volume request
+type SetTrackVolumeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User ID (for routing)
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Track ID (defaults to 0 = "speaker")
+	TrackId int32 `protobuf:"varint,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	// Default volume for the track (0.0 = mute, 1.0 = full volume, >1.0 = boost)
+	Volume        float32 `protobuf:"fixed32,3,opt,name=volume,proto3" json:"volume,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTrackVolumeRequest) Reset() {
+	*x = SetTrackVolumeRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTrackVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTrackVolumeRequest) ProtoMessage() {}
+
+func (x *SetTrackVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTrackVolumeRequest.ProtoReflect.Descriptor instead.
+func (*SetTrackVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetTrackVolumeRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetTrackVolumeRequest) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *SetTrackVolumeRequest) GetVolume() float32 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+// Set track volume response
+type SetTrackVolumeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetTrackVolumeResponse) Reset() {
+	*x = SetTrackVolumeResponse{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetTrackVolumeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetTrackVolumeResponse) ProtoMessage() {}
+
+func (x *SetTrackVolumeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetTrackVolumeResponse.ProtoReflect.Descriptor instead.
+func (*SetTrackVolumeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetTrackVolumeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SetTrackVolumeResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Send control request
+type SendControlRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User ID (for routing)
+	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Data packet topic, so receivers can tell control messages apart
+	Topic string `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	// Opaque payload
+	Payload []byte `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Deliver over the reliable (ordered, retransmitted) data channel
+	// instead of the lossy one
+	Reliable bool `protobuf:"varint,4,opt,name=reliable,proto3" json:"reliable,omitempty"`
+	// Participant identities to deliver to (empty = everyone in the room)
+	DestinationIdentities []string `protobuf:"bytes,5,rep,name=destination_identities,json=destinationIdentities,proto3" json:"destination_identities,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *SendControlRequest) Reset() {
+	*x = SendControlRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendControlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendControlRequest) ProtoMessage() {}
+
+func (x *SendControlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendControlRequest.ProtoReflect.Descriptor instead.
+func (*SendControlRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SendControlRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SendControlRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *SendControlRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SendControlRequest) GetReliable() bool {
+	if x != nil {
+		return x.Reliable
+	}
+	return false
+}
+
+func (x *SendControlRequest) GetDestinationIdentities() []string {
+	if x != nil {
+		return x.DestinationIdentities
+	}
+	return nil
+}
+
+// Send control response
+type SendControlResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendControlResponse) Reset() {
+	*x = SendControlResponse{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendControlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendControlResponse) ProtoMessage() {}
+
+func (x *SendControlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendControlResponse.ProtoReflect.Descriptor instead.
+func (*SendControlResponse) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SendControlResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SendControlResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Start room egress request
+type StartRoomEgressRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Room to record, on the bridge's LIVEKIT_URL server
+	RoomName string `protobuf:"bytes,1,opt,name=room_name,json=roomName,proto3" json:"room_name,omitempty"`
+	// Record only the room's mixed audio
+	AudioOnly bool `protobuf:"varint,3,opt,name=audio_only,json=audioOnly,proto3" json:"audio_only,omitempty"`
+	// Container: "mp4" or "ogg" (empty = chosen from the codecs)
+	FileType string `protobuf:"bytes,4,opt,name=file_type,json=fileType,proto3" json:"file_type,omitempty"`
+	// Path in the egress storage; egress templates such as {room_name} and
+	// {time} are expanded (empty = "{room_name}-{time}")
+	Filepath string `protobuf:"bytes,5,opt,name=filepath,proto3" json:"filepath,omitempty"`
+	// Compositing layout, e.g. "grid" or "speaker" (empty = egress default)
+	Layout        string `protobuf:"bytes,6,opt,name=layout,proto3" json:"layout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartRoomEgressRequest) Reset() {
+	*x = StartRoomEgressRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartRoomEgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRoomEgressRequest) ProtoMessage() {}
+
+func (x *StartRoomEgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRoomEgressRequest.ProtoReflect.Descriptor instead.
+func (*StartRoomEgressRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StartRoomEgressRequest) GetRoomName() string {
+	if x != nil {
+		return x.RoomName
+	}
+	return ""
+}
+
+func (x *StartRoomEgressRequest) GetAudioOnly() bool {
+	if x != nil {
+		return x.AudioOnly
+	}
+	return false
+}
+
+func (x *StartRoomEgressRequest) GetFileType() string {
+	if x != nil {
+		return x.FileType
+	}
+	return ""
+}
+
+func (x *StartRoomEgressRequest) GetFilepath() string {
+	if x != nil {
+		return x.Filepath
+	}
+	return ""
+}
+
+func (x *StartRoomEgressRequest) GetLayout() string {
+	if x != nil {
+		return x.Layout
+	}
+	return ""
+}
+
+// Start room egress response
+type StartRoomEgressResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// Identifies the recording to StopRoomEgress
+	EgressId string `protobuf:"bytes,3,opt,name=egress_id,json=egressId,proto3" json:"egress_id,omitempty"`
+	// LiveKit egress status, e.g. "EGRESS_STARTING"
+	Status        string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartRoomEgressResponse) Reset() {
+	*x = StartRoomEgressResponse{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartRoomEgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRoomEgressResponse) ProtoMessage() {}
+
+func (x *StartRoomEgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRoomEgressResponse.ProtoReflect.Descriptor instead.
+func (*StartRoomEgressResponse) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *StartRoomEgressResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StartRoomEgressResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StartRoomEgressResponse) GetEgressId() string {
+	if x != nil {
+		return x.EgressId
+	}
+	return ""
+}
+
+func (x *StartRoomEgressResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Stop room egress request
+type StopRoomEgressRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Egress ID returned by StartRoomEgress
+	EgressId      string `protobuf:"bytes,1,opt,name=egress_id,json=egressId,proto3" json:"egress_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRoomEgressRequest) Reset() {
+	*x = StopRoomEgressRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRoomEgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRoomEgressRequest) ProtoMessage() {}
+
+func (x *StopRoomEgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRoomEgressRequest.ProtoReflect.Descriptor instead.
+func (*StopRoomEgressRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *StopRoomEgressRequest) GetEgressId() string {
+	if x != nil {
+		return x.EgressId
+	}
+	return ""
+}
+
+// Stop room egress response
+type StopRoomEgressResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// LiveKit egress status, usually "EGRESS_ENDING": the file is finalized
+	// in the background
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	// Where the file was written, if LiveKit already reports it
+	FileLocation  string `protobuf:"bytes,4,opt,name=file_location,json=fileLocation,proto3" json:"file_location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRoomEgressResponse) Reset() {
+	*x = StopRoomEgressResponse{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRoomEgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRoomEgressResponse) ProtoMessage() {}
+
+func (x *StopRoomEgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRoomEgressResponse.ProtoReflect.Descriptor instead.
+func (*StopRoomEgressResponse) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StopRoomEgressResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *StopRoomEgressResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StopRoomEgressResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StopRoomEgressResponse) GetFileLocation() string {
+	if x != nil {
+		return x.FileLocation
+	}
+	return ""
+}
+
+// Health check request
+type HealthCheckRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional service name to check (empty = check all)
+	Service       string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckRequest) Reset() {
+	*x = HealthCheckRequest{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *HealthCheckRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+// Health check response
+type HealthCheckResponse struct {
+	state  protoimpl.MessageState            `protogen:"open.v1"`
+	Status HealthCheckResponse_ServingStatus `protobuf:"varint,1,opt,name=status,proto3,enum=mentra.livekit.bridge.HealthCheckResponse_ServingStatus" json:"status,omitempty"`
+	// Total active sessions
+	ActiveSessions int32 `protobuf:"varint,2,opt,name=active_sessions,json=activeSessions,proto3" json:"active_sessions,omitempty"`
+	// Total active streams
+	ActiveStreams int32 `protobuf:"varint,3,opt,name=active_streams,json=activeStreams,proto3" json:"active_streams,omitempty"`
+	// Uptime in seconds
+	UptimeSeconds int64 `protobuf:"varint,4,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	// Additional diagnostics
+	Metadata      map[string]string `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResponse) ProtoMessage() {}
+
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *HealthCheckResponse) GetStatus() HealthCheckResponse_ServingStatus {
+	if x != nil {
+		return x.Status
+	}
+	return HealthCheckResponse_UNKNOWN
+}
+
+func (x *HealthCheckResponse) GetActiveSessions() int32 {
+	if x != nil {
+		return x.ActiveSessions
+	}
+	return 0
+}
+
+func (x *HealthCheckResponse) GetActiveStreams() int32 {
+	if x != nil {
+		return x.ActiveStreams
+	}
+	return 0
+}
+
+func (x *HealthCheckResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *HealthCheckResponse) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// Statistics message (for future monitoring/debugging)
+type SessionStats struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	UserId              string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	AudioFramesSent     int64                  `protobuf:"varint,2,opt,name=audio_frames_sent,json=audioFramesSent,proto3" json:"audio_frames_sent,omitempty"`
+	AudioFramesReceived int64                  `protobuf:"varint,3,opt,name=audio_frames_received,json=audioFramesReceived,proto3" json:"audio_frames_received,omitempty"`
+	BytesSent           int64                  `protobuf:"varint,4,opt,name=bytes_sent,json=bytesSent,proto3" json:"bytes_sent,omitempty"`
+	BytesReceived       int64                  `protobuf:"varint,5,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	SessionDurationMs   int64                  `protobuf:"varint,6,opt,name=session_duration_ms,json=sessionDurationMs,proto3" json:"session_duration_ms,omitempty"`
+	RoomName            string                 `protobuf:"bytes,7,opt,name=room_name,json=roomName,proto3" json:"room_name,omitempty"`
+	ParticipantCount    int32                  `protobuf:"varint,8,opt,name=participant_count,json=participantCount,proto3" json:"participant_count,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SessionStats) Reset() {
+	*x = SessionStats{}
+	mi := &file_proto_livekit_bridge_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionStats) ProtoMessage() {}
+
+func (x *SessionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_livekit_bridge_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionStats.ProtoReflect.Descriptor instead.
+func (*SessionStats) Descriptor() ([]byte, []int) {
+	return file_proto_livekit_bridge_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SessionStats) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SessionStats) GetAudioFramesSent() int64 {
+	if x != nil {
+		return x.AudioFramesSent
+	}
+	return 0
+}
+
+func (x *SessionStats) GetAudioFramesReceived() int64 {
+	if x != nil {
+		return x.AudioFramesReceived
+	}
+	return 0
+}
+
+func (x *SessionStats) GetBytesSent() int64 {
+	if x != nil {
+		return x.BytesSent
+	}
+	return 0
+}
+
+func (x *SessionStats) GetBytesReceived() int64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+func (x *SessionStats) GetSessionDurationMs() int64 {
+	if x != nil {
+		return x.SessionDurationMs
+	}
+	return 0
+}
+
+func (x *SessionStats) GetRoomName() string {
+	if x != nil {
+		return x.RoomName
+	}
+	return ""
+}
+
+func (x *SessionStats) GetParticipantCount() int32 {
+	if x != nil {
+		return x.ParticipantCount
+	}
+	return 0
+}
+
+var File_proto_livekit_bridge_proto protoreflect.FileDescriptor
+
+const file_proto_livekit_bridge_proto_rawDesc = "" +
 	"\n" +
-	"\x1aproto/livekit_bridge.proto\x12\x15mentra.livekit.bridge\"\xbb\x01\n" +
+	"\x1aproto/livekit_bridge.proto\x12\x15mentra.livekit.bridge\"\xd3\x01\n" +
 	"\n" +
 	"AudioChunk\x12\x19\n" +
 	"\bpcm_data\x18\x01 \x01(\fR\apcmData\x12\x1f\n" +
@@ -1062,14 +2392,20 @@ const file_proto_livekit_bridge_proto_rawDesc = "" +
 	"\bchannels\x18\x03 \x01(\x05R\bchannels\x12!\n" +
 	"\ftimestamp_ms\x18\x04 \x01(\x03R\vtimestampMs\x12\x17\n" +
 	"\auser_id\x18\x05 \x01(\tR\x06userId\x12\x19\n" +
-	"\btrack_id\x18\x06 \x01(\x05R\atrackId\"\xa7\x01\n" +
+	"\btrack_id\x18\x06 \x01(\x05R\atrackId\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\"\x92\x02\n" +
 	"\x0fJoinRoomRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
 	"\troom_name\x18\x02 \x01(\tR\broomName\x12\x14\n" +
 	"\x05token\x18\x03 \x01(\tR\x05token\x12\x1f\n" +
 	"\vlivekit_url\x18\x04 \x01(\tR\n" +
 	"livekitUrl\x12'\n" +
-	"\x0ftarget_identity\x18\x05 \x01(\tR\x0etargetIdentity\"\xa6\x02\n" +
+	"\x0ftarget_identity\x18\x05 \x01(\tR\x0etargetIdentity\x12B\n" +
+	"\x05codec\x18\x06 \x01(\x0e2,.mentra.livekit.bridge.JoinRoomRequest.CodecR\x05codec\"%\n" +
+	"\x05Codec\x12\b\n" +
+	"\x04OPUS\x10\x00\x12\b\n" +
+	"\x04PCMU\x10\x01\x12\b\n" +
+	"\x04PCMA\x10\x02\"\xa6\x02\n" +
 	"\x10JoinRoomResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x12%\n" +
@@ -1084,7 +2420,7 @@ const file_proto_livekit_bridge_proto_rawDesc = "" +
 	"\x06reason\x18\x02 \x01(\tR\x06reason\"C\n" +
 	"\x11LeaveRoomResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\xb9\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xab\x02\n" +
 	"\x10PlayAudioRequest\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
@@ -1093,7 +2429,11 @@ const file_proto_livekit_bridge_proto_rawDesc = "" +
 	"\n" +
 	"stop_other\x18\x04 \x01(\bR\tstopOther\x12\x17\n" +
 	"\auser_id\x18\x05 \x01(\tR\x06userId\x12\x19\n" +
-	"\btrack_id\x18\x06 \x01(\x05R\atrackId\"\x9d\x03\n" +
+	"\btrack_id\x18\x06 \x01(\x05R\atrackId\x12'\n" +
+	"\x0fchannel_weights\x18\a \x03(\x02R\x0echannelWeights\x12\x1f\n" +
+	"\vtarget_lufs\x18\b \x01(\x02R\n" +
+	"targetLufs\x12&\n" +
+	"\x0fstart_offset_ms\x18\t \x01(\x03R\rstartOffsetMs\"\x9d\x03\n" +
 	"\x0ePlayAudioEvent\x12C\n" +
 	"\x04type\x18\x01 \x01(\x0e2/.mentra.livekit.bridge.PlayAudioEvent.EventTypeR\x04type\x12\x1d\n" +
 	"\n" +
@@ -1122,7 +2462,94 @@ const file_proto_livekit_bridge_proto_rawDesc = "" +
 	"\x11StopAudioResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x12,\n" +
-	"\x12stopped_request_id\x18\x03 \x01(\tR\x10stoppedRequestId\".\n" +
+	"\x12stopped_request_id\x18\x03 \x01(\tR\x10stoppedRequestId\",\n" +
+	"\x11PauseAudioRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"D\n" +
+	"\x12PauseAudioResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"-\n" +
+	"\x12ResumeAudioRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"E\n" +
+	"\x13ResumeAudioResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xa3\x01\n" +
+	"\x15SubscribeAudioRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1e\n" +
+	"\n" +
+	"identities\x18\x02 \x03(\tR\n" +
+	"identities\x12\x1d\n" +
+	"\n" +
+	"track_sids\x18\x03 \x03(\tR\ttrackSids\x122\n" +
+	"\x15disable_jitter_buffer\x18\x04 \x01(\bR\x13disableJitterBuffer\"\x93\x02\n" +
+	"\x14SubscribedAudioFrame\x12\x19\n" +
+	"\bpcm_data\x18\x01 \x01(\fR\apcmData\x12\x1f\n" +
+	"\vsample_rate\x18\x02 \x01(\x05R\n" +
+	"sampleRate\x121\n" +
+	"\x14participant_identity\x18\x03 \x01(\tR\x13participantIdentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x04 \x01(\tR\btrackSid\x12!\n" +
+	"\ftimestamp_ms\x18\x05 \x01(\x03R\vtimestampMs\x12L\n" +
+	"\vtrack_event\x18\x06 \x01(\v2+.mentra.livekit.bridge.SubscribedTrackEventR\n" +
+	"trackEvent\"\xe8\x01\n" +
+	"\x14SubscribedTrackEvent\x12I\n" +
+	"\x04type\x18\x01 \x01(\x0e25.mentra.livekit.bridge.SubscribedTrackEvent.EventTypeR\x04type\x12\x14\n" +
+	"\x05codec\x18\x02 \x01(\tR\x05codec\x12\x1d\n" +
+	"\n" +
+	"clock_rate\x18\x03 \x01(\x05R\tclockRate\x12\x1a\n" +
+	"\bchannels\x18\x04 \x01(\x05R\bchannels\"4\n" +
+	"\tEventType\x12\t\n" +
+	"\x05ADDED\x10\x00\x12\v\n" +
+	"\aREMOVED\x10\x01\x12\x0f\n" +
+	"\vUNSUPPORTED\x10\x02\"\xa8\x01\n" +
+	"\x18StreamAudioLevelsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1e\n" +
+	"\n" +
+	"identities\x18\x02 \x03(\tR\n" +
+	"identities\x12\x1f\n" +
+	"\vinterval_ms\x18\x03 \x01(\x05R\n" +
+	"intervalMs\x122\n" +
+	"\x15speaking_threshold_db\x18\x04 \x01(\x02R\x13speakingThresholdDb\"z\n" +
+	"\x0fAudioLevelEvent\x12D\n" +
+	"\x06levels\x18\x01 \x03(\v2,.mentra.livekit.bridge.ParticipantAudioLevelR\x06levels\x12!\n" +
+	"\ftimestamp_ms\x18\x02 \x01(\x03R\vtimestampMs\"j\n" +
+	"\x15ParticipantAudioLevel\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x19\n" +
+	"\blevel_db\x18\x02 \x01(\x02R\alevelDb\x12\x1a\n" +
+	"\bspeaking\x18\x03 \x01(\bR\bspeaking\"c\n" +
+	"\x15SetTrackVolumeRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x19\n" +
+	"\btrack_id\x18\x02 \x01(\x05R\atrackId\x12\x16\n" +
+	"\x06volume\x18\x03 \x01(\x02R\x06volume\"H\n" +
+	"\x16SetTrackVolumeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xb0\x01\n" +
+	"\x12SendControlRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x18\n" +
+	"\apayload\x18\x03 \x01(\fR\apayload\x12\x1a\n" +
+	"\breliable\x18\x04 \x01(\bR\breliable\x125\n" +
+	"\x16destination_identities\x18\x05 \x03(\tR\x15destinationIdentities\"E\n" +
+	"\x13SendControlResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xb8\x01\n" +
+	"\x16StartRoomEgressRequest\x12\x1b\n" +
+	"\troom_name\x18\x01 \x01(\tR\broomName\x12\x1d\n" +
+	"\n" +
+	"audio_only\x18\x03 \x01(\bR\taudioOnly\x12\x1b\n" +
+	"\tfile_type\x18\x04 \x01(\tR\bfileType\x12\x1a\n" +
+	"\bfilepath\x18\x05 \x01(\tR\bfilepath\x12\x16\n" +
+	"\x06layout\x18\x06 \x01(\tR\x06layoutJ\x04\b\x02\x10\x03R\vlivekit_url\"~\n" +
+	"\x17StartRoomEgressResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1b\n" +
+	"\tegress_id\x18\x03 \x01(\tR\begressId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\"G\n" +
+	"\x15StopRoomEgressRequest\x12\x1b\n" +
+	"\tegress_id\x18\x01 \x01(\tR\begressIdJ\x04\b\x02\x10\x03R\vlivekit_url\"\x85\x01\n" +
+	"\x16StopRoomEgressResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12#\n" +
+	"\rfile_location\x18\x04 \x01(\tR\ffileLocation\".\n" +
 	"\x12HealthCheckRequest\x12\x18\n" +
 	"\aservice\x18\x01 \x01(\tR\aservice\"\xc2\x03\n" +
 	"\x13HealthCheckResponse\x12P\n" +
@@ -1148,13 +2575,22 @@ const file_proto_livekit_bridge_proto_rawDesc = "" +
 	"\x0ebytes_received\x18\x05 \x01(\x03R\rbytesReceived\x12.\n" +
 	"\x13session_duration_ms\x18\x06 \x01(\x03R\x11sessionDurationMs\x12\x1b\n" +
 	"\troom_name\x18\a \x01(\tR\broomName\x12+\n" +
-	"\x11participant_count\x18\b \x01(\x05R\x10participantCount2\xca\x04\n" +
+	"\x11participant_count\x18\b \x01(\x05R\x10participantCount2\xa8\v\n" +
 	"\rLiveKitBridge\x12W\n" +
 	"\vStreamAudio\x12!.mentra.livekit.bridge.AudioChunk\x1a!.mentra.livekit.bridge.AudioChunk(\x010\x01\x12[\n" +
 	"\bJoinRoom\x12&.mentra.livekit.bridge.JoinRoomRequest\x1a'.mentra.livekit.bridge.JoinRoomResponse\x12^\n" +
 	"\tLeaveRoom\x12'.mentra.livekit.bridge.LeaveRoomRequest\x1a(.mentra.livekit.bridge.LeaveRoomResponse\x12]\n" +
 	"\tPlayAudio\x12'.mentra.livekit.bridge.PlayAudioRequest\x1a%.mentra.livekit.bridge.PlayAudioEvent0\x01\x12^\n" +
-	"\tStopAudio\x12'.mentra.livekit.bridge.StopAudioRequest\x1a(.mentra.livekit.bridge.StopAudioResponse\x12d\n" +
+	"\tStopAudio\x12'.mentra.livekit.bridge.StopAudioRequest\x1a(.mentra.livekit.bridge.StopAudioResponse\x12a\n" +
+	"\n" +
+	"PauseAudio\x12(.mentra.livekit.bridge.PauseAudioRequest\x1a).mentra.livekit.bridge.PauseAudioResponse\x12d\n" +
+	"\vResumeAudio\x12).mentra.livekit.bridge.ResumeAudioRequest\x1a*.mentra.livekit.bridge.ResumeAudioResponse\x12m\n" +
+	"\x0eSubscribeAudio\x12,.mentra.livekit.bridge.SubscribeAudioRequest\x1a+.mentra.livekit.bridge.SubscribedAudioFrame0\x01\x12n\n" +
+	"\x11StreamAudioLevels\x12/.mentra.livekit.bridge.StreamAudioLevelsRequest\x1a&.mentra.livekit.bridge.AudioLevelEvent0\x01\x12m\n" +
+	"\x0eSetTrackVolume\x12,.mentra.livekit.bridge.SetTrackVolumeRequest\x1a-.mentra.livekit.bridge.SetTrackVolumeResponse\x12d\n" +
+	"\vSendControl\x12).mentra.livekit.bridge.SendControlRequest\x1a*.mentra.livekit.bridge.SendControlResponse\x12p\n" +
+	"\x0fStartRoomEgress\x12-.mentra.livekit.bridge.StartRoomEgressRequest\x1a..mentra.livekit.bridge.StartRoomEgressResponse\x12m\n" +
+	"\x0eStopRoomEgress\x12,.mentra.livekit.bridge.StopRoomEgressRequest\x1a-.mentra.livekit.bridge.StopRoomEgressResponse\x12d\n" +
 	"\vHealthCheck\x12).mentra.livekit.bridge.HealthCheckRequest\x1a*.mentra.livekit.bridge.HealthCheckResponseB(Z&github.com/mentra/livekit-bridge/protob\x06proto3"
 
 var (
@@ -1169,50 +2605,90 @@ func file_proto_livekit_bridge_proto_rawDescGZIP() []byte {
 	return file_proto_livekit_bridge_proto_rawDescData
 }
 
-var file_proto_livekit_bridge_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_livekit_bridge_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_proto_livekit_bridge_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_livekit_bridge_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_proto_livekit_bridge_proto_goTypes = []any{
-	(PlayAudioEvent_EventType)(0),          // 0: mentra.livekit.bridge.PlayAudioEvent.EventType
-	(HealthCheckResponse_ServingStatus)(0), // 1: mentra.livekit.bridge.HealthCheckResponse.ServingStatus
-	(*AudioChunk)(nil),                     // 2: mentra.livekit.bridge.AudioChunk
-	(*JoinRoomRequest)(nil),                // 3: mentra.livekit.bridge.JoinRoomRequest
-	(*JoinRoomResponse)(nil),               // 4: mentra.livekit.bridge.JoinRoomResponse
-	(*LeaveRoomRequest)(nil),               // 5: mentra.livekit.bridge.LeaveRoomRequest
-	(*LeaveRoomResponse)(nil),              // 6: mentra.livekit.bridge.LeaveRoomResponse
-	(*PlayAudioRequest)(nil),               // 7: mentra.livekit.bridge.PlayAudioRequest
-	(*PlayAudioEvent)(nil),                 // 8: mentra.livekit.bridge.PlayAudioEvent
-	(*StopAudioRequest)(nil),               // 9: mentra.livekit.bridge.StopAudioRequest
-	(*StopAudioResponse)(nil),              // 10: mentra.livekit.bridge.StopAudioResponse
-	(*HealthCheckRequest)(nil),             // 11: mentra.livekit.bridge.HealthCheckRequest
-	(*HealthCheckResponse)(nil),            // 12: mentra.livekit.bridge.HealthCheckResponse
-	(*SessionStats)(nil),                   // 13: mentra.livekit.bridge.SessionStats
-	nil,                                    // 14: mentra.livekit.bridge.JoinRoomResponse.MetadataEntry
-	nil,                                    // 15: mentra.livekit.bridge.PlayAudioEvent.MetadataEntry
-	nil,                                    // 16: mentra.livekit.bridge.HealthCheckResponse.MetadataEntry
+	(JoinRoomRequest_Codec)(0),             // 0: mentra.livekit.bridge.JoinRoomRequest.Codec
+	(PlayAudioEvent_EventType)(0),          // 1: mentra.livekit.bridge.PlayAudioEvent.EventType
+	(SubscribedTrackEvent_EventType)(0),    // 2: mentra.livekit.bridge.SubscribedTrackEvent.EventType
+	(HealthCheckResponse_ServingStatus)(0), // 3: mentra.livekit.bridge.HealthCheckResponse.ServingStatus
+	(*AudioChunk)(nil),                     // 4: mentra.livekit.bridge.AudioChunk
+	(*JoinRoomRequest)(nil),                // 5: mentra.livekit.bridge.JoinRoomRequest
+	(*JoinRoomResponse)(nil),               // 6: mentra.livekit.bridge.JoinRoomResponse
+	(*LeaveRoomRequest)(nil),               // 7: mentra.livekit.bridge.LeaveRoomRequest
+	(*LeaveRoomResponse)(nil),              // 8: mentra.livekit.bridge.LeaveRoomResponse
+	(*PlayAudioRequest)(nil),               // 9: mentra.livekit.bridge.PlayAudioRequest
+	(*PlayAudioEvent)(nil),                 // 10: mentra.livekit.bridge.PlayAudioEvent
+	(*StopAudioRequest)(nil),               // 11: mentra.livekit.bridge.StopAudioRequest
+	(*StopAudioResponse)(nil),              // 12: mentra.livekit.bridge.StopAudioResponse
+	(*PauseAudioRequest)(nil),              // 13: mentra.livekit.bridge.PauseAudioRequest
+	(*PauseAudioResponse)(nil),             // 14: mentra.livekit.bridge.PauseAudioResponse
+	(*ResumeAudioRequest)(nil),             // 15: mentra.livekit.bridge.ResumeAudioRequest
+	(*ResumeAudioResponse)(nil),            // 16: mentra.livekit.bridge.ResumeAudioResponse
+	(*SubscribeAudioRequest)(nil),          // 17: mentra.livekit.bridge.SubscribeAudioRequest
+	(*SubscribedAudioFrame)(nil),           // 18: mentra.livekit.bridge.SubscribedAudioFrame
+	(*SubscribedTrackEvent)(nil),           // 19: mentra.livekit.bridge.SubscribedTrackEvent
+	(*StreamAudioLevelsRequest)(nil),       // 20: mentra.livekit.bridge.StreamAudioLevelsRequest
+	(*AudioLevelEvent)(nil),                // 21: mentra.livekit.bridge.AudioLevelEvent
+	(*ParticipantAudioLevel)(nil),          // 22: mentra.livekit.bridge.ParticipantAudioLevel
+	(*SetTrackVolumeRequest)(nil),          // 23: mentra.livekit.bridge.SetTrackVolumeRequest
+	(*SetTrackVolumeResponse)(nil),         // 24: mentra.livekit.bridge.SetTrackVolumeResponse
+	(*SendControlRequest)(nil),             // 25: mentra.livekit.bridge.SendControlRequest
+	(*SendControlResponse)(nil),            // 26: mentra.livekit.bridge.SendControlResponse
+	(*StartRoomEgressRequest)(nil),         // 27: mentra.livekit.bridge.StartRoomEgressRequest
+	(*StartRoomEgressResponse)(nil),        // 28: mentra.livekit.bridge.StartRoomEgressResponse
+	(*StopRoomEgressRequest)(nil),          // 29: mentra.livekit.bridge.StopRoomEgressRequest
+	(*StopRoomEgressResponse)(nil),         // 30: mentra.livekit.bridge.StopRoomEgressResponse
+	(*HealthCheckRequest)(nil),             // 31: mentra.livekit.bridge.HealthCheckRequest
+	(*HealthCheckResponse)(nil),            // 32: mentra.livekit.bridge.HealthCheckResponse
+	(*SessionStats)(nil),                   // 33: mentra.livekit.bridge.SessionStats
+	nil,                                    // 34: mentra.livekit.bridge.JoinRoomResponse.MetadataEntry
+	nil,                                    // 35: mentra.livekit.bridge.PlayAudioEvent.MetadataEntry
+	nil,                                    // 36: mentra.livekit.bridge.HealthCheckResponse.MetadataEntry
 }
 var file_proto_livekit_bridge_proto_depIdxs = []int32{
-	14, // 0: mentra.livekit.bridge.JoinRoomResponse.metadata:type_name -> mentra.livekit.bridge.JoinRoomResponse.MetadataEntry
-	0,  // 1: mentra.livekit.bridge.PlayAudioEvent.type:type_name -> mentra.livekit.bridge.PlayAudioEvent.EventType
-	15, // 2: mentra.livekit.bridge.PlayAudioEvent.metadata:type_name -> mentra.livekit.bridge.PlayAudioEvent.MetadataEntry
-	1,  // 3: mentra.livekit.bridge.HealthCheckResponse.status:type_name -> mentra.livekit.bridge.HealthCheckResponse.ServingStatus
-	16, // 4: mentra.livekit.bridge.HealthCheckResponse.metadata:type_name -> mentra.livekit.bridge.HealthCheckResponse.MetadataEntry
-	2,  // 5: mentra.livekit.bridge.LiveKitBridge.StreamAudio:input_type -> mentra.livekit.bridge.AudioChunk
-	3,  // 6: mentra.livekit.bridge.LiveKitBridge.JoinRoom:input_type -> mentra.livekit.bridge.JoinRoomRequest
-	5,  // 7: mentra.livekit.bridge.LiveKitBridge.LeaveRoom:input_type -> mentra.livekit.bridge.LeaveRoomRequest
-	7,  // 8: mentra.livekit.bridge.LiveKitBridge.PlayAudio:input_type -> mentra.livekit.bridge.PlayAudioRequest
-	9,  // 9: mentra.livekit.bridge.LiveKitBridge.StopAudio:input_type -> mentra.livekit.bridge.StopAudioRequest
-	11, // 10: mentra.livekit.bridge.LiveKitBridge.HealthCheck:input_type -> mentra.livekit.bridge.HealthCheckRequest
-	2,  // 11: mentra.livekit.bridge.LiveKitBridge.StreamAudio:output_type -> mentra.livekit.bridge.AudioChunk
-	4,  // 12: mentra.livekit.bridge.LiveKitBridge.JoinRoom:output_type -> mentra.livekit.bridge.JoinRoomResponse
-	6,  // 13: mentra.livekit.bridge.LiveKitBridge.LeaveRoom:output_type -> mentra.livekit.bridge.LeaveRoomResponse
-	8,  // 14: mentra.livekit.bridge.LiveKitBridge.PlayAudio:output_type -> mentra.livekit.bridge.PlayAudioEvent
-	10, // 15: mentra.livekit.bridge.LiveKitBridge.StopAudio:output_type -> mentra.livekit.bridge.StopAudioResponse
-	12, // 16: mentra.livekit.bridge.LiveKitBridge.HealthCheck:output_type -> mentra.livekit.bridge.HealthCheckResponse
-	11, // [11:17] is the sub-list for method output_type
-	5,  // [5:11] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	0,  // 0: mentra.livekit.bridge.JoinRoomRequest.codec:type_name -> mentra.livekit.bridge.JoinRoomRequest.Codec
+	34, // 1: mentra.livekit.bridge.JoinRoomResponse.metadata:type_name -> mentra.livekit.bridge.JoinRoomResponse.MetadataEntry
+	1,  // 2: mentra.livekit.bridge.PlayAudioEvent.type:type_name -> mentra.livekit.bridge.PlayAudioEvent.EventType
+	35, // 3: mentra.livekit.bridge.PlayAudioEvent.metadata:type_name -> mentra.livekit.bridge.PlayAudioEvent.MetadataEntry
+	19, // 4: mentra.livekit.bridge.SubscribedAudioFrame.track_event:type_name -> mentra.livekit.bridge.SubscribedTrackEvent
+	2,  // 5: mentra.livekit.bridge.SubscribedTrackEvent.type:type_name -> mentra.livekit.bridge.SubscribedTrackEvent.EventType
+	22, // 6: mentra.livekit.bridge.AudioLevelEvent.levels:type_name -> mentra.livekit.bridge.ParticipantAudioLevel
+	3,  // 7: mentra.livekit.bridge.HealthCheckResponse.status:type_name -> mentra.livekit.bridge.HealthCheckResponse.ServingStatus
+	36, // 8: mentra.livekit.bridge.HealthCheckResponse.metadata:type_name -> mentra.livekit.bridge.HealthCheckResponse.MetadataEntry
+	4,  // 9: mentra.livekit.bridge.LiveKitBridge.StreamAudio:input_type -> mentra.livekit.bridge.AudioChunk
+	5,  // 10: mentra.livekit.bridge.LiveKitBridge.JoinRoom:input_type -> mentra.livekit.bridge.JoinRoomRequest
+	7,  // 11: mentra.livekit.bridge.LiveKitBridge.LeaveRoom:input_type -> mentra.livekit.bridge.LeaveRoomRequest
+	9,  // 12: mentra.livekit.bridge.LiveKitBridge.PlayAudio:input_type -> mentra.livekit.bridge.PlayAudioRequest
+	11, // 13: mentra.livekit.bridge.LiveKitBridge.StopAudio:input_type -> mentra.livekit.bridge.StopAudioRequest
+	13, // 14: mentra.livekit.bridge.LiveKitBridge.PauseAudio:input_type -> mentra.livekit.bridge.PauseAudioRequest
+	15, // 15: mentra.livekit.bridge.LiveKitBridge.ResumeAudio:input_type -> mentra.livekit.bridge.ResumeAudioRequest
+	17, // 16: mentra.livekit.bridge.LiveKitBridge.SubscribeAudio:input_type -> mentra.livekit.bridge.SubscribeAudioRequest
+	20, // 17: mentra.livekit.bridge.LiveKitBridge.StreamAudioLevels:input_type -> mentra.livekit.bridge.StreamAudioLevelsRequest
+	23, // 18: mentra.livekit.bridge.LiveKitBridge.SetTrackVolume:input_type -> mentra.livekit.bridge.SetTrackVolumeRequest
+	25, // 19: mentra.livekit.bridge.LiveKitBridge.SendControl:input_type -> mentra.livekit.bridge.SendControlRequest
+	27, // 20: mentra.livekit.bridge.LiveKitBridge.StartRoomEgress:input_type -> mentra.livekit.bridge.StartRoomEgressRequest
+	29, // 21: mentra.livekit.bridge.LiveKitBridge.StopRoomEgress:input_type -> mentra.livekit.bridge.StopRoomEgressRequest
+	31, // 22: mentra.livekit.bridge.LiveKitBridge.HealthCheck:input_type -> mentra.livekit.bridge.HealthCheckRequest
+	4,  // 23: mentra.livekit.bridge.LiveKitBridge.StreamAudio:output_type -> mentra.livekit.bridge.AudioChunk
+	6,  // 24: mentra.livekit.bridge.LiveKitBridge.JoinRoom:output_type -> mentra.livekit.bridge.JoinRoomResponse
+	8,  // 25: mentra.livekit.bridge.LiveKitBridge.LeaveRoom:output_type -> mentra.livekit.bridge.LeaveRoomResponse
+	10, // 26: mentra.livekit.bridge.LiveKitBridge.PlayAudio:output_type -> mentra.livekit.bridge.PlayAudioEvent
+	12, // 27: mentra.livekit.bridge.LiveKitBridge.StopAudio:output_type -> mentra.livekit.bridge.StopAudioResponse
+	14, // 28: mentra.livekit.bridge.LiveKitBridge.PauseAudio:output_type -> mentra.livekit.bridge.PauseAudioResponse
+	16, // 29: mentra.livekit.bridge.LiveKitBridge.ResumeAudio:output_type -> mentra.livekit.bridge.ResumeAudioResponse
+	18, // 30: mentra.livekit.bridge.LiveKitBridge.SubscribeAudio:output_type -> mentra.livekit.bridge.SubscribedAudioFrame
+	21, // 31: mentra.livekit.bridge.LiveKitBridge.StreamAudioLevels:output_type -> mentra.livekit.bridge.AudioLevelEvent
+	24, // 32: mentra.livekit.bridge.LiveKitBridge.SetTrackVolume:output_type -> mentra.livekit.bridge.SetTrackVolumeResponse
+	26, // 33: mentra.livekit.bridge.LiveKitBridge.SendControl:output_type -> mentra.livekit.bridge.SendControlResponse
+	28, // 34: mentra.livekit.bridge.LiveKitBridge.StartRoomEgress:output_type -> mentra.livekit.bridge.StartRoomEgressResponse
+	30, // 35: mentra.livekit.bridge.LiveKitBridge.StopRoomEgress:output_type -> mentra.livekit.bridge.StopRoomEgressResponse
+	32, // 36: mentra.livekit.bridge.LiveKitBridge.HealthCheck:output_type -> mentra.livekit.bridge.HealthCheckResponse
+	23, // [23:37] is the sub-list for method output_type
+	9,  // [9:23] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_livekit_bridge_proto_init() }
@@ -1225,8 +2701,8 @@ func file_proto_livekit_bridge_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_livekit_bridge_proto_rawDesc), len(file_proto_livekit_bridge_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   15,
+			NumEnums:      4,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
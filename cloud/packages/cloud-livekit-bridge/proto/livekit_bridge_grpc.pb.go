@@ -19,12 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	LiveKitBridge_StreamAudio_FullMethodName = "/mentra.livekit.bridge.LiveKitBridge/StreamAudio"
-	LiveKitBridge_JoinRoom_FullMethodName    = "/mentra.livekit.bridge.LiveKitBridge/JoinRoom"
-	LiveKitBridge_LeaveRoom_FullMethodName   = "/mentra.livekit.bridge.LiveKitBridge/LeaveRoom"
-	LiveKitBridge_PlayAudio_FullMethodName   = "/mentra.livekit.bridge.LiveKitBridge/PlayAudio"
-	LiveKitBridge_StopAudio_FullMethodName   = "/mentra.livekit.bridge.LiveKitBridge/StopAudio"
-	LiveKitBridge_HealthCheck_FullMethodName = "/mentra.livekit.bridge.LiveKitBridge/HealthCheck"
+	LiveKitBridge_StreamAudio_FullMethodName       = "/mentra.livekit.bridge.LiveKitBridge/StreamAudio"
+	LiveKitBridge_JoinRoom_FullMethodName          = "/mentra.livekit.bridge.LiveKitBridge/JoinRoom"
+	LiveKitBridge_LeaveRoom_FullMethodName         = "/mentra.livekit.bridge.LiveKitBridge/LeaveRoom"
+	LiveKitBridge_PlayAudio_FullMethodName         = "/mentra.livekit.bridge.LiveKitBridge/PlayAudio"
+	LiveKitBridge_StopAudio_FullMethodName         = "/mentra.livekit.bridge.LiveKitBridge/StopAudio"
+	LiveKitBridge_PauseAudio_FullMethodName        = "/mentra.livekit.bridge.LiveKitBridge/PauseAudio"
+	LiveKitBridge_ResumeAudio_FullMethodName       = "/mentra.livekit.bridge.LiveKitBridge/ResumeAudio"
+	LiveKitBridge_SubscribeAudio_FullMethodName    = "/mentra.livekit.bridge.LiveKitBridge/SubscribeAudio"
+	LiveKitBridge_StreamAudioLevels_FullMethodName = "/mentra.livekit.bridge.LiveKitBridge/StreamAudioLevels"
+	LiveKitBridge_SetTrackVolume_FullMethodName    = "/mentra.livekit.bridge.LiveKitBridge/SetTrackVolume"
+	LiveKitBridge_SendControl_FullMethodName       = "/mentra.livekit.bridge.LiveKitBridge/SendControl"
+	LiveKitBridge_StartRoomEgress_FullMethodName   = "/mentra.livekit.bridge.LiveKitBridge/StartRoomEgress"
+	LiveKitBridge_StopRoomEgress_FullMethodName    = "/mentra.livekit.bridge.LiveKitBridge/StopRoomEgress"
+	LiveKitBridge_HealthCheck_FullMethodName       = "/mentra.livekit.bridge.LiveKitBridge/HealthCheck"
 )
 
 // LiveKitBridgeClient is the client API for LiveKitBridge service.
@@ -41,16 +49,42 @@ type LiveKitBridgeClient interface {
 	// Client can send audio TO LiveKit room and receive audio FROM room
 	// in a single multiplexed stream. Audio arrives bursty from LiveKit
 	// (network/buffering), TypeScript handles jitter buffering for Soniox.
+	// One stream per user: a new stream takes over, and the previous one
+	// ends with ABORTED.
 	StreamAudio(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[AudioChunk, AudioChunk], error)
 	// Room lifecycle management
 	JoinRoom(ctx context.Context, in *JoinRoomRequest, opts ...grpc.CallOption) (*JoinRoomResponse, error)
 	LeaveRoom(ctx context.Context, in *LeaveRoomRequest, opts ...grpc.CallOption) (*LeaveRoomResponse, error)
-	// Server-side audio playback (MP3/WAV → LiveKit track)
+	// Server-side audio playback (MP3/WAV/AAC → LiveKit track)
 	//
 	// Returns streaming events for progress tracking.
 	// Used by session.audio.playAudio() and session.audio.speak()
 	PlayAudio(ctx context.Context, in *PlayAudioRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PlayAudioEvent], error)
 	StopAudio(ctx context.Context, in *StopAudioRequest, opts ...grpc.CallOption) (*StopAudioResponse, error)
+	// Hold the current playback where it is and carry on from the same
+	// sample. The fetch and decoder stay open while paused, so a source that
+	// times out idle connections may end the playback with read_failed.
+	PauseAudio(ctx context.Context, in *PauseAudioRequest, opts ...grpc.CallOption) (*PauseAudioResponse, error)
+	ResumeAudio(ctx context.Context, in *ResumeAudioRequest, opts ...grpc.CallOption) (*ResumeAudioResponse, error)
+	// Receive the room's published audio tracks, decoded to 16kHz mono PCM16
+	// and tagged with the sending participant. Tracks are subscribed while at
+	// least one SubscribeAudio stream is open for the user.
+	SubscribeAudio(ctx context.Context, in *SubscribeAudioRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribedAudioFrame], error)
+	// Periodic per-participant audio levels and speaking flags, so UIs can
+	// show who is talking without receiving the audio. Covers data-packet
+	// audio and published tracks (subscribed while the stream is open).
+	StreamAudioLevels(ctx context.Context, in *StreamAudioLevelsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AudioLevelEvent], error)
+	// Persist a default volume for a track, used by later PlayAudio
+	// calls on that track that don't set one
+	SetTrackVolume(ctx context.Context, in *SetTrackVolumeRequest, opts ...grpc.CallOption) (*SetTrackVolumeResponse, error)
+	// Send a non-audio data packet (e.g. a control message) to room
+	// participants over the session's connection
+	SendControl(ctx context.Context, in *SendControlRequest, opts ...grpc.CallOption) (*SendControlResponse, error)
+	// Record a whole room with LiveKit Egress, authorized with the bridge's
+	// LIVEKIT_API_KEY/SECRET. The file is written to the egress service's
+	// configured storage and finalized after StopRoomEgress.
+	StartRoomEgress(ctx context.Context, in *StartRoomEgressRequest, opts ...grpc.CallOption) (*StartRoomEgressResponse, error)
+	StopRoomEgress(ctx context.Context, in *StopRoomEgressRequest, opts ...grpc.CallOption) (*StopRoomEgressResponse, error)
 	// Health check (for monitoring/load balancing)
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -125,6 +159,104 @@ func (c *liveKitBridgeClient) StopAudio(ctx context.Context, in *StopAudioReques
 	return out, nil
 }
 
+func (c *liveKitBridgeClient) PauseAudio(ctx context.Context, in *PauseAudioRequest, opts ...grpc.CallOption) (*PauseAudioResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PauseAudioResponse)
+	err := c.cc.Invoke(ctx, LiveKitBridge_PauseAudio_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *liveKitBridgeClient) ResumeAudio(ctx context.Context, in *ResumeAudioRequest, opts ...grpc.CallOption) (*ResumeAudioResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResumeAudioResponse)
+	err := c.cc.Invoke(ctx, LiveKitBridge_ResumeAudio_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *liveKitBridgeClient) SubscribeAudio(ctx context.Context, in *SubscribeAudioRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribedAudioFrame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LiveKitBridge_ServiceDesc.Streams[2], LiveKitBridge_SubscribeAudio_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeAudioRequest, SubscribedAudioFrame]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LiveKitBridge_SubscribeAudioClient = grpc.ServerStreamingClient[SubscribedAudioFrame]
+
+func (c *liveKitBridgeClient) StreamAudioLevels(ctx context.Context, in *StreamAudioLevelsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AudioLevelEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LiveKitBridge_ServiceDesc.Streams[3], LiveKitBridge_StreamAudioLevels_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamAudioLevelsRequest, AudioLevelEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LiveKitBridge_StreamAudioLevelsClient = grpc.ServerStreamingClient[AudioLevelEvent]
+
+func (c *liveKitBridgeClient) SetTrackVolume(ctx context.Context, in *SetTrackVolumeRequest, opts ...grpc.CallOption) (*SetTrackVolumeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetTrackVolumeResponse)
+	err := c.cc.Invoke(ctx, LiveKitBridge_SetTrackVolume_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *liveKitBridgeClient) SendControl(ctx context.Context, in *SendControlRequest, opts ...grpc.CallOption) (*SendControlResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendControlResponse)
+	err := c.cc.Invoke(ctx, LiveKitBridge_SendControl_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *liveKitBridgeClient) StartRoomEgress(ctx context.Context, in *StartRoomEgressRequest, opts ...grpc.CallOption) (*StartRoomEgressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartRoomEgressResponse)
+	err := c.cc.Invoke(ctx, LiveKitBridge_StartRoomEgress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *liveKitBridgeClient) StopRoomEgress(ctx context.Context, in *StopRoomEgressRequest, opts ...grpc.CallOption) (*StopRoomEgressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopRoomEgressResponse)
+	err := c.cc.Invoke(ctx, LiveKitBridge_StopRoomEgress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *liveKitBridgeClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -149,16 +281,42 @@ type LiveKitBridgeServer interface {
 	// Client can send audio TO LiveKit room and receive audio FROM room
 	// in a single multiplexed stream. Audio arrives bursty from LiveKit
 	// (network/buffering), TypeScript handles jitter buffering for Soniox.
+	// One stream per user: a new stream takes over, and the previous one
+	// ends with ABORTED.
 	StreamAudio(grpc.BidiStreamingServer[AudioChunk, AudioChunk]) error
 	// Room lifecycle management
 	JoinRoom(context.Context, *JoinRoomRequest) (*JoinRoomResponse, error)
 	LeaveRoom(context.Context, *LeaveRoomRequest) (*LeaveRoomResponse, error)
-	// Server-side audio playback (MP3/WAV → LiveKit track)
+	// Server-side audio playback (MP3/WAV/AAC → LiveKit track)
 	//
 	// Returns streaming events for progress tracking.
 	// Used by session.audio.playAudio() and session.audio.speak()
 	PlayAudio(*PlayAudioRequest, grpc.ServerStreamingServer[PlayAudioEvent]) error
 	StopAudio(context.Context, *StopAudioRequest) (*StopAudioResponse, error)
+	// Hold the current playback where it is and carry on from the same
+	// sample. The fetch and decoder stay open while paused, so a source that
+	// times out idle connections may end the playback with read_failed.
+	PauseAudio(context.Context, *PauseAudioRequest) (*PauseAudioResponse, error)
+	ResumeAudio(context.Context, *ResumeAudioRequest) (*ResumeAudioResponse, error)
+	// Receive the room's published audio tracks, decoded to 16kHz mono PCM16
+	// and tagged with the sending participant. Tracks are subscribed while at
+	// least one SubscribeAudio stream is open for the user.
+	SubscribeAudio(*SubscribeAudioRequest, grpc.ServerStreamingServer[SubscribedAudioFrame]) error
+	// Periodic per-participant audio levels and speaking flags, so UIs can
+	// show who is talking without receiving the audio. Covers data-packet
+	// audio and published tracks (subscribed while the stream is open).
+	StreamAudioLevels(*StreamAudioLevelsRequest, grpc.ServerStreamingServer[AudioLevelEvent]) error
+	// Persist a default volume for a track, used by later PlayAudio
+	// calls on that track that don't set one
+	SetTrackVolume(context.Context, *SetTrackVolumeRequest) (*SetTrackVolumeResponse, error)
+	// Send a non-audio data packet (e.g. a control message) to room
+	// participants over the session's connection
+	SendControl(context.Context, *SendControlRequest) (*SendControlResponse, error)
+	// Record a whole room with LiveKit Egress, authorized with the bridge's
+	// LIVEKIT_API_KEY/SECRET. The file is written to the egress service's
+	// configured storage and finalized after StopRoomEgress.
+	StartRoomEgress(context.Context, *StartRoomEgressRequest) (*StartRoomEgressResponse, error)
+	StopRoomEgress(context.Context, *StopRoomEgressRequest) (*StopRoomEgressResponse, error)
 	// Health check (for monitoring/load balancing)
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedLiveKitBridgeServer()
@@ -186,6 +344,30 @@ func (UnimplementedLiveKitBridgeServer) PlayAudio(*PlayAudioRequest, grpc.Server
 func (UnimplementedLiveKitBridgeServer) StopAudio(context.Context, *StopAudioRequest) (*StopAudioResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StopAudio not implemented")
 }
+func (UnimplementedLiveKitBridgeServer) PauseAudio(context.Context, *PauseAudioRequest) (*PauseAudioResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseAudio not implemented")
+}
+func (UnimplementedLiveKitBridgeServer) ResumeAudio(context.Context, *ResumeAudioRequest) (*ResumeAudioResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeAudio not implemented")
+}
+func (UnimplementedLiveKitBridgeServer) SubscribeAudio(*SubscribeAudioRequest, grpc.ServerStreamingServer[SubscribedAudioFrame]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAudio not implemented")
+}
+func (UnimplementedLiveKitBridgeServer) StreamAudioLevels(*StreamAudioLevelsRequest, grpc.ServerStreamingServer[AudioLevelEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAudioLevels not implemented")
+}
+func (UnimplementedLiveKitBridgeServer) SetTrackVolume(context.Context, *SetTrackVolumeRequest) (*SetTrackVolumeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTrackVolume not implemented")
+}
+func (UnimplementedLiveKitBridgeServer) SendControl(context.Context, *SendControlRequest) (*SendControlResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendControl not implemented")
+}
+func (UnimplementedLiveKitBridgeServer) StartRoomEgress(context.Context, *StartRoomEgressRequest) (*StartRoomEgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartRoomEgress not implemented")
+}
+func (UnimplementedLiveKitBridgeServer) StopRoomEgress(context.Context, *StopRoomEgressRequest) (*StopRoomEgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopRoomEgress not implemented")
+}
 func (UnimplementedLiveKitBridgeServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -282,6 +464,136 @@ func _LiveKitBridge_StopAudio_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LiveKitBridge_PauseAudio_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseAudioRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LiveKitBridgeServer).PauseAudio(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LiveKitBridge_PauseAudio_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LiveKitBridgeServer).PauseAudio(ctx, req.(*PauseAudioRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LiveKitBridge_ResumeAudio_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeAudioRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LiveKitBridgeServer).ResumeAudio(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LiveKitBridge_ResumeAudio_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LiveKitBridgeServer).ResumeAudio(ctx, req.(*ResumeAudioRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LiveKitBridge_SubscribeAudio_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeAudioRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LiveKitBridgeServer).SubscribeAudio(m, &grpc.GenericServerStream[SubscribeAudioRequest, SubscribedAudioFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LiveKitBridge_SubscribeAudioServer = grpc.ServerStreamingServer[SubscribedAudioFrame]
+
+func _LiveKitBridge_StreamAudioLevels_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAudioLevelsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LiveKitBridgeServer).StreamAudioLevels(m, &grpc.GenericServerStream[StreamAudioLevelsRequest, AudioLevelEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type LiveKitBridge_StreamAudioLevelsServer = grpc.ServerStreamingServer[AudioLevelEvent]
+
+func _LiveKitBridge_SetTrackVolume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTrackVolumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LiveKitBridgeServer).SetTrackVolume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LiveKitBridge_SetTrackVolume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LiveKitBridgeServer).SetTrackVolume(ctx, req.(*SetTrackVolumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LiveKitBridge_SendControl_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendControlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LiveKitBridgeServer).SendControl(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LiveKitBridge_SendControl_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LiveKitBridgeServer).SendControl(ctx, req.(*SendControlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LiveKitBridge_StartRoomEgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRoomEgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LiveKitBridgeServer).StartRoomEgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LiveKitBridge_StartRoomEgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LiveKitBridgeServer).StartRoomEgress(ctx, req.(*StartRoomEgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LiveKitBridge_StopRoomEgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRoomEgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LiveKitBridgeServer).StopRoomEgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LiveKitBridge_StopRoomEgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LiveKitBridgeServer).StopRoomEgress(ctx, req.(*StopRoomEgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _LiveKitBridge_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -319,6 +631,30 @@ var LiveKitBridge_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopAudio",
 			Handler:    _LiveKitBridge_StopAudio_Handler,
 		},
+		{
+			MethodName: "PauseAudio",
+			Handler:    _LiveKitBridge_PauseAudio_Handler,
+		},
+		{
+			MethodName: "ResumeAudio",
+			Handler:    _LiveKitBridge_ResumeAudio_Handler,
+		},
+		{
+			MethodName: "SetTrackVolume",
+			Handler:    _LiveKitBridge_SetTrackVolume_Handler,
+		},
+		{
+			MethodName: "SendControl",
+			Handler:    _LiveKitBridge_SendControl_Handler,
+		},
+		{
+			MethodName: "StartRoomEgress",
+			Handler:    _LiveKitBridge_StartRoomEgress_Handler,
+		},
+		{
+			MethodName: "StopRoomEgress",
+			Handler:    _LiveKitBridge_StopRoomEgress_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _LiveKitBridge_HealthCheck_Handler,
@@ -336,6 +672,16 @@ var LiveKitBridge_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _LiveKitBridge_PlayAudio_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeAudio",
+			Handler:       _LiveKitBridge_SubscribeAudio_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAudioLevels",
+			Handler:       _LiveKitBridge_StreamAudioLevels_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proto/livekit_bridge.proto",
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// Automatic room reconnection.
+//
+// When LiveKit drops the connection (OnDisconnected) the session used to be
+// left dead until TypeScript tore it down. Instead the room is re-joined with
+// the stored token, retrying with exponential backoff, and the tracks that
+// were published are published again. Progress is reported to the attached
+// StreamAudio stream as status chunks.
+
+// Status values sent in AudioChunk.status
+const (
+	statusReconnecting    = "reconnecting"
+	statusReconnected     = "reconnected"
+	statusReconnectFailed = "reconnect_failed"
+)
+
+// currentRoom returns the session's live room connection
+func (s *RoomSession) currentRoom() *lksdk.Room {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.room
+}
+
+// trackNames lists the published tracks
+func (s *RoomSession) trackNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.tracks))
+	for name := range s.tracks {
+		names = append(names, name)
+	}
+	return names
+}
+
+// emitStatus queues a status chunk for the attached StreamAudio stream.
+// Without a stream, or if it isn't keeping up, the status is dropped.
+func (s *RoomSession) emitStatus(status string) {
+	if s.activeStreams.Load() == 0 {
+		return
+	}
+	select {
+	case s.statusEvents <- status:
+	default:
+	}
+}
+
+// reconnectDelay is the backoff before attempt n (0-based)
+func (c *Config) reconnectDelay(n int) time.Duration {
+	d := c.ReconnectBaseDelay << n
+	if d <= 0 || d > c.ReconnectMaxDelay {
+		d = c.ReconnectMaxDelay
+	}
+	return d
+}
+
+// reconnectRoom re-joins after lost disconnected. It gives up once the
+// session closes, the room has already been replaced, or the attempts run
+// out.
+func (s *LiveKitBridgeService) reconnectRoom(session *RoomSession, lost *lksdk.Room, connect func() (*lksdk.Room, error)) {
+	if !session.reconnecting.CompareAndSwap(false, true) {
+		return
+	}
+	defer session.reconnecting.Store(false)
+	if session.currentRoom() != lost {
+		return
+	}
+
+	names := session.trackNames()
+	session.emitStatus(statusReconnecting)
+	for attempt := 0; attempt < s.config.ReconnectMaxAttempts; attempt++ {
+		delay := s.config.reconnectDelay(attempt)
+		select {
+		case <-session.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		log.Printf("Reconnecting LiveKit room for user %s (attempt %d/%d)", session.userId, attempt+1, s.config.ReconnectMaxAttempts)
+		room, err := connect()
+		if err != nil {
+			log.Printf("Reconnect attempt %d failed for user %s: %v", attempt+1, session.userId, err)
+			continue
+		}
+		if !session.swapRoom(room) {
+			return // closed while connecting
+		}
+		for _, name := range names {
+			if _, err := session.getOrCreateTrack(name); err != nil {
+				log.Printf("Republishing track '%s' for user %s failed: %v", name, session.userId, err)
+			}
+		}
+		session.markPacket()
+		s.bsLogger.LogInfo("Reconnected to LiveKit room", map[string]interface{}{
+			"user_id":  session.userId,
+			"attempts": attempt + 1,
+			"tracks":   len(names),
+		})
+		log.Printf("Reconnected LiveKit room for user %s after %d attempt(s), republished %d track(s)", session.userId, attempt+1, len(names))
+		session.emitStatus(statusReconnected)
+		return
+	}
+
+	s.bsLogger.LogError("LiveKit reconnect gave up", fmt.Errorf("%d attempts failed", s.config.ReconnectMaxAttempts), map[string]interface{}{
+		"user_id": session.userId,
+	})
+	log.Printf("Giving up reconnecting LiveKit room for user %s", session.userId)
+	session.emitStatus(statusReconnectFailed)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// resumeRetryDelay is the pause before each Range request after a drop
+const resumeRetryDelay = 250 * time.Millisecond
+
+// resumableBody reads a PlayAudio download, and when the connection drops
+// mid-file re-requests the rest with a Range header from the last byte
+// handed to the decoder. If-Range pins the resumed bytes to the same version
+// of the file; a server that answers with anything but the matching 206
+// ends the download. Each PlayAudio gets attempts resumes in total.
+type resumableBody struct {
+	ctx       context.Context
+	url       string
+	validator string // ETag or Last-Modified for If-Range
+	attempts  int
+	offset    int64 // bytes read so far
+
+	mu     sync.Mutex // body may be closed from another goroutine
+	body   io.ReadCloser
+	closed bool
+}
+
+// newResumableBody wraps resp's body, allowing attempts resumes
+func newResumableBody(ctx context.Context, resp *http.Response, attempts int) *resumableBody {
+	return &resumableBody{
+		ctx:       ctx,
+		url:       resp.Request.URL.String(),
+		validator: rangeValidator(resp.Header),
+		attempts:  attempts,
+		body:      resp.Body,
+	}
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	for {
+		b.mu.Lock()
+		body := b.body
+		b.mu.Unlock()
+
+		n, err := body.Read(p)
+		b.offset += int64(n)
+		if err == nil || errors.Is(err, io.EOF) || b.ctx.Err() != nil {
+			return n, err
+		}
+		if rerr := b.resume(err); rerr != nil {
+			return n, fmt.Errorf("%w (not resumed: %v)", err, rerr)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume replaces the dropped body with a ranged request for the rest
+func (b *resumableBody) resume(cause error) error {
+	if b.attempts <= 0 {
+		return fmt.Errorf("no resume attempts left")
+	}
+	var lastErr error
+	for b.attempts > 0 {
+		b.attempts--
+		log.Printf("Audio download dropped at byte %d (%v); resuming, %d attempts left", b.offset, cause, b.attempts)
+
+		select {
+		case <-b.ctx.Done():
+			return b.ctx.Err()
+		case <-time.After(resumeRetryDelay):
+		}
+
+		req, err := http.NewRequestWithContext(b.ctx, http.MethodGet, b.url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", b.offset))
+		if b.validator != "" {
+			req.Header.Set("If-Range", b.validator)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusPartialContent || rangeStart(resp.Header.Get("Content-Range")) != b.offset {
+			// The server ignored the range or the file changed; the rest
+			// can't be spliced on
+			resp.Body.Close()
+			return fmt.Errorf("server did not resume at byte %d: %s", b.offset, resp.Status)
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.closed {
+			resp.Body.Close()
+			return fmt.Errorf("download closed")
+		}
+		b.body.Close()
+		b.body = resp.Body
+		return nil
+	}
+	return fmt.Errorf("resume attempts used up: %w", lastErr)
+}
+
+func (b *resumableBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return b.body.Close()
+}
+
+// rangeValidator returns the header value that identifies this version of
+// the file for If-Range: a strong ETag, or else Last-Modified
+func rangeValidator(h http.Header) string {
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return h.Get("Last-Modified")
+}
+
+// rangeStart returns the first byte of a "bytes first-last/size"
+// Content-Range, or -1
+func rangeStart(contentRange string) int64 {
+	spec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return -1
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(first), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
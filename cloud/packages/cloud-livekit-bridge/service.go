@@ -2,49 +2,73 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/logger"
 	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/playerr"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/registry"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/g711track"
 	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/pion/webrtc/v4"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// Named tracks for the well-known track IDs; any other ID maps to track_<id>
+var namedTracks = map[int32]string{
+	0: "speaker",
+	1: "app_audio",
+	2: "tts",
+}
+
 // trackIDToName converts track ID to track name
 func trackIDToName(trackID int32) string {
-	switch trackID {
-	case 0:
-		return "speaker"
-	case 1:
-		return "app_audio"
-	case 2:
-		return "tts"
-	default:
-		return fmt.Sprintf("track_%d", trackID)
+	if name, ok := namedTracks[trackID]; ok {
+		return name
 	}
+	return fmt.Sprintf("track_%d", trackID)
 }
 
+// errNegativeTrackID rejects track IDs that have no track name
+var errNegativeTrackID = errors.New("track_id must be >= 0")
+
 // LiveKitBridgeService implements the gRPC service
 type LiveKitBridgeService struct {
 	pb.UnimplementedLiveKitBridgeServer
 
-	sessions sync.Map // userId -> *RoomSession
+	sessions *registry.Registry[*RoomSession]
 	config   *Config
 	bsLogger *logger.BetterStackLogger
+	drops    *dropMeter
+	cache    *audioCache // nil when PLAYBACK_CACHE_MB is 0
 	mu       sync.RWMutex
 }
 
 // NewLiveKitBridgeService creates a new service instance
 func NewLiveKitBridgeService(config *Config, bsLogger *logger.BetterStackLogger) *LiveKitBridgeService {
-	return &LiveKitBridgeService{
+	s := &LiveKitBridgeService{
+		sessions: registry.New[*RoomSession](),
 		config:   config,
 		bsLogger: bsLogger,
+		drops:    newDropMeter(config.HealthDropWindow),
+	}
+	if config.PlaybackCacheBytes > 0 {
+		cache, err := newAudioCache(config.PlaybackCacheDir, config.PlaybackCacheBytes)
+		if err != nil {
+			log.Printf("Playback cache disabled: %v", err)
+		} else {
+			s.cache = cache
+		}
 	}
+	return s
 }
 
 // JoinRoom handles room join requests
@@ -57,10 +81,11 @@ func (s *LiveKitBridgeService) JoinRoom(
 		"user_id":     req.UserId,
 		"room_name":   req.RoomName,
 		"livekit_url": req.LivekitUrl,
+		"codec":       req.Codec.String(),
 	})
 
 	// Check if session already exists
-	if _, exists := s.sessions.Load(req.UserId); exists {
+	if _, exists := s.sessions.Get(req.UserId); exists {
 		s.bsLogger.LogWarn("Session already exists for user", map[string]interface{}{
 			"user_id": req.UserId,
 		})
@@ -71,87 +96,139 @@ func (s *LiveKitBridgeService) JoinRoom(
 	}
 
 	// Create new session
-	session := NewRoomSession(req.UserId)
+	session := NewRoomSession(req.UserId, func() ProcessorChain { return newProcessorChain(s.config) })
+	session.clipLevel = s.config.ClipLevel
+	session.maxAhead = s.config.MaxTrackAhead
+	session.dropWhenAhead = s.config.DropWhenAhead
+	session.crossfadeSamples = trackSampleRate * s.config.CrossfadeMs / 1000
+	session.resampleQuality = s.config.ResampleQuality
+	switch req.Codec {
+	case pb.JoinRoomRequest_PCMU:
+		session.codec = g711track.PCMU
+	case pb.JoinRoomRequest_PCMA:
+		session.codec = g711track.PCMA
+	}
+	if s.config.DuckDB > 0 {
+		session.ducker = newDucker(s.config.DuckDB, s.config.DuckAttack, s.config.DuckRelease)
+	}
 
 	// Setup callbacks for LiveKit room
 	var receivedPackets int64
 	var droppedPackets int64
 
-	roomCallback := &lksdk.RoomCallback{
-		ParticipantCallback: lksdk.ParticipantCallback{
-			OnDataPacket: func(packet lksdk.DataPacket, params lksdk.DataReceiveParams) {
-				// Only process packets from target identity if specified
-				if req.TargetIdentity != "" && params.SenderIdentity != req.TargetIdentity {
-					return
-				}
+	participantCallback := lksdk.ParticipantCallback{
+		OnDataPacket: func(packet lksdk.DataPacket, params lksdk.DataReceiveParams) {
+			// Levels cover every sender, whatever the audio target
+			session.meterDataPacket(params.SenderIdentity, packet)
 
-				// Extract audio data from packet
-				userPacket, ok := packet.(*lksdk.UserDataPacket)
-				if !ok || len(userPacket.Payload) == 0 {
-					return
-				}
+			// Only process packets from target identity if specified
+			if req.TargetIdentity != "" && params.SenderIdentity != req.TargetIdentity {
+				return
+			}
+
+			// Extract audio data from packet
+			userPacket, ok := packet.(*lksdk.UserDataPacket)
+			if !ok || len(userPacket.Payload) == 0 {
+				return
+			}
 
-				receivedPackets++
+			receivedPackets++
+			session.markPacket()
 
-				// Match old bridge behavior exactly
-				pcmData := userPacket.Payload
-				if len(pcmData)%2 == 1 {
-					pcmData = pcmData[1:]
-				}
-				if len(pcmData)%2 == 1 {
-					pcmData = pcmData[:len(pcmData)-1]
+			// Match old bridge behavior exactly
+			pcmData := userPacket.Payload
+			if len(pcmData)%2 == 1 {
+				pcmData = pcmData[1:]
+			}
+			if len(pcmData)%2 == 1 {
+				pcmData = pcmData[:len(pcmData)-1]
+			}
+			if len(pcmData) == 0 {
+				return
+			}
+
+			// Send to channel (non-blocking)
+			select {
+			case session.audioFromLiveKit <- pcmData:
+				s.drops.add(false)
+				// Log periodically to show audio is flowing
+				if receivedPackets%100 == 0 {
+					s.bsLogger.LogDebug("Audio flowing from LiveKit", map[string]interface{}{
+						"user_id":     req.UserId,
+						"received":    receivedPackets,
+						"dropped":     droppedPackets,
+						"channel_len": len(session.audioFromLiveKit),
+						"room_name":   req.RoomName,
+					})
+					log.Printf("Audio flowing for %s: received=%d, dropped=%d, channelLen=%d",
+						req.UserId, receivedPackets, droppedPackets, len(session.audioFromLiveKit))
 				}
-				if len(pcmData) == 0 {
-					return
+			default:
+				// Drop frame if channel full (backpressure)
+				droppedPackets++
+				s.drops.add(true)
+				if droppedPackets%50 == 0 {
+					s.bsLogger.LogWarn("Dropping audio frames", map[string]interface{}{
+						"user_id":       req.UserId,
+						"total_dropped": droppedPackets,
+						"channel_full":  len(session.audioFromLiveKit),
+						"room_name":     req.RoomName,
+					})
+					log.Printf("Dropping audio frames for %s: total_dropped=%d, channel_full=%d",
+						req.UserId, droppedPackets, len(session.audioFromLiveKit))
 				}
+			}
+		},
+		// Remote tracks are only downloaded while SubscribeAudio streams want them
+		OnTrackPublished: func(pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+			session.subscribePublication(pub, rp)
+		},
+		OnTrackSubscribed: func(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+			session.attachDecoder(track, pub, rp)
+		},
+		OnTrackUnsubscribed: func(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+			session.detachDecoder(track, pub, rp)
+		},
+	}
 
-				// Send to channel (non-blocking)
-				select {
-				case session.audioFromLiveKit <- pcmData:
-					// Log periodically to show audio is flowing
-					if receivedPackets%100 == 0 {
-						s.bsLogger.LogDebug("Audio flowing from LiveKit", map[string]interface{}{
-							"user_id":     req.UserId,
-							"received":    receivedPackets,
-							"dropped":     droppedPackets,
-							"channel_len": len(session.audioFromLiveKit),
-							"room_name":   req.RoomName,
-						})
-						log.Printf("Audio flowing for %s: received=%d, dropped=%d, channelLen=%d",
-							req.UserId, receivedPackets, droppedPackets, len(session.audioFromLiveKit))
-					}
-				default:
-					// Drop frame if channel full (backpressure)
-					droppedPackets++
-					if droppedPackets%50 == 0 {
-						s.bsLogger.LogWarn("Dropping audio frames", map[string]interface{}{
-							"user_id":       req.UserId,
-							"total_dropped": droppedPackets,
-							"channel_full":  len(session.audioFromLiveKit),
-							"room_name":     req.RoomName,
-						})
-						log.Printf("Dropping audio frames for %s: total_dropped=%d, channel_full=%d",
-							req.UserId, droppedPackets, len(session.audioFromLiveKit))
-					}
+	// connect joins the room with the stored token, refreshed if it is about
+	// to expire. Each connection gets its own callback so a late
+	// OnDisconnected from a replaced room is ignored.
+	token := newRoomToken(req.Token, s.config.LiveKitAPIKey, s.config.LiveKitAPISecret)
+	var connect func() (*lksdk.Room, error)
+	connect = func() (*lksdk.Room, error) {
+		raw, err := token.get(time.Now())
+		if err != nil {
+			return nil, err
+		}
+		var self atomic.Pointer[lksdk.Room]
+		roomCallback := &lksdk.RoomCallback{
+			ParticipantCallback: participantCallback,
+			OnDisconnected: func() {
+				s.bsLogger.LogWarn("Disconnected from LiveKit room", map[string]interface{}{
+					"user_id":   req.UserId,
+					"room_name": req.RoomName,
+				})
+				log.Printf("Disconnected from LiveKit room: %s", req.RoomName)
+				if lost := self.Load(); lost != nil && s.config.ReconnectMaxAttempts > 0 {
+					session.spawn(func() { s.reconnectRoom(session, lost, connect) })
 				}
 			},
-		},
-		OnDisconnected: func() {
-			s.bsLogger.LogWarn("Disconnected from LiveKit room", map[string]interface{}{
-				"user_id":   req.UserId,
-				"room_name": req.RoomName,
-			})
-			log.Printf("Disconnected from LiveKit room: %s", req.RoomName)
-		},
+		}
+		room, err := lksdk.ConnectToRoomWithToken(
+			req.LivekitUrl,
+			raw,
+			roomCallback,
+			lksdk.WithAutoSubscribe(false),
+		)
+		if err == nil {
+			self.Store(room)
+		}
+		return room, err
 	}
 
 	// Connect to LiveKit room
-	room, err := lksdk.ConnectToRoomWithToken(
-		req.LivekitUrl,
-		req.Token,
-		roomCallback,
-		lksdk.WithAutoSubscribe(false),
-	)
+	room, err := connect()
 	if err != nil {
 		s.bsLogger.LogError("Failed to connect to LiveKit room", err, map[string]interface{}{
 			"user_id":     req.UserId,
@@ -164,13 +241,27 @@ func (s *LiveKitBridgeService) JoinRoom(
 		}, nil
 	}
 
+	session.mu.Lock()
 	session.room = room
+	session.mu.Unlock()
+
+	if s.config.AudioStallTimeout > 0 {
+		session.spawn(func() {
+			s.watchAudioFlow(session, connect)
+		})
+	}
 
 	// DON'T create track here - only create when actually playing audio
 	// This prevents static feedback loop (mobile hears empty track as static)
 
-	// Store session
-	s.sessions.Store(req.UserId, session)
+	// Store session, unless a concurrent JoinRoom for this user won the race
+	if !s.sessions.PutIfAbsent(req.UserId, session) {
+		session.Close()
+		return &pb.JoinRoomResponse{
+			Success: false,
+			Error:   "session already exists for this user",
+		}, nil
+	}
 
 	log.Printf("Successfully joined room: userId=%s, participantId=%s",
 		req.UserId, room.LocalParticipant.Identity())
@@ -199,7 +290,7 @@ func (s *LiveKitBridgeService) LeaveRoom(
 		"user_id": req.UserId,
 	})
 
-	sessionVal, ok := s.sessions.Load(req.UserId)
+	session, ok := s.sessions.Get(req.UserId)
 	if !ok {
 		return &pb.LeaveRoomResponse{
 			Success: false,
@@ -207,9 +298,8 @@ func (s *LiveKitBridgeService) LeaveRoom(
 		}, nil
 	}
 
-	session := sessionVal.(*RoomSession)
 	session.Close()
-	s.sessions.Delete(req.UserId)
+	s.sessions.DeleteIf(req.UserId, session)
 
 	log.Printf("Successfully left room: userId=%s", req.UserId)
 
@@ -236,25 +326,36 @@ func (s *LiveKitBridgeService) StreamAudio(
 	log.Printf("StreamAudio started: userId=%s", userId)
 
 	// Get session
-	sessionVal, ok := s.sessions.Load(userId)
+	session, ok := s.sessions.Get(userId)
 	if !ok {
 		return status.Errorf(codes.NotFound, "session not found for user %s", userId)
 	}
-	session := sessionVal.(*RoomSession)
+	session.streamStarted()
+	defer session.streamEnded()
+
+	// Take over from any stream still attached for this user
+	streamCtx, detach := session.attachStream()
+	defer detach()
 
 	// Error channel for goroutine communication
 	errChan := make(chan error, 2)
 
 	// Goroutine 1: Receive from client → LiveKit
-	go func() {
+	session.spawn(func() {
 		defer log.Printf("StreamAudio receive goroutine ended: userId=%s", userId)
 
 		// Process first chunk with track ID
-		trackName := trackIDToName(firstChunk.TrackId)
-		if err := session.writeAudioToTrack(firstChunk.PcmData, trackName); err != nil {
-			errChan <- fmt.Errorf("failed to write first chunk: %w", err)
+		if firstChunk.TrackId < 0 {
+			errChan <- status.Error(codes.InvalidArgument, errNegativeTrackID.Error())
 			return
 		}
+		trackName := trackIDToName(firstChunk.TrackId)
+		if session.admitRealtime(trackName, len(firstChunk.PcmData)/2) {
+			if err := session.writeAudioToTrack(firstChunk.PcmData, trackName); err != nil {
+				errChan <- fmt.Errorf("failed to write first chunk: %w", err)
+				return
+			}
+		}
 
 		// Continue receiving
 		for {
@@ -266,18 +367,29 @@ func (s *LiveKitBridgeService) StreamAudio(
 				errChan <- fmt.Errorf("receive error: %w", err)
 				return
 			}
+			if streamCtx.Err() != nil {
+				// Superseded; the new stream owns the tracks now
+				return
+			}
 
 			// Convert track_id to track name
+			if chunk.TrackId < 0 {
+				errChan <- status.Error(codes.InvalidArgument, errNegativeTrackID.Error())
+				return
+			}
 			trackName := trackIDToName(chunk.TrackId)
+			if !session.admitRealtime(trackName, len(chunk.PcmData)/2) {
+				continue
+			}
 			if err := session.writeAudioToTrack(chunk.PcmData, trackName); err != nil {
 				errChan <- fmt.Errorf("failed to write audio: %w", err)
 				return
 			}
 		}
-	}()
+	})
 
 	// Goroutine 2: Send from LiveKit → client
-	go func() {
+	session.spawn(func() {
 		defer log.Printf("StreamAudio send goroutine ended: userId=%s", userId)
 
 		var sentPackets int64
@@ -285,6 +397,11 @@ func (s *LiveKitBridgeService) StreamAudio(
 
 		for {
 			select {
+			case st := <-session.statusEvents:
+				if err := stream.Send(&pb.AudioChunk{Status: st}); err != nil {
+					errChan <- fmt.Errorf("send error: %w", err)
+					return
+				}
 			case audioData, ok := <-session.audioFromLiveKit:
 				if !ok {
 					return
@@ -292,14 +409,14 @@ func (s *LiveKitBridgeService) StreamAudio(
 
 				// Send to client with timeout to prevent blocking forever
 				sendDone := make(chan error, 1)
-				go func() {
+				session.spawn(func() {
 					sendDone <- stream.Send(&pb.AudioChunk{
 						PcmData:     audioData,
 						SampleRate:  16000,
 						Channels:    1,
 						TimestampMs: 0,
 					})
-				}()
+				})
 
 				select {
 				case err := <-sendDone:
@@ -326,15 +443,15 @@ func (s *LiveKitBridgeService) StreamAudio(
 					log.Printf("StreamAudio send timeout for %s after 2s, client may be stuck", userId)
 					errChan <- fmt.Errorf("send timeout after 2s")
 					return
-				case <-session.ctx.Done():
+				case <-streamCtx.Done():
 					return
 				}
 
-			case <-session.ctx.Done():
+			case <-streamCtx.Done():
 				return
 			}
 		}
-	}()
+	})
 
 	// Wait for error or cancellation
 	select {
@@ -344,17 +461,28 @@ func (s *LiveKitBridgeService) StreamAudio(
 		})
 		log.Printf("StreamAudio error for userId=%s: %v", userId, err)
 
+		// A superseded stream failing must not tear down its successor's session
+		if streamCtx.Err() != nil {
+			return err
+		}
+
 		// CRITICAL: Clean up session on stream error
 		// This prevents zombie sessions and "channel full" errors after reconnection issues
 		s.bsLogger.LogWarn("Cleaning up session due to stream error", map[string]interface{}{
 			"user_id": userId,
 		})
 		log.Printf("Cleaning up session for %s due to stream error", userId)
-		session.Close()
-		s.sessions.Delete(userId)
+		s.sessions.DeleteIf(userId, session)
+		// Close asynchronously: the receive goroutine only exits once this
+		// handler returns and the stream context is cancelled
+		go session.Close()
 
 		return err
-	case <-session.ctx.Done():
+	case <-streamCtx.Done():
+		if session.ctx.Err() == nil {
+			log.Printf("StreamAudio superseded by a newer stream: userId=%s", userId)
+			return status.Error(codes.Aborted, "superseded by a newer StreamAudio stream for this user")
+		}
 		log.Printf("StreamAudio context done: userId=%s", userId)
 		return nil
 	}
@@ -367,11 +495,14 @@ func (s *LiveKitBridgeService) PlayAudio(
 ) error {
 	log.Printf("PlayAudio request: userId=%s, url=%s", req.UserId, req.AudioUrl)
 
-	sessionVal, ok := s.sessions.Load(req.UserId)
+	if req.TrackId < 0 {
+		return status.Error(codes.InvalidArgument, errNegativeTrackID.Error())
+	}
+
+	session, ok := s.sessions.Get(req.UserId)
 	if !ok {
 		return status.Errorf(codes.NotFound, "session not found for user %s", req.UserId)
 	}
-	session := sessionVal.(*RoomSession)
 
 	// Send STARTED event
 	if err := stream.Send(&pb.PlayAudioEvent{
@@ -386,31 +517,66 @@ func (s *LiveKitBridgeService) PlayAudio(
 
 	// Play audio file (implementation in playback.go)
 	duration, err := s.playAudioFile(req, session, stream, trackName)
+	if errors.Is(err, errPlaybackReplaced) {
+		// The track now belongs to the PlayAudio that replaced this one,
+		// so it is left open
+		return stream.Send(&pb.PlayAudioEvent{
+			Type:      pb.PlayAudioEvent_COMPLETED,
+			RequestId: req.RequestId,
+			Metadata:  map[string]string{"outcome": "replaced"},
+		})
+	}
+	if errors.Is(err, errEmptyAudio) {
+		// Nothing was played; report it as its own outcome rather than a failure
+		session.releaseTrack(trackName)
+		return stream.Send(&pb.PlayAudioEvent{
+			Type:      pb.PlayAudioEvent_COMPLETED,
+			RequestId: req.RequestId,
+			Metadata:  map[string]string{"outcome": "empty_audio"},
+		})
+	}
 	if err != nil {
 		// Send FAILED event
+		code := playerr.CodeOf(err)
 		stream.Send(&pb.PlayAudioEvent{
 			Type:      pb.PlayAudioEvent_FAILED,
 			RequestId: req.RequestId,
 			Error:     err.Error(),
+			Metadata: map[string]string{
+				"code":    string(code),
+				"message": code.Message(),
+			},
 		})
 
+		// Give the listener audible feedback instead of an abrupt cut,
+		// unless playback was cancelled on purpose
+		if !errors.Is(err, context.Canceled) {
+			s.playErrorFeedback(session, trackName)
+		}
+
 		// Close only this specific track on error
-		session.closeTrack(trackName)
+		session.releaseTrack(trackName)
 
 		return err
 	}
 
-	// Send COMPLETED event
+	// Send COMPLETED event, with how much of the clip hit the clip level
+	clips := session.clipStats(trackName)
 	if err := stream.Send(&pb.PlayAudioEvent{
 		Type:       pb.PlayAudioEvent_COMPLETED,
 		RequestId:  req.RequestId,
 		DurationMs: duration,
+		Metadata: map[string]string{
+			"outcome":         "played",
+			"clipped_samples": strconv.FormatInt(clips.Clipped, 10),
+			"clip_percent":    strconv.FormatFloat(clips.Percent(), 'f', 3, 64),
+		},
 	}); err != nil {
 		return err
 	}
 
 	// Close only this specific track after playback to prevent static feedback
-	session.closeTrack(trackName)
+	session.releaseTrack(trackName)
 
 	return nil
 }
@@ -422,7 +588,14 @@ func (s *LiveKitBridgeService) StopAudio(
 ) (*pb.StopAudioResponse, error) {
 	log.Printf("StopAudio request: userId=%s, trackId=%d", req.UserId, req.TrackId)
 
-	sessionVal, ok := s.sessions.Load(req.UserId)
+	if req.TrackId < 0 {
+		return &pb.StopAudioResponse{
+			Success: false,
+			Error:   errNegativeTrackID.Error(),
+		}, nil
+	}
+
+	session, ok := s.sessions.Get(req.UserId)
 	if !ok {
 		return &pb.StopAudioResponse{
 			Success: false,
@@ -430,14 +603,17 @@ func (s *LiveKitBridgeService) StopAudio(
 		}, nil
 	}
 
-	session := sessionVal.(*RoomSession)
-
 	// Convert track_id to track name
 	trackName := trackIDToName(req.TrackId)
 
 	// Cancel playback for this track
 	session.stopPlayback()
 
+	// Give the playback loop time to write its fade-out before closing
+	if s.config.StopFadeMs > 0 {
+		waitTimeout(&session.playbackWG, playbackDrainTimeout)
+	}
+
 	// Close only the specific track
 	session.closeTrack(trackName)
 
@@ -447,6 +623,124 @@ func (s *LiveKitBridgeService) StopAudio(
 	}, nil
 }
 
+// PauseAudio holds the current playback
+func (s *LiveKitBridgeService) PauseAudio(
+	ctx context.Context,
+	req *pb.PauseAudioRequest,
+) (*pb.PauseAudioResponse, error) {
+	log.Printf("PauseAudio request: userId=%s", req.UserId)
+
+	session, ok := s.sessions.Get(req.UserId)
+	if !ok {
+		return &pb.PauseAudioResponse{
+			Success: false,
+			Error:   "session not found",
+		}, nil
+	}
+
+	if err := session.pausePlayback(); err != nil {
+		return &pb.PauseAudioResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+	return &pb.PauseAudioResponse{Success: true}, nil
+}
+
+// ResumeAudio continues a playback held by PauseAudio
+func (s *LiveKitBridgeService) ResumeAudio(
+	ctx context.Context,
+	req *pb.ResumeAudioRequest,
+) (*pb.ResumeAudioResponse, error) {
+	log.Printf("ResumeAudio request: userId=%s", req.UserId)
+
+	session, ok := s.sessions.Get(req.UserId)
+	if !ok {
+		return &pb.ResumeAudioResponse{
+			Success: false,
+			Error:   "session not found",
+		}, nil
+	}
+
+	if err := session.resumePlayback(); err != nil {
+		return &pb.ResumeAudioResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+	return &pb.ResumeAudioResponse{Success: true}, nil
+}
+
+// SetTrackVolume persists a default volume for a track
+func (s *LiveKitBridgeService) SetTrackVolume(
+	ctx context.Context,
+	req *pb.SetTrackVolumeRequest,
+) (*pb.SetTrackVolumeResponse, error) {
+	log.Printf("SetTrackVolume request: userId=%s, trackId=%d, volume=%.2f", req.UserId, req.TrackId, req.Volume)
+
+	session, ok := s.sessions.Get(req.UserId)
+	if !ok {
+		return &pb.SetTrackVolumeResponse{
+			Success: false,
+			Error:   "session not found",
+		}, nil
+	}
+
+	if req.TrackId < 0 {
+		return &pb.SetTrackVolumeResponse{
+			Success: false,
+			Error:   errNegativeTrackID.Error(),
+		}, nil
+	}
+
+	if req.Volume < 0 {
+		return &pb.SetTrackVolumeResponse{
+			Success: false,
+			Error:   "volume must be >= 0",
+		}, nil
+	}
+
+	session.setTrackVolume(trackIDToName(req.TrackId), req.Volume)
+
+	return &pb.SetTrackVolumeResponse{Success: true}, nil
+}
+
+// SendControl publishes a data packet to the user's room
+func (s *LiveKitBridgeService) SendControl(
+	ctx context.Context,
+	req *pb.SendControlRequest,
+) (*pb.SendControlResponse, error) {
+	log.Printf("SendControl request: userId=%s, topic=%s, bytes=%d, reliable=%v", req.UserId, req.Topic, len(req.Payload), req.Reliable)
+
+	session, ok := s.sessions.Get(req.UserId)
+	if !ok {
+		return &pb.SendControlResponse{
+			Success: false,
+			Error:   "session not found",
+		}, nil
+	}
+
+	if err := session.publishData(req.Topic, req.Payload, req.Reliable, req.DestinationIdentities); err != nil {
+		s.bsLogger.LogWarn("SendControl failed", map[string]interface{}{
+			"user_id": req.UserId,
+			"topic":   req.Topic,
+			"error":   err.Error(),
+		})
+		return &pb.SendControlResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	return &pb.SendControlResponse{Success: true}, nil
+}
+
+// Degraded reports whether the aggregate drop rate is over
+// HEALTH_MAX_DROP_RATE for the current window
+func (s *LiveKitBridgeService) Degraded() bool {
+	return s.drops.degraded(s.config.HealthMaxDropRate)
+}
+
 // HealthCheck handles health check requests
 func (s *LiveKitBridgeService) HealthCheck(
 	ctx context.Context,
@@ -455,28 +749,39 @@ func (s *LiveKitBridgeService) HealthCheck(
 	var activeSessions int32
 	var activeStreams int32
 
-	s.sessions.Range(func(key, value interface{}) bool {
+	s.sessions.Each(func(userId string, session *RoomSession) bool {
 		activeSessions++
-		session := value.(*RoomSession)
-		if session.room != nil {
+		if session.currentRoom() != nil {
 			activeStreams++
 		}
 		return true
 	})
 
+	sessions := s.sessions.Stats()
+
+	// Route traffic away while we're dropping too much inbound audio
+	serving := pb.HealthCheckResponse_SERVING
+	if s.Degraded() {
+		serving = pb.HealthCheckResponse_NOT_SERVING
+	}
+
 	return &pb.HealthCheckResponse{
-		Status:         pb.HealthCheckResponse_SERVING,
+		Status:         serving,
 		ActiveSessions: activeSessions,
 		ActiveStreams:  activeStreams,
 		UptimeSeconds:  0, // Could track uptime if needed
+		Metadata: map[string]string{
+			"peak_sessions":  strconv.Itoa(sessions.Peak),
+			"total_sessions": strconv.FormatInt(sessions.Created, 10),
+		},
 	}, nil
 }
 
 // getSession is a helper to safely get a session
 func (s *LiveKitBridgeService) getSession(userId string) (*RoomSession, error) {
-	sessionVal, ok := s.sessions.Load(userId)
+	session, ok := s.sessions.Get(userId)
 	if !ok {
 		return nil, fmt.Errorf("session not found for user %s", userId)
 	}
-	return sessionVal.(*RoomSession), nil
+	return session, nil
 }
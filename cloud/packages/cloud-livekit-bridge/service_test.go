@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/g711track"
+)
+
+// trackNameToID is the inverse of trackIDToName. ok is false for names
+// trackIDToName never produces.
+func trackNameToID(name string) (int32, bool) {
+	for id, n := range namedTracks {
+		if n == name {
+			return id, true
+		}
+	}
+	var id int32
+	if _, err := fmt.Sscanf(name, "track_%d", &id); err != nil || id < 0 || trackIDToName(id) != name {
+		return 0, false
+	}
+	return id, true
+}
+
+func TestTrackIDToName(t *testing.T) {
+	tests := []struct {
+		id   int32
+		name string
+	}{
+		{0, "speaker"},
+		{1, "app_audio"},
+		{2, "tts"},
+		{3, "track_3"},
+		{42, "track_42"},
+		{2147483647, "track_2147483647"},
+	}
+	for _, tt := range tests {
+		if got := trackIDToName(tt.id); got != tt.name {
+			t.Errorf("trackIDToName(%d) = %q, want %q", tt.id, got, tt.name)
+		}
+		if id, ok := trackNameToID(tt.name); !ok || id != tt.id {
+			t.Errorf("trackNameToID(%q) = %d, %v, want %d", tt.name, id, ok, tt.id)
+		}
+	}
+
+	// Every ID maps to its own name, so no two tracks can share one
+	seen := map[string]int32{}
+	for id := int32(0); id < 100; id++ {
+		name := trackIDToName(id)
+		if other, dup := seen[name]; dup {
+			t.Errorf("tracks %d and %d are both named %q", other, id, name)
+		}
+		seen[name] = id
+	}
+
+	// Names trackIDToName never produces
+	for _, name := range []string{"", "Speaker", "track_", "track_-1", "track_01", "track_0", "track_2", "track_3x", "mic"} {
+		if id, ok := trackNameToID(name); ok {
+			t.Errorf("trackNameToID(%q) = %d, want not ok", name, id)
+		}
+	}
+}
+
+func TestNewTrackG711(t *testing.T) {
+	for _, codec := range []string{g711track.PCMU, g711track.PCMA} {
+		s := NewRoomSession("u", nil)
+		s.codec = codec
+		track, err := s.newTrack()
+		if err != nil {
+			t.Fatalf("codec %q: %v", codec, err)
+		}
+		if _, ok := track.(*g711track.Track); !ok {
+			t.Errorf("codec %q: got %T", codec, track)
+		}
+		track.Close()
+		s.Close()
+	}
+}
@@ -2,85 +2,205 @@ package main
 
 import (
 	"context"
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
-
+	"sync/atomic"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil/retry"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/g711track"
+	"github.com/livekit/media-sdk"
 	lksdk "github.com/livekit/server-sdk-go/v2"
 	lkmedia "github.com/livekit/server-sdk-go/v2/pkg/media"
+	"github.com/pion/webrtc/v4"
 )
 
+// trackSampleRate is the rate of every published track. Playback, ducking
+// and crossfades all work at this rate.
+const trackSampleRate = 16000
+
+// localTrack is a published track: PCMLocalTrack for Opus or
+// g711track.Track for G.711. Both take 16kHz mono PCM.
+type localTrack interface {
+	webrtc.TrackLocal
+	WriteSample(chunk media.PCM16Sample) error
+	Close()
+}
+
 // RoomSession manages a single user's LiveKit room connection
 type RoomSession struct {
 	userId           string
 	room             *lksdk.Room
-	publishTrack     *lkmedia.PCMLocalTrack // Deprecated: use tracks map
-	tracks           map[string]*lkmedia.PCMLocalTrack
+	publishTrack     localTrack // Deprecated: use tracks map
+	tracks           map[string]localTrack
+	codec            string                        // publish codec: "" (Opus), g711track.PCMU or g711track.PCMA
+	processors       map[string]ProcessorChain     // per-track, since processors may hold state
+	lastFrames       map[string][]int16            // last frame written per track, for fade-out
+	trackVolumes     map[string]float32            // SetTrackVolume defaults; outlive the track itself
+	clips            map[string]*audio.ClipCounter // clipped samples written per track
+	clipLevel        int
+	clocks           map[string]*trackClock // StreamAudio real-time accounting per track
+	maxAhead         time.Duration
+	dropWhenAhead    bool
+	ducker           *ducker            // ducks speaker/app_audio under tts; nil = off
+	lastPacket       atomic.Int64       // unix nanos of the last inbound packet
+	activeStreams    atomic.Int32       // open StreamAudio calls
+	streamCancel     context.CancelFunc // the StreamAudio call that owns the session's audio
+	streamSeq        uint64
+	statusEvents     chan string // connection status for the attached StreamAudio stream
+	reconnecting     atomic.Bool
+	subscribers      map[*audioSubscriber]struct{}      // open SubscribeAudio streams
+	decoders         map[string]*lkmedia.PCMRemoteTrack // remote audio being decoded, by track SID
+	levelMeters      map[*audio.LevelMeter]struct{}     // open StreamAudioLevels streams
+	newProcessors    func() ProcessorChain
 	audioFromLiveKit chan []byte
 	ctx              context.Context
 	cancel           context.CancelFunc
 	closeOnce        sync.Once
-	playbackCancel   context.CancelFunc
+	playbackCancel   context.CancelCauseFunc
+	playbackPacer    *audio.Pacer     // paces the current playback; PauseAudio holds it
+	playbackTrack    string           // track the current playback writes to
+	crossfadeSamples int              // fade when a PlayAudio replaces another; 0 = cut
+	resampleQuality  resample.Quality // filter for G.711 tracks' 16k -> 8kHz
+	playbackWG       sync.WaitGroup   // in-flight playAudioFile calls
+	wg               sync.WaitGroup   // goroutines started via spawn
+	crossfades       map[string]*crossfade
+	closing          bool
+	publishMu        sync.Mutex // serializes track publishes; taken before mu
 	mu               sync.RWMutex
 }
 
-// NewRoomSession creates a new room session
-func NewRoomSession(userId string) *RoomSession {
+// playbackDrainTimeout bounds how long Close waits for playback to stop
+const playbackDrainTimeout = 2 * time.Second
+
+// Publish attempts against LiveKit before a failure is treated as persistent
+const (
+	publishAttempts   = 3
+	publishRetryDelay = 100 * time.Millisecond
+)
+
+// goroutineDrainTimeout bounds how long Close waits for spawned goroutines
+const goroutineDrainTimeout = 2 * time.Second
+
+// NewRoomSession creates a new room session. newProcessors builds the
+// processing chain for each track; it may be nil.
+func NewRoomSession(userId string, newProcessors func() ProcessorChain) *RoomSession {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &RoomSession{
 		userId:           userId,
-		tracks:           make(map[string]*lkmedia.PCMLocalTrack),
+		tracks:           make(map[string]localTrack),
+		processors:       make(map[string]ProcessorChain),
+		lastFrames:       make(map[string][]int16),
+		trackVolumes:     make(map[string]float32),
+		clips:            make(map[string]*audio.ClipCounter),
+		clocks:           make(map[string]*trackClock),
+		crossfades:       make(map[string]*crossfade),
+		clipLevel:        32767,
+		newProcessors:    newProcessors,
 		audioFromLiveKit: make(chan []byte, 200), // Increased buffer for bursty audio
+		statusEvents:     make(chan string, 4),
+		subscribers:      make(map[*audioSubscriber]struct{}),
+		decoders:         make(map[string]*lkmedia.PCMRemoteTrack),
+		levelMeters:      make(map[*audio.LevelMeter]struct{}),
 		ctx:              ctx,
 		cancel:           cancel,
 	}
 }
 
 // createPublishTrack creates and publishes an audio track (deprecated, kept for compatibility)
-func (s *RoomSession) createPublishTrack() (*lkmedia.PCMLocalTrack, error) {
+func (s *RoomSession) createPublishTrack() (localTrack, error) {
 	// Use "speaker" as default track name
 	return s.getOrCreateTrack("speaker")
 }
 
 // getOrCreateTrack gets or creates a named audio track
-func (s *RoomSession) getOrCreateTrack(trackName string) (*lkmedia.PCMLocalTrack, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if s.room == nil {
-		return nil, fmt.Errorf("room not connected")
-	}
-
+func (s *RoomSession) getOrCreateTrack(trackName string) (localTrack, error) {
 	// Default to "speaker" if not specified
 	if trackName == "" {
 		trackName = "speaker"
 	}
 
 	// Return existing track if already created
-	if track, exists := s.tracks[trackName]; exists {
+	s.mu.RLock()
+	room, track := s.room, s.tracks[trackName]
+	s.mu.RUnlock()
+	if room == nil {
+		return nil, fmt.Errorf("room not connected")
+	}
+	if track != nil {
 		return track, nil
 	}
 
-	// Create new PCM track (16kHz, mono)
-	track, err := lkmedia.NewPCMLocalTrack(16000, 1, nil)
+	// Publishing (with retries) can take a while, so it runs under
+	// publishMu rather than mu: writes to other tracks and the session's
+	// RPCs carry on meanwhile, and two writers can't publish the same name
+	s.publishMu.Lock()
+	defer s.publishMu.Unlock()
+	s.mu.RLock()
+	room, track = s.room, s.tracks[trackName]
+	s.mu.RUnlock()
+	if room == nil {
+		return nil, fmt.Errorf("room not connected")
+	}
+	if track != nil {
+		return track, nil // published while we waited
+	}
+
+	track, err := s.newTrack()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create PCM track: %w", err)
 	}
 
-	// Publish track to room with specified name
-	if _, err := s.room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{
-		Name: trackName,
-	}); err != nil {
+	// Publish track to room with specified name, retrying transient failures
+	err = retry.Transient(s.ctx, publishAttempts, publishRetryDelay, func() error {
+		_, err := room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{
+			Name: trackName,
+		})
+		if err != nil {
+			log.Printf("PublishTrack '%s' failed for user %s: %v", trackName, s.userId, err)
+		}
+		return err
+	})
+	if err != nil {
 		track.Close()
 		return nil, fmt.Errorf("failed to publish track: %w", err)
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The session may have closed or changed rooms during the publish
+	if s.room != room || s.closing {
+		track.Close()
+		return nil, fmt.Errorf("room not connected")
+	}
 	s.tracks[trackName] = track
-	log.Printf("Published PCM track '%s' for user %s", trackName, s.userId)
+	if s.newProcessors != nil {
+		s.processors[trackName] = s.newProcessors()
+	}
+	log.Printf("Published PCM track '%s' for user %s (codec=%s)", trackName, s.userId, s.codecName())
 	return track, nil
 }
 
+// newTrack creates a 16kHz mono track in the session's publish codec
+func (s *RoomSession) newTrack() (localTrack, error) {
+	if s.codec != "" {
+		return g711track.New(s.codec, trackSampleRate, s.resampleQuality)
+	}
+	return lkmedia.NewPCMLocalTrack(trackSampleRate, 1, nil)
+}
+
+// codecName is the publish codec with the Opus default filled in, for logs
+func (s *RoomSession) codecName() string {
+	if s.codec == "" {
+		return "opus"
+	}
+	return s.codec
+}
+
 // writeAudioToLiveKit writes PCM audio data to the LiveKit track
 func (s *RoomSession) writeAudioToLiveKit(pcmData []byte) error {
 	return s.writeAudioToTrack(pcmData, "speaker")
@@ -107,12 +227,21 @@ func (s *RoomSession) writeAudioToTrack(pcmData []byte, trackName string) error
 	}
 
 	// Convert bytes to int16 samples
-	samples := bytesToInt16(pcmData)
+	samples := audio.BytesToInt16(pcmData)
+
+	s.mu.RLock()
+	chain := s.processors[trackName]
+	s.mu.RUnlock()
+
+	if trackName == "tts" {
+		s.ducker.noteTTS(len(samples), time.Now())
+	}
 
 	// Write in 10ms chunks (160 samples at 16kHz)
-	sampleRate := 16000
-	frameSamples := sampleRate / 100 // 10ms chunks
+	frameSamples := trackSampleRate / 100
 
+	var last []int16
+	var clips audio.ClipCounter
 	for offset := 0; offset < len(samples); offset += frameSamples {
 		end := offset + frameSamples
 		if end > len(samples) {
@@ -120,14 +249,189 @@ func (s *RoomSession) writeAudioToTrack(pcmData []byte, trackName string) error
 		}
 
 		frame := samples[offset:end]
+		s.ducker.apply(trackName, frame, time.Now())
+		frame = chain.Process(frame)
+		if len(frame) == 0 {
+			continue
+		}
 		if err := track.WriteSample(frame); err != nil {
 			return fmt.Errorf("failed to write sample: %w", err)
 		}
+		last = frame
+		clips.Add(frame, s.clipLevel)
+	}
+
+	if last != nil {
+		s.mu.Lock()
+		s.lastFrames[trackName] = append(s.lastFrames[trackName][:0], last...)
+		total := s.clips[trackName]
+		if total == nil {
+			total = &audio.ClipCounter{}
+			s.clips[trackName] = total
+		}
+		total.Merge(clips)
+		s.mu.Unlock()
 	}
 
 	return nil
 }
 
+// writePlayback writes PlayAudio audio from the playback paced by pacer.
+// While one playback replaces another on the track, the old one's audio is
+// taken for the crossfade and the new one's is mixed over it.
+func (s *RoomSession) writePlayback(pacer *audio.Pacer, pcmData []byte, trackName string) error {
+	s.mu.RLock()
+	var chain []*crossfade
+	for xf := s.crossfades[trackName]; xf != nil; xf = xf.prev {
+		chain = append(chain, xf)
+	}
+	s.mu.RUnlock()
+
+	// Oldest fade first: a playback being faded in is mixed over the one
+	// it replaced before a newer fade takes its audio
+	for i := len(chain) - 1; i >= 0; i-- {
+		xf := chain[i]
+		switch pacer {
+		case xf.out:
+			if xf.take(audio.BytesToInt16(pcmData)) {
+				return nil
+			}
+		case xf.in:
+			samples := audio.BytesToInt16(pcmData)
+			xf.mix(samples)
+			pcmData = audio.Int16ToBytes(samples)
+		}
+	}
+	return s.writeAudioToTrack(pcmData, trackName)
+}
+
+// setTrackVolume persists the default volume for a track
+func (s *RoomSession) setTrackVolume(trackName string, volume float32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trackVolumes[trackName] = volume
+}
+
+// playbackVolume resolves the gain for a playback: the request's volume if
+// set, otherwise the track default, otherwise unity
+func (s *RoomSession) playbackVolume(trackName string, requested float32) float64 {
+	if requested > 0 {
+		return float64(requested)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if v, ok := s.trackVolumes[trackName]; ok {
+		return float64(v)
+	}
+	return 1.0
+}
+
+// fadeOut repeats the last frame written to trackName while ramping it down
+// to silence over ms, so the track doesn't end on an abrupt cut
+func (s *RoomSession) fadeOut(trackName string, ms int) error {
+	s.mu.RLock()
+	last := append([]int16(nil), s.lastFrames[trackName]...)
+	closing := s.closing
+	s.mu.RUnlock()
+	if ms <= 0 || closing || len(last) == 0 {
+		return nil
+	}
+
+	n := trackSampleRate * ms / 1000
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(float64(last[i%len(last)]) * float64(n-i) / float64(n))
+	}
+	return s.writeAudioToTrack(audio.Int16ToBytes(out), trackName)
+}
+
+// clipStats returns the clipping tally for samples written to trackName
+// since the latest playback on it started, or since the track was created
+func (s *RoomSession) clipStats(trackName string) audio.ClipCounter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if c := s.clips[trackName]; c != nil {
+		return *c
+	}
+	return audio.ClipCounter{}
+}
+
+// publishData sends payload as a data packet on topic. Transient failures
+// are retried like track publishes.
+func (s *RoomSession) publishData(topic string, payload []byte, reliable bool, destinations []string) error {
+	s.mu.RLock()
+	room := s.room
+	s.mu.RUnlock()
+	if room == nil {
+		return fmt.Errorf("room not connected")
+	}
+
+	opts := []lksdk.DataPublishOption{lksdk.WithDataPublishReliable(reliable)}
+	if topic != "" {
+		opts = append(opts, lksdk.WithDataPublishTopic(topic))
+	}
+	if len(destinations) > 0 {
+		opts = append(opts, lksdk.WithDataPublishDestination(destinations))
+	}
+	return retry.Transient(s.ctx, publishAttempts, publishRetryDelay, func() error {
+		return room.LocalParticipant.PublishData(payload, opts...)
+	})
+}
+
+// trackClock tracks how far a track's writes have run ahead of real time
+type trackClock struct {
+	start   time.Time
+	samples int64
+	dropped int64
+}
+
+// admitRealtime accounts for n samples about to be written to trackName by
+// StreamAudio. When the track is more than maxAhead ahead of real time the
+// frames are dropped (false) or the caller is held until it's back within
+// the margin, so a client writing faster than real time can't build up
+// latency in the SDK's queue.
+func (s *RoomSession) admitRealtime(trackName string, n int) bool {
+	if s.maxAhead <= 0 || n <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	clock := s.clocks[trackName]
+	if clock == nil {
+		clock = &trackClock{}
+		s.clocks[trackName] = clock
+	}
+	now := time.Now()
+	// Re-anchor after an underrun so idle time isn't banked as credit
+	if clock.start.IsZero() || clock.playedUntil().Before(now) {
+		clock.start, clock.samples = now, 0
+	}
+	ahead := clock.playedUntil().Sub(now)
+	if ahead > s.maxAhead && s.dropWhenAhead {
+		clock.dropped++
+		if clock.dropped == 1 || clock.dropped%100 == 0 {
+			log.Printf("Track '%s' for user %s is %v ahead of real time; dropped %d chunks", trackName, s.userId, ahead, clock.dropped)
+		}
+		s.mu.Unlock()
+		return false
+	}
+	clock.samples += int64(n)
+	s.mu.Unlock()
+
+	if wait := ahead - s.maxAhead; wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-s.ctx.Done():
+		}
+	}
+	return true
+}
+
+// playedUntil is when the samples written so far finish playing at 16kHz
+func (c *trackClock) playedUntil() time.Time {
+	return c.start.Add(time.Duration(c.samples) * time.Second / trackSampleRate)
+}
+
 // closeTrack closes and unpublishes a specific track
 func (s *RoomSession) closeTrack(trackName string) {
 	s.mu.Lock()
@@ -136,19 +440,202 @@ func (s *RoomSession) closeTrack(trackName string) {
 	if track, exists := s.tracks[trackName]; exists {
 		track.Close()
 		delete(s.tracks, trackName)
+		delete(s.processors, trackName)
+		delete(s.lastFrames, trackName)
+		if c := s.clips[trackName]; c != nil && c.Clipped > 0 {
+			log.Printf("Track '%s' for user %s clipped %d of %d samples (%.2f%%)", trackName, s.userId, c.Clipped, c.Samples, c.Percent())
+		}
+		delete(s.clips, trackName)
+		delete(s.clocks, trackName)
 		log.Printf("Closed and unpublished track '%s' for user %s", trackName, s.userId)
 	}
 }
 
-// stopPlayback cancels any ongoing audio playback (does not close tracks)
+// releaseTrack closes trackName after a PlayAudio on it ends, unless a
+// newer playback has taken the track over
+func (s *RoomSession) releaseTrack(trackName string) {
+	s.mu.RLock()
+	taken := s.playbackPacer != nil && s.playbackTrack == trackName
+	s.mu.RUnlock()
+	if !taken {
+		s.closeTrack(trackName)
+	}
+}
+
+// attachStream makes the caller the session's only StreamAudio stream. A
+// stream already attached is cancelled so two clients never interleave
+// audio into the same tracks; the newest wins since it's usually a client
+// reconnecting before the old stream noticed it was dead. The returned
+// context ends on takeover or session close; detach must be called when
+// the stream ends.
+func (s *RoomSession) attachStream() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	s.mu.Lock()
+	if s.streamCancel != nil {
+		log.Printf("StreamAudio takeover for user %s: closing previous stream", s.userId)
+		s.streamCancel()
+	}
+	s.streamSeq++
+	seq := s.streamSeq
+	s.streamCancel = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		if s.streamSeq == seq {
+			s.streamCancel = nil
+		}
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// spawn runs fn in a goroutine tracked by the session so Close can wait for it
+func (s *RoomSession) spawn(fn func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+	}()
+}
+
+// waitTimeout waits for wg, giving up after timeout. Returns false on timeout.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// beginPlayback registers an in-flight playback so Close can wait for it,
+// and returns the pacer its writes go through. A playback already on
+// trackName is replaced: crossfaded into this one, or cancelled when
+// crossfading is off. Returns false if the session is already closing.
+func (s *RoomSession) beginPlayback(cancel context.CancelCauseFunc, trackName string) (*audio.Pacer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return nil, false
+	}
+	pacer := audio.NewPacer(trackSampleRate, playbackLead)
+	if s.playbackPacer != nil && s.playbackTrack == trackName {
+		if s.crossfadeSamples > 0 {
+			// A pending fade carries on under this one
+			xf := newCrossfade(s.playbackPacer, pacer, s.playbackCancel, s.crossfadeSamples)
+			xf.prev = s.crossfades[trackName]
+			s.crossfades[trackName] = xf
+		} else {
+			for xf := s.crossfades[trackName]; xf != nil; xf = xf.prev {
+				xf.finish()
+			}
+			delete(s.crossfades, trackName)
+			s.playbackCancel(errPlaybackReplaced)
+		}
+	}
+	s.playbackCancel = cancel
+	s.playbackPacer = pacer
+	s.playbackTrack = trackName
+	// The COMPLETED event reports clipping for this playback alone
+	delete(s.clips, trackName)
+	s.playbackWG.Add(1)
+	return pacer, true
+}
+
+// endPlayback marks an in-flight playback as finished
+func (s *RoomSession) endPlayback(pacer *audio.Pacer) {
+	s.mu.Lock()
+	if s.playbackPacer == pacer {
+		s.playbackCancel = nil
+		s.playbackPacer = nil
+	}
+	for trackName, head := range s.crossfades {
+		// Unlink the fade this playback was coming in on; it has nothing
+		// left to fade
+		var newer *crossfade
+		for xf := head; xf != nil; xf = xf.prev {
+			switch pacer {
+			case xf.out:
+				xf.outEnded()
+			case xf.in:
+				xf.finish()
+				switch {
+				case newer != nil:
+					newer.prev = xf.prev
+				case xf.prev != nil:
+					s.crossfades[trackName] = xf.prev
+				default:
+					delete(s.crossfades, trackName)
+				}
+				continue
+			}
+			newer = xf
+		}
+	}
+	s.mu.Unlock()
+	s.playbackWG.Done()
+}
+
+// stopPlayback cancels any ongoing audio playback, including one being
+// crossfaded out (does not close tracks)
 func (s *RoomSession) stopPlayback() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.playbackCancel != nil {
-		s.playbackCancel()
+		s.playbackCancel(nil)
 		s.playbackCancel = nil
 	}
+	s.playbackPacer = nil
+	for trackName, head := range s.crossfades {
+		for xf := head; xf != nil; xf = xf.prev {
+			xf.finish()
+		}
+		delete(s.crossfades, trackName)
+	}
+}
+
+var (
+	errNoPlayback    = errors.New("no playback in progress")
+	errAlreadyPaused = errors.New("playback is already paused")
+	errNotPaused     = errors.New("playback is not paused")
+)
+
+// pausePlayback holds the current playback where it is. Its fetch and
+// decoder stay open, so resumePlayback carries on from the same sample.
+func (s *RoomSession) pausePlayback() error {
+	s.mu.Lock()
+	pacer := s.playbackPacer
+	s.mu.Unlock()
+	if pacer == nil {
+		return errNoPlayback
+	}
+	if !pacer.Pause() {
+		return errAlreadyPaused
+	}
+	return nil
+}
+
+// resumePlayback releases a playback held by pausePlayback
+func (s *RoomSession) resumePlayback() error {
+	s.mu.Lock()
+	pacer := s.playbackPacer
+	s.mu.Unlock()
+	if pacer == nil {
+		return errNoPlayback
+	}
+	if !pacer.Resume() {
+		return errNotPaused
+	}
+	return nil
 }
 
 // Close cleans up all resources
@@ -156,21 +643,29 @@ func (s *RoomSession) Close() {
 	s.closeOnce.Do(func() {
 		log.Printf("Closing room session for user %s", s.userId)
 
+		// Refuse new playback from here on
+		s.mu.Lock()
+		s.closing = true
+		s.mu.Unlock()
+
 		// Cancel context (stops all goroutines)
 		s.cancel()
 
-		// Stop any playback
+		// Stop any playback and wait for it to let go of the tracks,
+		// so we never close a track while it is being written
 		s.stopPlayback()
+		if !waitTimeout(&s.playbackWG, playbackDrainTimeout) {
+			log.Printf("Timed out waiting for playback to stop for user %s", s.userId)
+		}
 
 		s.mu.Lock()
-		defer s.mu.Unlock()
 
 		// Close all tracks
 		for name, track := range s.tracks {
 			track.Close()
 			log.Printf("Closed track '%s' for user %s", name, s.userId)
 		}
-		s.tracks = make(map[string]*lkmedia.PCMLocalTrack)
+		s.tracks = make(map[string]localTrack)
 
 		// Close deprecated single track if still present
 		if s.publishTrack != nil {
@@ -178,6 +673,8 @@ func (s *RoomSession) Close() {
 			s.publishTrack = nil
 		}
 
+		decoders := s.takeDecodersLocked()
+
 		// Disconnect from room
 		if s.room != nil {
 			s.room.Disconnect()
@@ -186,30 +683,14 @@ func (s *RoomSession) Close() {
 
 		// Close audio channel
 		close(s.audioFromLiveKit)
+		s.mu.Unlock()
+		closeDecoders(decoders)
+
+		// Wait for stream goroutines to observe the cancellation
+		if !waitTimeout(&s.wg, goroutineDrainTimeout) {
+			log.Printf("Timed out waiting for session goroutines to exit for user %s", s.userId)
+		}
 
 		log.Printf("Closed room session for user %s", s.userId)
 	})
 }
-
-// bytesToInt16 converts byte slice to int16 samples (little-endian)
-func bytesToInt16(pcmData []byte) []int16 {
-	if len(pcmData)%2 == 1 {
-		pcmData = pcmData[:len(pcmData)-1]
-	}
-
-	samples := make([]int16, len(pcmData)/2)
-	for i := 0; i < len(samples); i++ {
-		samples[i] = int16(binary.LittleEndian.Uint16(pcmData[i*2:]))
-	}
-
-	return samples
-}
-
-// int16ToBytes converts int16 samples to byte slice (little-endian)
-func int16ToBytes(samples []int16) []byte {
-	pcmData := make([]byte, len(samples)*2)
-	for i, sample := range samples {
-		binary.LittleEndian.PutUint16(pcmData[i*2:], uint16(sample))
-	}
-	return pcmData
-}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/Mentra-Community/MentraOS/cloud/packages/cloud-livekit-bridge/proto"
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+	"github.com/livekit/media-sdk"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	lkmedia "github.com/livekit/server-sdk-go/v2/pkg/media"
+	"github.com/pion/webrtc/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Remote track audio for SubscribeAudio.
+//
+// The room is joined without auto-subscribe, so nothing is downloaded until
+// a SubscribeAudio stream opens. While at least one is open, remote audio
+// publications are subscribed and each track gets a PCMRemoteTrack decoder
+// (Opus -> 16kHz mono PCM16) whose frames are fanned out to the streams that
+// want that participant. Streams are also told when a track is added or
+// removed, or can't be decoded because it isn't Opus. When the last stream
+// ends the tracks are unsubscribed and the decoders closed.
+
+// subscribeSampleRate is the rate frames are decoded to
+const subscribeSampleRate = 16000
+
+// subscriberBuffer is how many frames a slow stream may fall behind before
+// its frames are dropped
+const subscriberBuffer = 100
+
+// audioSubscriber is one open SubscribeAudio stream
+type audioSubscriber struct {
+	identities map[string]bool // nil = everyone
+	trackSids  map[string]bool // nil = every track of those participants
+	noJitter   bool            // would rather skip the jitter buffer
+	frames     chan *pb.SubscribedAudioFrame
+	dropped    atomic.Int64
+}
+
+// wants reports whether the subscriber receives identity's audio
+func (a *audioSubscriber) wants(identity string) bool {
+	return a.identities == nil || a.identities[identity]
+}
+
+// wantsTrack reports whether the subscriber receives track sid from identity
+func (a *audioSubscriber) wantsTrack(identity, sid string) bool {
+	return a.wants(identity) && (a.trackSids == nil || a.trackSids[sid])
+}
+
+// remoteAudioWriter receives a decoder's PCM and fans it out
+type remoteAudioWriter struct {
+	session  *RoomSession
+	identity string
+	sid      string
+}
+
+func (w *remoteAudioWriter) WriteSample(sample media.PCM16Sample) error {
+	if len(sample) == 0 {
+		return nil
+	}
+	w.session.fanoutFrame(&pb.SubscribedAudioFrame{
+		PcmData:             audio.Int16ToBytes(sample),
+		SampleRate:          subscribeSampleRate,
+		ParticipantIdentity: w.identity,
+		TrackSid:            w.sid,
+		TimestampMs:         time.Now().UnixMilli(),
+	})
+	return nil
+}
+
+func (w *remoteAudioWriter) Close() error {
+	return nil
+}
+
+// addSubscriber registers a stream for identities and trackSids (empty =
+// no filter) and subscribes the room's matching audio tracks. noJitter asks
+// for its tracks to be decoded without the jitter buffer.
+func (s *RoomSession) addSubscriber(identities, trackSids []string, noJitter bool) *audioSubscriber {
+	sub := &audioSubscriber{
+		frames:     make(chan *pb.SubscribedAudioFrame, subscriberBuffer),
+		identities: stringSet(identities),
+		trackSids:  stringSet(trackSids),
+		noJitter:   noJitter,
+	}
+
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	room := s.room
+	s.mu.Unlock()
+
+	if room != nil {
+		s.subscribeRoomAudio(room)
+	}
+	return sub
+}
+
+// removeSubscriber unregisters a stream and unsubscribes the tracks no
+// remaining stream wants. The last one to go also closes the decoders.
+func (s *RoomSession) removeSubscriber(sub *audioSubscriber) {
+	s.mu.Lock()
+	delete(s.subscribers, sub)
+	room := s.room
+	var decoders []*lkmedia.PCMRemoteTrack
+	if len(s.subscribers) == 0 {
+		decoders = s.takeDecodersLocked()
+	}
+	s.mu.Unlock()
+
+	closeDecoders(decoders)
+	if room == nil {
+		return
+	}
+	for _, rp := range room.GetRemoteParticipants() {
+		for _, pub := range rp.TrackPublications() {
+			if remote, ok := pub.(*lksdk.RemoteTrackPublication); ok && remote.Kind() == lksdk.TrackKindAudio && remote.IsSubscribed() && !s.wantsTrack(rp.Identity(), remote.SID()) {
+				if err := remote.SetSubscribed(false); err != nil {
+					log.Printf("Unsubscribing track %s for user %s failed: %v", remote.SID(), s.userId, err)
+				}
+			}
+		}
+	}
+}
+
+// stringSet is values as a set, or nil if there are none
+func stringSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// wantsTrack reports whether any open stream wants track sid from identity
+func (s *RoomSession) wantsTrack(identity, sid string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subscribers {
+		if sub.wantsTrack(identity, sid) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleJitter reports whether track sid from identity should be decoded
+// through the jitter buffer: unless every stream that wants it asked to
+// skip it
+func (s *RoomSession) handleJitter(identity, sid string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subscribers {
+		if sub.wantsTrack(identity, sid) && !sub.noJitter {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribeRoomAudio subscribes every audio publication already in room
+// that an open stream wants
+func (s *RoomSession) subscribeRoomAudio(room *lksdk.Room) {
+	for _, rp := range room.GetRemoteParticipants() {
+		for _, pub := range rp.TrackPublications() {
+			if remote, ok := pub.(*lksdk.RemoteTrackPublication); ok {
+				s.subscribePublication(remote, rp)
+			}
+		}
+	}
+}
+
+// subscribePublication subscribes pub if it is audio an open stream wants.
+// Decoding starts once LiveKit reports the track subscribed.
+func (s *RoomSession) subscribePublication(pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+	if pub.Kind() != lksdk.TrackKindAudio || pub.IsSubscribed() || !s.wantsTrack(rp.Identity(), pub.SID()) {
+		return
+	}
+	if err := pub.SetSubscribed(true); err != nil {
+		log.Printf("Subscribing track %s from %s for user %s failed: %v", pub.SID(), rp.Identity(), s.userId, err)
+	}
+}
+
+// attachDecoder starts decoding a newly subscribed remote audio track
+func (s *RoomSession) attachDecoder(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+	if track.Kind() != webrtc.RTPCodecTypeAudio {
+		return
+	}
+	if !s.wantsTrack(rp.Identity(), pub.SID()) {
+		// The last stream ended while the subscription was in flight
+		if err := pub.SetSubscribed(false); err != nil {
+			log.Printf("Unsubscribing track %s for user %s failed: %v", pub.SID(), s.userId, err)
+		}
+		return
+	}
+
+	// PCMRemoteTrack only decodes Opus. Anything else (PCMU/PCMA from a
+	// SIP participant, say) is reported and dropped instead of being fed
+	// to the Opus decoder.
+	codec := track.Codec()
+	if !strings.EqualFold(codec.MimeType, webrtc.MimeTypeOpus) {
+		log.Printf("Track %s from %s for user %s is %s at %dHz; only Opus can be decoded", pub.SID(), rp.Identity(), s.userId, codec.MimeType, codec.ClockRate)
+		s.fanoutFrame(trackEvent(pb.SubscribedTrackEvent_UNSUPPORTED, rp.Identity(), pub.SID(), codec))
+		if err := pub.SetSubscribed(false); err != nil {
+			log.Printf("Unsubscribing track %s for user %s failed: %v", pub.SID(), s.userId, err)
+		}
+		return
+	}
+
+	writer := &remoteAudioWriter{session: s, identity: rp.Identity(), sid: pub.SID()}
+	jitter := s.handleJitter(rp.Identity(), pub.SID())
+	decoder, err := lkmedia.NewPCMRemoteTrack(track, writer,
+		lkmedia.WithTargetSampleRate(subscribeSampleRate),
+		lkmedia.WithTargetChannels(1),
+		lkmedia.WithHandleJitter(jitter),
+	)
+	if err != nil {
+		log.Printf("Cannot decode track %s from %s for user %s: %v", pub.SID(), rp.Identity(), s.userId, err)
+		return
+	}
+
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		decoder.Close()
+		return
+	}
+	prev := s.decoders[pub.SID()]
+	s.decoders[pub.SID()] = decoder
+	s.mu.Unlock()
+	if prev != nil {
+		prev.Close()
+	}
+	log.Printf("Decoding %s %dHz/%dch track %s from %s for user %s (jitter buffer=%v)", codec.MimeType, codec.ClockRate, codec.Channels, pub.SID(), rp.Identity(), s.userId, jitter)
+	s.fanoutFrame(trackEvent(pb.SubscribedTrackEvent_ADDED, rp.Identity(), pub.SID(), codec))
+}
+
+// detachDecoder stops decoding a track that was unsubscribed or unpublished
+func (s *RoomSession) detachDecoder(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+	s.mu.Lock()
+	decoder := s.decoders[pub.SID()]
+	delete(s.decoders, pub.SID())
+	s.mu.Unlock()
+	if decoder != nil {
+		decoder.Close()
+		s.fanoutFrame(trackEvent(pb.SubscribedTrackEvent_REMOVED, rp.Identity(), pub.SID(), track.Codec()))
+	}
+}
+
+// trackEvent is a stream message reporting a change to a remote track
+func trackEvent(typ pb.SubscribedTrackEvent_EventType, identity, sid string, codec webrtc.RTPCodecParameters) *pb.SubscribedAudioFrame {
+	return &pb.SubscribedAudioFrame{
+		SampleRate:          subscribeSampleRate,
+		ParticipantIdentity: identity,
+		TrackSid:            sid,
+		TimestampMs:         time.Now().UnixMilli(),
+		TrackEvent: &pb.SubscribedTrackEvent{
+			Type:      typ,
+			Codec:     codec.MimeType,
+			ClockRate: int32(codec.ClockRate),
+			Channels:  int32(codec.Channels),
+		},
+	}
+}
+
+// takeDecodersLocked empties the decoder map and returns what it held, to
+// be closed once s.mu is released. s.mu must be held.
+func (s *RoomSession) takeDecodersLocked() []*lkmedia.PCMRemoteTrack {
+	decoders := make([]*lkmedia.PCMRemoteTrack, 0, len(s.decoders))
+	for sid, decoder := range s.decoders {
+		decoders = append(decoders, decoder)
+		delete(s.decoders, sid)
+	}
+	return decoders
+}
+
+func closeDecoders(decoders []*lkmedia.PCMRemoteTrack) {
+	for _, decoder := range decoders {
+		decoder.Close()
+	}
+}
+
+// fanoutFrame hands a decoded frame to every stream that wants it. A stream
+// that isn't keeping up loses the frame rather than stalling the decoder.
+// It runs for every decoded frame of every track, so it only takes the
+// read lock and leaves playback and the other decoders to run alongside.
+func (s *RoomSession) fanoutFrame(frame *pb.SubscribedAudioFrame) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subscribers {
+		if !sub.wantsTrack(frame.ParticipantIdentity, frame.TrackSid) {
+			continue
+		}
+		select {
+		case sub.frames <- frame:
+		default:
+			if dropped := sub.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+				log.Printf("SubscribeAudio for user %s is falling behind; dropped %d frames", s.userId, dropped)
+			}
+		}
+	}
+}
+
+// SubscribeAudio streams decoded remote participant audio
+func (s *LiveKitBridgeService) SubscribeAudio(
+	req *pb.SubscribeAudioRequest,
+	stream pb.LiveKitBridge_SubscribeAudioServer,
+) error {
+	log.Printf("SubscribeAudio request: userId=%s, identities=%v, trackSids=%v, disableJitterBuffer=%v", req.UserId, req.Identities, req.TrackSids, req.DisableJitterBuffer)
+
+	session, ok := s.sessions.Get(req.UserId)
+	if !ok {
+		return status.Errorf(codes.NotFound, "session not found for user %s", req.UserId)
+	}
+
+	sub := session.addSubscriber(req.Identities, req.TrackSids, req.DisableJitterBuffer)
+	defer session.removeSubscriber(sub)
+
+	for {
+		select {
+		case frame := <-sub.frames:
+			if err := stream.Send(frame); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			log.Printf("SubscribeAudio ended: userId=%s", req.UserId)
+			return nil
+		case <-session.ctx.Done():
+			log.Printf("SubscribeAudio ended, session closed: userId=%s", req.UserId)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	lkauth "github.com/livekit/protocol/auth"
+)
+
+// Room token refresh.
+//
+// Reconnects, after a dropped connection or an audio stall, re-join with the
+// JoinRoom token, which may have expired since. A token within
+// tokenExpiryMargin of its exp isn't reused: if it was signed with
+// LIVEKIT_API_KEY the bridge signs a fresh one with the same grants, checked
+// against LIVEKIT_API_SECRET when the token was first seen; otherwise the
+// reconnect fails with errTokenExpired rather than being refused by LiveKit.
+
+// tokenExpiryMargin is how long before exp a token stops being used
+const tokenExpiryMargin = time.Minute
+
+var errTokenExpired = errors.New("room token has expired and wasn't signed with LIVEKIT_API_KEY, so it can't be refreshed")
+
+// roomToken is the token a session joins and reconnects with
+type roomToken struct {
+	apiKey    string
+	apiSecret string
+	grants    *lkauth.ClaimGrants // verified at join; nil = can't refresh
+	ttl       time.Duration       // lifetime of the original token
+
+	mu      sync.Mutex
+	raw     string
+	expires time.Time // zero = no exp claim
+}
+
+// newRoomToken wraps raw. If it verifies against apiKey and apiSecret its
+// grants are kept for refreshing it.
+func newRoomToken(raw, apiKey, apiSecret string) *roomToken {
+	t := &roomToken{apiKey: apiKey, apiSecret: apiSecret, raw: raw}
+	issued, expires, err := tokenTimes(raw)
+	if err != nil {
+		log.Printf("Room token has no readable expiry, reconnects will reuse it: %v", err)
+		return t
+	}
+	t.expires = expires
+	if !issued.IsZero() && !expires.IsZero() {
+		t.ttl = expires.Sub(issued)
+	}
+	if apiKey == "" || apiSecret == "" {
+		return t
+	}
+	if v, err := lkauth.ParseAPIToken(raw); err == nil && v.APIKey() == apiKey {
+		if grants, err := v.Verify(apiSecret); err == nil {
+			t.grants = grants
+		}
+	}
+	return t
+}
+
+// get returns a token valid for at least tokenExpiryMargin, refreshing it
+// if needed
+func (t *roomToken) get(now time.Time) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.expires.IsZero() || now.Add(tokenExpiryMargin).Before(t.expires) {
+		return t.raw, nil
+	}
+	if t.grants == nil {
+		return "", errTokenExpired
+	}
+
+	ttl := t.ttl
+	if ttl <= tokenExpiryMargin {
+		ttl = time.Hour
+	}
+	at := lkauth.NewAccessToken(t.apiKey, t.apiSecret)
+	*at.GetGrants() = *t.grants.Clone()
+	at.SetValidFor(ttl)
+	raw, err := at.ToJWT()
+	if err != nil {
+		return "", fmt.Errorf("refresh room token: %w", err)
+	}
+	t.raw, t.expires = raw, now.Add(ttl)
+	log.Printf("Refreshed room token for %s, valid for %v", t.grants.Identity, ttl)
+	return raw, nil
+}
+
+// tokenTimes reads the iat and exp claims of a JWT without verifying it.
+// Missing claims come back zero.
+func tokenTimes(raw string) (issued, expires time.Time, err error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return time.Time{}, time.Time{}, errors.New("not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("JWT payload: %w", err)
+	}
+	var claims struct {
+		IssuedAt  int64 `json:"iat"`
+		NotBefore int64 `json:"nbf"`
+		Expiry    int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("JWT payload: %w", err)
+	}
+	if claims.IssuedAt == 0 {
+		claims.IssuedAt = claims.NotBefore
+	}
+	if claims.IssuedAt != 0 {
+		issued = time.Unix(claims.IssuedAt, 0)
+	}
+	if claims.Expiry != 0 {
+		expires = time.Unix(claims.Expiry, 0)
+	}
+	return issued, expires, nil
+}
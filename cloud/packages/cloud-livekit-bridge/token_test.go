@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	lkauth "github.com/livekit/protocol/auth"
+)
+
+func signToken(t *testing.T, key, secret string, ttl time.Duration) string {
+	t.Helper()
+	at := lkauth.NewAccessToken(key, secret)
+	at.SetIdentity("user-1")
+	at.SetName("User One")
+	at.SetValidFor(ttl)
+	at.AddGrant(&lkauth.VideoGrant{RoomJoin: true, Room: "room-1"})
+	raw, err := at.ToJWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestRoomTokenRefresh(t *testing.T) {
+	const key, secret = "APIkey", "a-secret-that-is-long-enough-for-hs256"
+	raw := signToken(t, key, secret, 10*time.Minute)
+	now := time.Now()
+
+	// Fresh: used as is, by anyone
+	for _, tok := range []*roomToken{newRoomToken(raw, key, secret), newRoomToken(raw, "", "")} {
+		if got, err := tok.get(now); err != nil || got != raw {
+			t.Errorf("fresh token: get = %q, %v; want the original", got, err)
+		}
+	}
+
+	// Inside the margin without the signing key: refused
+	late := now.Add(10*time.Minute - tokenExpiryMargin/2)
+	for _, tok := range []*roomToken{newRoomToken(raw, "", ""), newRoomToken(raw, "otherKey", secret), newRoomToken(raw, key, "wrong-secret")} {
+		if _, err := tok.get(late); !errors.Is(err, errTokenExpired) {
+			t.Errorf("expiring token without its key: err = %v, want errTokenExpired", err)
+		}
+	}
+
+	// Inside the margin with the signing key: re-signed with the same grants
+	tok := newRoomToken(raw, key, secret)
+	refreshed, err := tok.get(late)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tok.expires.After(late.Add(tokenExpiryMargin)) {
+		t.Errorf("refreshed token expires at %v, still inside the margin", tok.expires)
+	}
+	v, err := lkauth.ParseAPIToken(refreshed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants, err := v.Verify(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grants.Identity != "user-1" || grants.Name != "User One" || grants.Video == nil || grants.Video.Room != "room-1" || !grants.Video.RoomJoin {
+		t.Errorf("refreshed grants = %+v, video %+v", grants, grants.Video)
+	}
+	if again, _ := tok.get(late); again != refreshed {
+		t.Errorf("second get re-signed again")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// Audio-flow watchdog.
+//
+// Inbound audio arrives as data packets; if the SFU silently stops
+// delivering them nothing else notices. While a StreamAudio stream is open
+// the watchdog checks time since the last packet and, past the stall
+// timeout, warns and (with AUDIO_STALL_ACTION=reconnect) reconnects the
+// session's room.
+
+// markPacket records that an inbound packet arrived
+func (s *RoomSession) markPacket() {
+	s.lastPacket.Store(time.Now().UnixNano())
+}
+
+// streamStarted marks a StreamAudio consumer as attached. The stall clock
+// starts from here so a stream that never receives anything is caught too.
+func (s *RoomSession) streamStarted() {
+	s.activeStreams.Add(1)
+	s.markPacket()
+}
+
+func (s *RoomSession) streamEnded() {
+	s.activeStreams.Add(-1)
+}
+
+// sinceLastPacket returns how long inbound audio has been quiet
+func (s *RoomSession) sinceLastPacket() time.Duration {
+	return time.Since(time.Unix(0, s.lastPacket.Load()))
+}
+
+// swapRoom replaces the session's room after a reconnect. Published tracks
+// belong to the old connection, so they are closed and get republished on
+// the next write; track volumes are kept.
+func (s *RoomSession) swapRoom(room *lksdk.Room) bool {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		room.Disconnect()
+		return false
+	}
+	for name, track := range s.tracks {
+		track.Close()
+		delete(s.tracks, name)
+		delete(s.processors, name)
+		delete(s.lastFrames, name)
+		delete(s.clocks, name)
+	}
+	old := s.room
+	s.room = room
+	decoders := s.takeDecodersLocked()
+	subscribed := len(s.subscribers) > 0
+	s.mu.Unlock()
+
+	closeDecoders(decoders)
+	if old != nil {
+		old.Disconnect()
+	}
+	if subscribed {
+		s.subscribeRoomAudio(room)
+	}
+	return true
+}
+
+// watchAudioFlow runs for the life of the session
+func (s *LiveKitBridgeService) watchAudioFlow(session *RoomSession, reconnect func() (*lksdk.Room, error)) {
+	timeout := s.config.AudioStallTimeout
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+
+	stalled := false
+	for {
+		select {
+		case <-session.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		quiet := session.sinceLastPacket()
+		if session.activeStreams.Load() == 0 || quiet < timeout {
+			if stalled {
+				log.Printf("Audio flow resumed for user %s", session.userId)
+				stalled = false
+			}
+			continue
+		}
+
+		// Warn once per stall, but retry a reconnect every timeout period
+		if !stalled {
+			stalled = true
+			s.bsLogger.LogWarn("Audio flow stalled", map[string]interface{}{
+				"user_id":  session.userId,
+				"quiet_ms": quiet.Milliseconds(),
+				"action":   s.config.AudioStallAction,
+			})
+			log.Printf("No audio from LiveKit for user %s in %v while streaming", session.userId, quiet.Round(time.Millisecond))
+		}
+		if s.config.AudioStallAction != "reconnect" {
+			continue
+		}
+
+		log.Printf("Reconnecting LiveKit room for user %s after audio stall", session.userId)
+		room, err := reconnect()
+		if err != nil {
+			s.bsLogger.LogError("Audio stall reconnect failed", err, map[string]interface{}{
+				"user_id": session.userId,
+			})
+			log.Printf("Audio stall reconnect failed for user %s: %v", session.userId, err)
+		} else if session.swapRoom(room) {
+			log.Printf("Reconnected LiveKit room for user %s", session.userId)
+		}
+		// Give the new connection a full timeout before judging it
+		session.markPacket()
+	}
+}
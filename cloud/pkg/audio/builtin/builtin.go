@@ -0,0 +1,27 @@
+// Package builtin embeds diagnostic WAVs that the bridges play without
+// network as builtin://<name> (e.g. builtin://chime), for CI and offline
+// testing.
+package builtin
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//go:embed assets/*.wav
+var assets embed.FS
+
+// Scheme prefixes URLs that name an embedded asset
+const Scheme = "builtin://"
+
+// Open opens the embedded WAV named by a builtin:// URL
+func Open(url string) (io.ReadCloser, error) {
+	name := strings.TrimPrefix(url, Scheme)
+	f, err := assets.Open("assets/" + name + ".wav")
+	if err != nil {
+		return nil, fmt.Errorf("unknown builtin asset %q", name)
+	}
+	return f, nil
+}
@@ -0,0 +1,42 @@
+package builtin
+
+import (
+	"bufio"
+	"testing"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"builtin://chime", false},
+		{"builtin://nope", true},
+		{"builtin://../builtin", true},
+		{"builtin://", true},
+	}
+	for _, tt := range tests {
+		f, err := Open(tt.url)
+		if tt.wantErr {
+			if err == nil {
+				f.Close()
+				t.Errorf("Open(%q) succeeded", tt.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Open(%q): %v", tt.url, err)
+			continue
+		}
+		// Both bridges play assets through their 16-bit PCM WAV path
+		h, err := audio.ReadWAVHeader(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", tt.url, err)
+		} else if h.AudioFormat != 1 || h.BitsPerSample != 16 {
+			t.Errorf("%s: format %d, %d bits; want 16-bit PCM", tt.url, h.AudioFormat, h.BitsPerSample)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package audio
+
+// ClipCounter tallies samples at or beyond a clip level, to flag assets and
+// publish gains that are too hot. The zero value is ready to use; it is not
+// safe for concurrent use.
+type ClipCounter struct {
+	Samples int64
+	Clipped int64
+}
+
+// Add counts frame. level is the magnitude treated as clipped (32767 counts
+// only full-scale samples).
+func (c *ClipCounter) Add(frame []int16, level int) {
+	for _, s := range frame {
+		if v := int(s); v >= level || v <= -level-1 {
+			c.Clipped++
+		}
+	}
+	c.Samples += int64(len(frame))
+}
+
+// Merge adds other's counts to c
+func (c *ClipCounter) Merge(other ClipCounter) {
+	c.Samples += other.Samples
+	c.Clipped += other.Clipped
+}
+
+// Percent is the share of clipped samples, 0-100
+func (c *ClipCounter) Percent() float64 {
+	if c.Samples == 0 {
+		return 0
+	}
+	return float64(c.Clipped) * 100 / float64(c.Samples)
+}
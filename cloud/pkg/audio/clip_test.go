@@ -0,0 +1,40 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClipCounter(t *testing.T) {
+	tests := []struct {
+		name        string
+		frames      [][]int16
+		level       int
+		wantClipped int64
+		wantPercent float64
+	}{
+		{"empty", nil, 32767, 0, 0},
+		{"full scale only", [][]int16{{0, 32767, -32768, 32766, -32767}}, 32767, 2, 40},
+		{"lower level", [][]int16{{0, 30000, -30001, 29999}}, 30000, 2, 50},
+		{"across frames", [][]int16{{math.MaxInt16}, {0, 0, 0}}, 32767, 1, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c ClipCounter
+			for _, f := range tt.frames {
+				c.Add(f, tt.level)
+			}
+			if c.Clipped != tt.wantClipped || c.Percent() != tt.wantPercent {
+				t.Errorf("clipped %d (%.1f%%), want %d (%.1f%%)", c.Clipped, c.Percent(), tt.wantClipped, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestClipCounterMerge(t *testing.T) {
+	a := ClipCounter{Samples: 10, Clipped: 1}
+	a.Merge(ClipCounter{Samples: 30, Clipped: 3})
+	if a != (ClipCounter{Samples: 40, Clipped: 4}) || a.Percent() != 10 {
+		t.Errorf("merged = %+v (%.1f%%)", a, a.Percent())
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/Mentra-Community/MentraOS/cloud/pkg/audio
+
+go 1.24.2
@@ -0,0 +1,132 @@
+package audio
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SilenceDB is the level reported for a source that was digitally silent
+const SilenceDB = -100.0
+
+type levelSum struct {
+	sumSq float64
+	n     int
+}
+
+// LevelMeter accumulates per-source signal energy between reports. The
+// zero value is ready to use and it is safe for concurrent use.
+type LevelMeter struct {
+	mu      sync.Mutex
+	sources map[string]*levelSum
+}
+
+// Add accumulates samples under source
+func (m *LevelMeter) Add(source string, samples []int16) {
+	if len(samples) == 0 {
+		return
+	}
+	var sumSq float64
+	for _, s := range samples {
+		v := float64(s) / 32768
+		sumSq += v * v
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sources == nil {
+		m.sources = make(map[string]*levelSum)
+	}
+	l := m.sources[source]
+	if l == nil {
+		l = &levelSum{}
+		m.sources[source] = l
+	}
+	l.sumSq += sumSq
+	l.n += len(samples)
+}
+
+// Drain returns each source's RMS level in dBFS (rounded to 0.1 dB, floored
+// at SilenceDB) since the last call, and resets the meter. Sources that
+// added nothing are left out.
+func (m *LevelMeter) Drain() map[string]float64 {
+	m.mu.Lock()
+	sources := m.sources
+	m.sources = nil
+	m.mu.Unlock()
+
+	levels := make(map[string]float64, len(sources))
+	for source, l := range sources {
+		db := SilenceDB
+		if l.sumSq > 0 {
+			db = math.Max(SilenceDB, 10*math.Log10(l.sumSq/float64(l.n)))
+		}
+		levels[source] = math.Round(db*10) / 10
+	}
+	return levels
+}
+
+// Speaking detection defaults, for callers that don't choose their own
+const (
+	// DefaultSpeakingDB is the level at which a source counts as speaking
+	DefaultSpeakingDB = -45.0
+	// DefaultSpeakingHold keeps a source speaking through short pauses
+	DefaultSpeakingHold = 500 * time.Millisecond
+)
+
+// SourceLevel is one source's level and speaking state for a report
+type SourceLevel struct {
+	Source   string
+	LevelDB  float64
+	Speaking bool
+}
+
+// SpeakingDetector turns drained levels into speaking indicators. A source
+// is speaking once its level reaches ThresholdDB and stays so until it has
+// been below it for Hold, so pauses between words don't flicker.
+type SpeakingDetector struct {
+	ThresholdDB float64
+	Hold        time.Duration
+
+	lastLoud map[string]time.Time // sources currently speaking
+}
+
+// Update reports every source in levels, plus any that were speaking and
+// went quiet (at SilenceDB, not speaking) so indicators get cleared. The
+// result is sorted by source.
+func (d *SpeakingDetector) Update(levels map[string]float64, now time.Time) []SourceLevel {
+	if d.lastLoud == nil {
+		d.lastLoud = make(map[string]time.Time)
+	}
+	out := make([]SourceLevel, 0, len(levels)+len(d.lastLoud))
+	for source, db := range levels {
+		if db >= d.ThresholdDB {
+			d.lastLoud[source] = now
+		}
+		out = append(out, SourceLevel{Source: source, LevelDB: db, Speaking: d.speaking(source, now)})
+	}
+	for source := range d.lastLoud {
+		if _, heard := levels[source]; heard {
+			continue
+		}
+		speaking := d.speaking(source, now)
+		out = append(out, SourceLevel{Source: source, LevelDB: SilenceDB, Speaking: speaking})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
+
+// speaking reports whether source is within Hold of its last loud report,
+// forgetting it once it isn't
+func (d *SpeakingDetector) speaking(source string, now time.Time) bool {
+	last, ok := d.lastLoud[source]
+	if !ok {
+		return false
+	}
+	if now.Sub(last) > d.Hold {
+		delete(d.lastLoud, source)
+		return false
+	}
+	return true
+}
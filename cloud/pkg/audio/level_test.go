@@ -0,0 +1,66 @@
+package audio
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLevelMeterDrain(t *testing.T) {
+	var m LevelMeter
+	full := make([]int16, 160)
+	for i := range full {
+		full[i] = 32767
+		if i%2 == 1 {
+			full[i] = -32768
+		}
+	}
+	m.Add("loud", full)
+	m.Add("half", []int16{16384, -16384})
+	m.Add("silent", make([]int16, 160))
+	m.Add("nothing", nil)
+
+	got := m.Drain()
+	want := map[string]float64{"loud": 0, "half": -6, "silent": SilenceDB}
+	if len(got) != len(want) {
+		t.Fatalf("Drain = %v, want %v", got, want)
+	}
+	for source, db := range want {
+		if got[source] != db {
+			t.Errorf("%s = %v dB, want %v", source, got[source], db)
+		}
+	}
+	if again := m.Drain(); len(again) != 0 {
+		t.Errorf("second Drain = %v, want empty", again)
+	}
+}
+
+func TestSpeakingDetector(t *testing.T) {
+	d := SpeakingDetector{ThresholdDB: DefaultSpeakingDB, Hold: DefaultSpeakingHold}
+	t0 := time.Unix(0, 0)
+	steps := []struct {
+		at     time.Duration
+		levels map[string]float64
+		want   []SourceLevel
+	}{
+		{0, map[string]float64{"a": -20, "b": -60},
+			[]SourceLevel{{"a", -20, true}, {"b", -60, false}}},
+		// a goes quiet but is held; b starts talking
+		{200 * time.Millisecond, map[string]float64{"a": -70, "b": -30},
+			[]SourceLevel{{"a", -70, true}, {"b", -30, true}}},
+		// a stops reporting within the hold: still speaking, at silence
+		{400 * time.Millisecond, map[string]float64{"b": -30},
+			[]SourceLevel{{"a", SilenceDB, true}, {"b", -30, true}}},
+		// past a's hold: cleared once, then forgotten
+		{600 * time.Millisecond, map[string]float64{"b": -30},
+			[]SourceLevel{{"a", SilenceDB, false}, {"b", -30, true}}},
+		{700 * time.Millisecond, map[string]float64{"b": -30},
+			[]SourceLevel{{"b", -30, true}}},
+	}
+	for _, s := range steps {
+		got := d.Update(s.levels, t0.Add(s.at))
+		if !slices.Equal(got, s.want) {
+			t.Errorf("at %v: %v, want %v", s.at, got, s.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package audio
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pacer holds a playback loop to real time. Audio tracks queue whatever is
+// written to them, so a decoder left to run would queue a whole clip in a
+// moment; with a Pacer it stays at most Lead ahead of what has played, which
+// also bounds how much queued audio still plays after Pause.
+type Pacer struct {
+	rate int
+	lead time.Duration
+
+	mu       sync.Mutex
+	start    time.Time
+	samples  int64
+	total    int64         // samples accounted since NewPacer
+	resume   chan struct{} // non-nil while paused; closed by Resume
+	pausedAt time.Time
+	paused   time.Duration
+}
+
+// NewPacer returns a Pacer for audio at sampleRate that may run lead ahead
+// of real time
+func NewPacer(sampleRate int, lead time.Duration) *Pacer {
+	return &Pacer{rate: sampleRate, lead: lead}
+}
+
+// Wait accounts for n samples about to be written, blocking while paused and
+// until the samples already written are within Lead of real time. It returns
+// ctx's error if ctx ends first.
+func (p *Pacer) Wait(ctx context.Context, n int) error {
+	for {
+		p.mu.Lock()
+		resume := p.resume
+		if resume == nil {
+			break
+		}
+		p.mu.Unlock()
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	now := time.Now()
+	// Re-anchor after an underrun (or a pause) so idle time isn't banked as
+	// credit
+	if p.start.IsZero() || p.playedUntil().Before(now) {
+		p.start, p.samples = now, 0
+	}
+	ahead := p.playedUntil().Sub(now)
+	p.samples += int64(n)
+	p.total += int64(n)
+	p.mu.Unlock()
+
+	if wait := ahead - p.lead; wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	return ctx.Err()
+}
+
+// playedUntil is when the samples written since start finish playing.
+// p.mu must be held.
+func (p *Pacer) playedUntil() time.Time {
+	return p.start.Add(time.Duration(p.samples) * time.Second / time.Duration(p.rate))
+}
+
+// Pause holds the next Wait until Resume. It returns false if already paused.
+func (p *Pacer) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resume != nil {
+		return false
+	}
+	p.resume = make(chan struct{})
+	p.pausedAt = time.Now()
+	return true
+}
+
+// Resume releases a paused Pacer. It returns false if it wasn't paused.
+func (p *Pacer) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resume == nil {
+		return false
+	}
+	close(p.resume)
+	p.resume = nil
+	p.paused += time.Since(p.pausedAt)
+	return true
+}
+
+// Written is how much audio has been passed to Wait
+func (p *Pacer) Written() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Duration(p.total) * time.Second / time.Duration(p.rate)
+}
+
+// Position is how much of the audio passed to Wait has played: all of it,
+// less what is still queued ahead of real time
+func (p *Pacer) Position() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pos := time.Duration(p.total) * time.Second / time.Duration(p.rate)
+	if ahead := time.Until(p.playedUntil()); ahead > 0 {
+		pos -= ahead
+	}
+	return max(pos, 0)
+}
+
+// PausedFor is the total time spent paused, including a pause in progress
+func (p *Pacer) PausedFor() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resume != nil {
+		return p.paused + time.Since(p.pausedAt)
+	}
+	return p.paused
+}
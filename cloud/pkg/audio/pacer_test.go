@@ -0,0 +1,145 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const testRate = 16000
+
+// samplesFor is how many samples at testRate last d
+func samplesFor(d time.Duration) int {
+	return int(d * testRate / time.Second)
+}
+
+func TestPacerWaitHoldsToLead(t *testing.T) {
+	tests := []struct {
+		name    string
+		lead    time.Duration
+		written time.Duration // audio passed to Wait in 10ms frames
+		minTook time.Duration
+		maxTook time.Duration
+	}{
+		{"within lead runs free", 200 * time.Millisecond, 150 * time.Millisecond, 0, 50 * time.Millisecond},
+		{"past lead waits", 50 * time.Millisecond, 250 * time.Millisecond, 150 * time.Millisecond, 400 * time.Millisecond},
+		{"zero lead is real time", 0, 200 * time.Millisecond, 150 * time.Millisecond, 350 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPacer(testRate, tt.lead)
+			frame := samplesFor(10 * time.Millisecond)
+			start := time.Now()
+			for i := 0; i < int(tt.written/(10*time.Millisecond)); i++ {
+				if err := p.Wait(context.Background(), frame); err != nil {
+					t.Fatal(err)
+				}
+			}
+			took := time.Since(start)
+			if took < tt.minTook || took > tt.maxTook {
+				t.Errorf("writing %v took %v, want %v..%v", tt.written, took, tt.minTook, tt.maxTook)
+			}
+			if got := p.Written(); got != tt.written {
+				t.Errorf("Written = %v, want %v", got, tt.written)
+			}
+		})
+	}
+}
+
+func TestPacerWaitReturnsContextError(t *testing.T) {
+	p := NewPacer(testRate, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := p.Wait(ctx, samplesFor(time.Second))
+	if err == nil {
+		err = p.Wait(ctx, samplesFor(time.Second))
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if took := time.Since(start); took > 500*time.Millisecond {
+		t.Errorf("Wait returned after %v, want soon after the deadline", took)
+	}
+}
+
+func TestPacerPauseResume(t *testing.T) {
+	p := NewPacer(testRate, time.Second)
+	if !p.Pause() {
+		t.Fatal("Pause on a running pacer returned false")
+	}
+	if p.Pause() {
+		t.Error("second Pause returned true")
+	}
+
+	waited := make(chan struct{})
+	go func() {
+		p.Wait(context.Background(), 160)
+		close(waited)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := p.PausedFor(); got < 50*time.Millisecond {
+		t.Errorf("PausedFor during a pause = %v, want >= 50ms", got)
+	}
+
+	if !p.Resume() {
+		t.Fatal("Resume on a paused pacer returned false")
+	}
+	if p.Resume() {
+		t.Error("second Resume returned true")
+	}
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait still blocked after Resume")
+	}
+
+	paused := p.PausedFor()
+	time.Sleep(20 * time.Millisecond)
+	if got := p.PausedFor(); got != paused {
+		t.Errorf("PausedFor grew from %v to %v while running", paused, got)
+	}
+}
+
+func TestPacerPausedWaitHonoursContext(t *testing.T) {
+	p := NewPacer(testRate, time.Second)
+	p.Pause()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Wait(ctx, 160); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if got := p.Written(); got != 0 {
+		t.Errorf("Written = %v after a cancelled Wait, want 0", got)
+	}
+}
+
+func TestPacerPosition(t *testing.T) {
+	p := NewPacer(testRate, time.Second)
+	if got := p.Position(); got != 0 {
+		t.Fatalf("Position before any Wait = %v, want 0", got)
+	}
+
+	// Half a second queued at once is all ahead of real time
+	if err := p.Wait(context.Background(), samplesFor(500*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Position(); got > 50*time.Millisecond {
+		t.Errorf("Position right after queueing = %v, want about 0", got)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if got := p.Position(); got < 150*time.Millisecond || got > 350*time.Millisecond {
+		t.Errorf("Position after 200ms = %v, want about 200ms", got)
+	}
+
+	time.Sleep(400 * time.Millisecond)
+	if got := p.Position(); got != 500*time.Millisecond {
+		t.Errorf("Position once everything played = %v, want 500ms", got)
+	}
+}
@@ -0,0 +1,94 @@
+// Package audio holds the PCM helpers shared by the cloud's Go audio
+// services: sample conversion, gain, downmixing and WAV header parsing.
+// Resampling lives in the resample subpackage.
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// BytesToInt16 converts PCM16 LE bytes to samples. A trailing odd byte is
+// ignored.
+func BytesToInt16(pcm []byte) []int16 {
+	samples := make([]int16, len(pcm)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[i*2:]))
+	}
+	return samples
+}
+
+// Int16ToBytes converts samples to PCM16 LE bytes
+func Int16ToBytes(samples []int16) []byte {
+	pcm := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(s))
+	}
+	return pcm
+}
+
+// Float32ToInt16 converts float32 LE samples in [-1,1] to int16, clipping
+// out-of-range values
+func Float32ToInt16(pcm []byte) []int16 {
+	samples := make([]int16, len(pcm)/4)
+	for i := range samples {
+		v := float64(math.Float32frombits(binary.LittleEndian.Uint32(pcm[i*4:])))
+		samples[i] = int16(clamp(v, -1, 1) * 32767)
+	}
+	return samples
+}
+
+// Int16ToFloat32Bytes converts samples to float32 LE in [-1,1]
+func Int16ToFloat32Bytes(samples []int16) []byte {
+	pcm := make([]byte, len(samples)*4)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint32(pcm[i*4:], math.Float32bits(float32(s)/32768))
+	}
+	return pcm
+}
+
+// ApplyGain scales samples in place, saturating at the int16 range
+func ApplyGain(samples []int16, gain float64) {
+	if gain == 1.0 {
+		return
+	}
+	for i, s := range samples {
+		samples[i] = int16(clamp(float64(s)*gain, math.MinInt16, math.MaxInt16))
+	}
+}
+
+// Downmix folds interleaved N-channel samples into mono. With no weights
+// every channel contributes equally; otherwise weights must have one entry
+// per channel. Mono input is returned as is.
+func Downmix[W float32 | float64](samples []int16, channels int, weights []W) []int16 {
+	if channels == 1 {
+		return samples
+	}
+	mono := make([]int16, len(samples)/channels)
+	for f := range mono {
+		frame := samples[f*channels : (f+1)*channels]
+		var v float64
+		if len(weights) == channels {
+			for c, s := range frame {
+				v += float64(s) * float64(weights[c])
+			}
+		} else {
+			for _, s := range frame {
+				v += float64(s)
+			}
+			v /= float64(channels)
+		}
+		mono[f] = int16(clamp(v, math.MinInt16, math.MaxInt16))
+	}
+	return mono
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v > hi {
+		return hi
+	}
+	if v < lo {
+		return lo
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestInt16BytesRoundTrip(t *testing.T) {
+	samples := []int16{0, 1, -1, math.MaxInt16, math.MinInt16, 12345}
+	pcm := Int16ToBytes(samples)
+	if want := []byte{0, 0, 1, 0, 0xff, 0xff, 0xff, 0x7f, 0, 0x80, 0x39, 0x30}; !slices.Equal(pcm, want) {
+		t.Fatalf("Int16ToBytes = %v, want %v", pcm, want)
+	}
+	if got := BytesToInt16(append(pcm, 7)); !slices.Equal(got, samples) {
+		t.Errorf("BytesToInt16 = %v, want %v (trailing odd byte ignored)", got, samples)
+	}
+}
+
+func TestFloat32RoundTrip(t *testing.T) {
+	tests := []struct {
+		in, want int16
+	}{
+		{0, 0},
+		{16384, 16383},
+		{-16384, -16383},
+		{math.MinInt16, -32767},
+	}
+	for _, tt := range tests {
+		got := Float32ToInt16(Int16ToFloat32Bytes([]int16{tt.in}))[0]
+		if got != tt.want {
+			t.Errorf("float32 round trip of %d = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestApplyGain(t *testing.T) {
+	tests := []struct {
+		name string
+		gain float64
+		in   []int16
+		want []int16
+	}{
+		{"unity", 1, []int16{100, -100}, []int16{100, -100}},
+		{"half", 0.5, []int16{100, -100, 1}, []int16{50, -50, 0}},
+		{"saturates", 4, []int16{20000, -20000}, []int16{math.MaxInt16, math.MinInt16}},
+		{"mute", 0, []int16{100, -100}, []int16{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Clone(tt.in)
+			ApplyGain(got, tt.gain)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ApplyGain(%v, %v) = %v, want %v", tt.in, tt.gain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownmix(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       []int16
+		channels int
+		weights  []float64
+		want     []int16
+	}{
+		{"mono unchanged", []int16{1, 2, 3}, 1, nil, []int16{1, 2, 3}},
+		{"stereo average", []int16{100, 300, -100, -300}, 2, nil, []int16{200, -200}},
+		{"weighted", []int16{100, 300, 1000, 0}, 2, []float64{1, 0}, []int16{100, 1000}},
+		{"weights of the wrong length are ignored", []int16{100, 300}, 2, []float64{1}, []int16{200}},
+		{"weighted sum saturates", []int16{30000, 30000}, 2, []float64{1, 1}, []int16{math.MaxInt16}},
+		{"partial frame dropped", []int16{10, 20, 30, 40, 50}, 3, nil, []int16{20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Downmix(tt.in, tt.channels, tt.weights); !slices.Equal(got, tt.want) {
+				t.Errorf("Downmix = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package playerr defines the stable, machine-readable reasons a playback
+// request can fail. Both bridges report them (play_complete "code" over
+// WebSocket, FAILED event metadata "code" over gRPC) next to a human
+// message, so clients can branch on one set of codes whichever bridge
+// they talk to.
+package playerr
+
+import (
+	"context"
+	"errors"
+)
+
+// Code is the reason a playback failed
+type Code string
+
+const (
+	TrackUnavailable  Code = "track_unavailable"  // session closing or no track to play into
+	InvalidURL        Code = "invalid_url"        // URL unparsable or unknown builtin asset
+	FetchFailed       Code = "fetch_failed"       // request to the URL failed
+	HTTPStatus        Code = "http_error"         // URL answered with a non-2xx status
+	UnsupportedFormat Code = "unsupported_format" // content type or encoding we can't play
+	MalformedAudio    Code = "malformed_audio"    // file structure is broken
+	DecodeTimeout     Code = "decode_timeout"     // no decodable audio arrived in time
+	DecodeFailed      Code = "decode_error"       // decoder rejected the stream
+	ReadFailed        Code = "read_failed"        // stream broke mid-playback
+	InvalidRequest    Code = "invalid_request"    // request options don't fit the audio
+	WriteFailed       Code = "write_failed"       // writing to the LiveKit track failed
+	EmptyAudio        Code = "empty_audio"        // source produced no samples
+	Cancelled         Code = "cancelled"          // stopped on request or by a newer play
+	NotifyFailed      Code = "notify_failed"      // the started notification couldn't be delivered
+	Internal          Code = "internal"           // anything unclassified
+)
+
+var messages = map[Code]string{
+	TrackUnavailable:  "No audio track available to play into",
+	InvalidURL:        "The audio URL is invalid",
+	FetchFailed:       "Could not fetch the audio URL",
+	HTTPStatus:        "The audio URL returned an error status",
+	UnsupportedFormat: "The audio format is not supported",
+	MalformedAudio:    "The audio file is malformed",
+	DecodeTimeout:     "No playable audio arrived in time",
+	DecodeFailed:      "The audio could not be decoded",
+	ReadFailed:        "The audio stream failed during playback",
+	InvalidRequest:    "The play options do not match the audio",
+	WriteFailed:       "Could not write audio to the room",
+	EmptyAudio:        "The audio contained no samples",
+	Cancelled:         "Playback was cancelled",
+	NotifyFailed:      "Playback was aborted because the started notification could not be sent",
+	Internal:          "Playback failed",
+}
+
+// Message returns the human-readable text for the code
+func (c Code) Message() string {
+	if msg, ok := messages[c]; ok {
+		return msg
+	}
+	return string(c)
+}
+
+// Error tags a playback error with its code
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// Fail wraps err with code
+func Fail(code Code, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+// CodeOf classifies err. Cancellation wins over the tag, since a fetch cut
+// short by a stop request otherwise reads as FetchFailed.
+func CodeOf(err error) Code {
+	if errors.Is(err, context.Canceled) {
+		return Cancelled
+	}
+	var pe *Error
+	if errors.As(err, &pe) {
+		return pe.Code
+	}
+	return Internal
+}
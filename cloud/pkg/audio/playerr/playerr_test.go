@@ -0,0 +1,51 @@
+package playerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"tagged", Fail(FetchFailed, base), FetchFailed},
+		{"tagged and wrapped", fmt.Errorf("play: %w", Fail(MalformedAudio, base)), MalformedAudio},
+		{"untagged", base, Internal},
+		{"cancelled", context.Canceled, Cancelled},
+		{"cancellation beats the tag", Fail(FetchFailed, fmt.Errorf("get: %w", context.Canceled)), Cancelled},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("%s: CodeOf = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFailKeepsError(t *testing.T) {
+	base := errors.New("boom")
+	err := Fail(ReadFailed, base)
+	if err.Error() != "boom" || !errors.Is(err, base) {
+		t.Errorf("Fail(ReadFailed, boom) = %v, want it to read as and wrap boom", err)
+	}
+}
+
+func TestEveryCodeHasAMessage(t *testing.T) {
+	for _, c := range []Code{
+		TrackUnavailable, InvalidURL, FetchFailed, HTTPStatus, UnsupportedFormat,
+		MalformedAudio, DecodeTimeout, DecodeFailed, ReadFailed, InvalidRequest,
+		WriteFailed, EmptyAudio, Cancelled, NotifyFailed, Internal,
+	} {
+		if c.Message() == string(c) {
+			t.Errorf("%s has no message", c)
+		}
+	}
+	if got := Code("other").Message(); got != "other" {
+		t.Errorf("unknown code message = %q", got)
+	}
+}
@@ -0,0 +1,279 @@
+// Package resample converts PCM16 mono audio between sample rates with a
+// windowed-sinc polyphase filter.
+//
+// The ratio between rates is reduced to up/down and the read position is
+// tracked as an exact rational, so long streams never drift. Filter banks
+// are built once per (rates, quality) and shared between resamplers.
+//
+// Cost per output sample is one dot product of Taps() coefficients: at
+// Medium, 16kHz -> 48kHz is 32 multiply-adds per sample and 44.1kHz ->
+// 16kHz is 90, a few million per second of audio - well under 1% of a
+// core per stream. BenchmarkProcess reports the time per second of audio
+// for the common ratios (go test -bench . ./resample).
+package resample
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// Quality selects the filter length and stopband attenuation
+type Quality int
+
+const (
+	// Medium is used when no quality is given
+	Medium Quality = iota
+	// Low is a short filter for when CPU matters more than aliasing
+	Low
+	// High is a long filter with a narrow transition band, for music
+	High
+)
+
+func (q Quality) String() string {
+	switch q {
+	case Low:
+		return "low"
+	case High:
+		return "high"
+	default:
+		return "medium"
+	}
+}
+
+// ParseQuality maps "low", "medium" or "high" to a Quality
+func ParseQuality(s string) (Quality, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return Low, nil
+	case "", "medium":
+		return Medium, nil
+	case "high":
+		return High, nil
+	}
+	return Medium, fmt.Errorf("unknown resample quality %q (want low, medium or high)", s)
+}
+
+// filterParams are the design values for a Quality
+type filterParams struct {
+	zeroCrossings int     // sinc lobes on each side of the centre
+	beta          float64 // Kaiser window shape
+	cutoff        float64 // passband edge as a fraction of the lower Nyquist
+}
+
+func (q Quality) params() filterParams {
+	switch q {
+	case Low:
+		return filterParams{zeroCrossings: 8, beta: 5, cutoff: 0.90}
+	case High:
+		return filterParams{zeroCrossings: 32, beta: 9, cutoff: 0.96}
+	default:
+		return filterParams{zeroCrossings: 16, beta: 7, cutoff: 0.94}
+	}
+}
+
+// maxPhases bounds the filter bank. Ratios with a larger up factor (odd
+// rates like 44100 -> 16001) round the read position down to one of
+// maxPhases phases; the error is still below 16-bit quantization noise.
+const maxPhases = 1024
+
+// bank is a precomputed polyphase filter, read-only once built
+type bank struct {
+	phases int
+	taps   int // coefficients per phase, even
+	coeffs []float32
+}
+
+type bankKey struct {
+	up, down int
+	quality  Quality
+}
+
+var banks sync.Map // bankKey -> *bank
+
+func loadBank(up, down int, q Quality) *bank {
+	key := bankKey{up, down, q}
+	if b, ok := banks.Load(key); ok {
+		return b.(*bank)
+	}
+	b, _ := banks.LoadOrStore(key, newBank(up, down, q))
+	return b.(*bank)
+}
+
+// newBank designs the filter for output times frac = p/phases between two
+// input samples. Downsampling lowers the cutoff to the output Nyquist and
+// stretches the filter to match.
+func newBank(up, down int, q Quality) *bank {
+	p := q.params()
+	scale := math.Min(1, float64(up)/float64(down))
+	fc := p.cutoff * scale
+	halfLen := float64(p.zeroCrossings) / scale
+	half := int(math.Ceil(halfLen))
+
+	b := &bank{phases: min(up, maxPhases), taps: 2 * half}
+	b.coeffs = make([]float32, b.phases*b.taps)
+	i0Beta := besselI0(p.beta)
+	row := make([]float64, b.taps)
+	for ph := 0; ph < b.phases; ph++ {
+		frac := float64(ph) / float64(b.phases)
+		var sum float64
+		for j := range row {
+			// Distance from the output time to input sample j of the window
+			t := frac + float64(half-1-j)
+			row[j] = 0
+			if math.Abs(t) < halfLen {
+				x := t / halfLen
+				row[j] = fc * sinc(fc*t) * besselI0(p.beta*math.Sqrt(1-x*x)) / i0Beta
+			}
+			sum += row[j]
+		}
+		// Unity gain at DC for every phase, so a constant stays constant
+		for j, c := range row {
+			b.coeffs[ph*b.taps+j] = float32(c / sum)
+		}
+	}
+	return b
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// besselI0 is the zeroth-order modified Bessel function of the first kind
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+// Resampler converts a stream of samples from one rate to another. It keeps
+// the last Taps()/2 input samples as history between calls, so audio may be
+// pushed in chunks of any size. Not safe for concurrent use.
+type Resampler struct {
+	up, down int64 // dst/gcd, src/gcd
+	bank     *bank
+	half     int
+	buf      []float32
+	pos      int   // index in buf of the input sample at or before the next output
+	phase    int64 // fractional read position, in units of 1/up
+}
+
+// MaxRate is the highest sample rate New accepts. It bounds the filter a
+// hostile or corrupt header can make New build.
+const MaxRate = 384000
+
+// New returns a resampler from srcRate to dstRate. Both rates must be in
+// 1..MaxRate; rates usually come from file headers, so anything else is an
+// error rather than a panic.
+func New(srcRate, dstRate int, q Quality) (*Resampler, error) {
+	if srcRate <= 0 || dstRate <= 0 || srcRate > MaxRate || dstRate > MaxRate {
+		return nil, fmt.Errorf("resample: invalid rates %d -> %d", srcRate, dstRate)
+	}
+	g := gcd(srcRate, dstRate)
+	up, down := dstRate/g, srcRate/g
+	b := loadBank(up, down, q)
+	r := &Resampler{up: int64(up), down: int64(down), bank: b, half: b.taps / 2}
+	r.Reset()
+	return r, nil
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Taps is the filter length per output sample
+func (r *Resampler) Taps() int {
+	return r.bank.taps
+}
+
+// Reset drops buffered input so the next Process starts a fresh stream
+func (r *Resampler) Reset() {
+	// Pre-roll with silence so the first output lines up with the first input
+	r.buf = append(r.buf[:0], make([]float32, r.half-1)...)
+	r.pos = r.half - 1
+	r.phase = 0
+}
+
+// Process pushes in and returns as many output samples as the buffered
+// input allows
+func (r *Resampler) Process(in []int16) []int16 {
+	for _, s := range in {
+		r.buf = append(r.buf, float32(s))
+	}
+	return r.drain(nil)
+}
+
+// Flush returns the output still held back waiting for look-ahead, as if the
+// stream were followed by silence, and resets the resampler
+func (r *Resampler) Flush() []int16 {
+	n := len(r.buf)
+	r.buf = append(r.buf, make([]float32, r.half)...)
+	// Only emit outputs whose read position lies within the real input
+	var out []int16
+	for r.pos < n && r.pos+r.half < len(r.buf) {
+		out = r.next(out)
+	}
+	r.Reset()
+	return out
+}
+
+func (r *Resampler) drain(out []int16) []int16 {
+	if avail := len(r.buf) - r.half - r.pos; avail > 0 {
+		est := int(int64(avail) * r.up / r.down)
+		if cap(out)-len(out) < est+1 {
+			out = append(make([]int16, 0, len(out)+est+1), out...)
+		}
+	}
+	for r.pos+r.half < len(r.buf) {
+		out = r.next(out)
+	}
+
+	// Keep only the history the next output needs
+	if start := r.pos - r.half + 1; start > 0 {
+		r.buf = r.buf[:copy(r.buf, r.buf[start:])]
+		r.pos -= start
+	}
+	return out
+}
+
+// next computes one output sample and advances the read position
+func (r *Resampler) next(out []int16) []int16 {
+	b := r.bank
+	ph := int(r.phase * int64(b.phases) / r.up)
+	coeffs := b.coeffs[ph*b.taps : (ph+1)*b.taps]
+	window := r.buf[r.pos-r.half+1 : r.pos-r.half+1+b.taps]
+
+	var acc float32
+	for j, c := range coeffs {
+		acc += c * window[j]
+	}
+	if acc >= 0 {
+		acc += 0.5
+	} else {
+		acc -= 0.5
+	}
+	if acc > math.MaxInt16 {
+		acc = math.MaxInt16
+	} else if acc < math.MinInt16 {
+		acc = math.MinInt16
+	}
+	out = append(out, int16(acc))
+
+	r.phase += r.down
+	r.pos += int(r.phase / r.up)
+	r.phase %= r.up
+	return out
+}
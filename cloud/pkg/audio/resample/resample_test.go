@@ -0,0 +1,217 @@
+package resample
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNewRejectsInvalidRates(t *testing.T) {
+	for _, rates := range [][2]int{{0, 16000}, {16000, 0}, {-8000, 16000}, {MaxRate + 1, 16000}, {16000, MaxRate + 1}} {
+		if _, err := New(rates[0], rates[1], Medium); err == nil {
+			t.Errorf("New(%d, %d) succeeded", rates[0], rates[1])
+		}
+	}
+}
+
+// sine returns n samples of a half-scale sine at freq Hz
+func sine(n, rate int, freq float64) []int16 {
+	out := make([]int16, n)
+	for i := range out {
+		out[i] = int16(16384 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return out
+}
+
+func TestParseQuality(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Quality
+		wantErr bool
+	}{
+		{"", Medium, false},
+		{"low", Low, false},
+		{" Medium ", Medium, false},
+		{"HIGH", High, false},
+		{"best", Medium, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuality(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseQuality(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+var ratios = []struct{ src, dst int }{
+	{16000, 16000},
+	{16000, 48000},
+	{48000, 16000},
+	{44100, 16000},
+	{22050, 16000},
+	{8000, 16000},
+	{44100, 16001},
+}
+
+// TestLength checks that a stream yields dst/src times as many samples
+// however it is chunked, so long streams don't drift
+func TestLength(t *testing.T) {
+	for _, c := range ratios {
+		for _, chunk := range []int{1, 160, 441, 10000} {
+			t.Run(fmt.Sprintf("%d-%d/chunk%d", c.src, c.dst, chunk), func(t *testing.T) {
+				r, err := New(c.src, c.dst, Medium)
+				if err != nil {
+					t.Fatal(err)
+				}
+				in := sine(10*c.src, c.src, 440)
+				var n int
+				for off := 0; off < len(in); off += chunk {
+					n += len(r.Process(in[off:min(off+chunk, len(in))]))
+				}
+				n += len(r.Flush())
+				want := int(math.Ceil(float64(len(in)) * float64(c.dst) / float64(c.src)))
+				if n != want {
+					t.Errorf("%d input samples gave %d output, want %d", len(in), n, want)
+				}
+			})
+		}
+	}
+}
+
+// TestLengthLong runs 10 minutes through the non-integer ratios in 100ms
+// chunks, where rounding in the phase step would show up as drift
+func TestLengthLong(t *testing.T) {
+	if testing.Short() {
+		t.Skip("10 minutes of audio per ratio")
+	}
+	for _, c := range ratios {
+		if c.src%c.dst == 0 || c.dst%c.src == 0 {
+			continue
+		}
+		t.Run(fmt.Sprintf("%d-%d", c.src, c.dst), func(t *testing.T) {
+			r, err := New(c.src, c.dst, Medium)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// One second of tone, fed 600 times
+			second := sine(c.src, c.src, 440)
+			chunk := c.src / 10
+			var n int
+			for s := 0; s < 600; s++ {
+				for off := 0; off < len(second); off += chunk {
+					n += len(r.Process(second[off:min(off+chunk, len(second))]))
+				}
+			}
+			n += len(r.Flush())
+			in := 600 * c.src
+			want := int(math.Ceil(float64(in) * float64(c.dst) / float64(c.src)))
+			if n != want {
+				t.Errorf("%d input samples gave %d output, want %d", in, n, want)
+			}
+		})
+	}
+}
+
+// TestPreservesSignal checks DC stays DC and a tone keeps its level and
+// frequency, for every ratio and quality
+func TestPreservesSignal(t *testing.T) {
+	for _, c := range ratios {
+		for _, q := range []Quality{Low, Medium, High} {
+			t.Run(fmt.Sprintf("%d-%d/%s", c.src, c.dst, q), func(t *testing.T) {
+				r, _ := New(c.src, c.dst, q)
+				dc := make([]int16, c.src)
+				for i := range dc {
+					dc[i] = 10000
+				}
+				out := r.Process(dc)
+				// Skip the filter's warm-up from the silent pre-roll: Taps
+				// input samples, in output samples
+				warm := r.Taps()*c.dst/c.src + 1
+				for i, s := range out[warm:] {
+					if s < 9990 || s > 10010 {
+						t.Fatalf("DC sample %d = %d, want 10000", i, s)
+					}
+				}
+
+				r.Reset()
+				out = r.Process(sine(c.src, c.src, 1000))
+				out = out[warm : len(out)-warm]
+				got, level := goertzel(out, c.dst, 1000)
+				if got < 0.95*level || level < 16384*0.95/math.Sqrt2 {
+					t.Errorf("1kHz holds %.0f of %.0f RMS, want a 1kHz tone at -6dBFS", got, level)
+				}
+			})
+		}
+	}
+}
+
+// goertzel returns the RMS of out at freq and its total RMS
+func goertzel(out []int16, rate int, freq float64) (atFreq, total float64) {
+	w := 2 * math.Pi * freq / float64(rate)
+	var re, im, sumSq float64
+	for i, s := range out {
+		v := float64(s)
+		re += v * math.Cos(w*float64(i))
+		im += v * math.Sin(w*float64(i))
+		sumSq += v * v
+	}
+	n := float64(len(out))
+	return math.Hypot(re, im) / n * math.Sqrt2, math.Sqrt(sumSq / n)
+}
+
+func TestFlushResets(t *testing.T) {
+	r, _ := New(44100, 16000, Medium)
+	in := sine(4410, 44100, 440)
+	first := append(r.Process(in), r.Flush()...)
+	second := append(r.Process(in), r.Flush()...)
+	if len(first) != len(second) {
+		t.Fatalf("second stream gave %d samples, first %d", len(second), len(first))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("sample %d differs after Flush: %d vs %d", i, second[i], first[i])
+		}
+	}
+}
+
+// BenchmarkProcess reports the cost of resampling one second of audio in
+// 10ms chunks, as the bridges do; ns/op is CPU time per second of audio
+func BenchmarkProcess(b *testing.B) {
+	for _, c := range []struct{ src, dst int }{
+		{16000, 48000},
+		{48000, 16000},
+		{44100, 16000},
+		{22050, 16000},
+		{8000, 16000},
+	} {
+		for _, q := range []Quality{Low, Medium, High} {
+			b.Run(fmt.Sprintf("%d-%d/%s", c.src, c.dst, q), func(b *testing.B) {
+				r, err := New(c.src, c.dst, q)
+				if err != nil {
+					b.Fatal(err)
+				}
+				in := sine(c.src, c.src, 440)
+				chunk := c.src / 100
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for off := 0; off+chunk <= len(in); off += chunk {
+						r.Process(in[off : off+chunk])
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkNew is the cost of a resampler for an odd ratio once its filter
+// bank is cached
+func BenchmarkNew(b *testing.B) {
+	if _, err := New(44100, 16000, High); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		New(44100, 16000, High)
+	}
+}
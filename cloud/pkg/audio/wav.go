@@ -0,0 +1,182 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WAVUnknownDataSize is the data chunk size written by streaming encoders
+// that don't know the final length
+const WAVUnknownDataSize = 0xFFFFFFFF
+
+// MaxWAVSampleRate is the highest sample rate ReadWAVHeader accepts. Rates
+// above it are corrupt or hostile headers, not audio anyone means to play.
+const MaxWAVSampleRate = 384000
+
+// WAVHeader describes a WAV stream up to the start of its data chunk
+type WAVHeader struct {
+	AudioFormat   uint16 // 1 = integer PCM
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+	DataSize      uint32
+
+	// Bytes the fmt chunk declared but didn't contain. Some writers
+	// declare an extended fmt and only write the 16 PCM fields.
+	FmtShortBy int
+}
+
+// BlockAlign is the size of one frame (a sample for every channel) in bytes
+func (h *WAVHeader) BlockAlign() int {
+	return h.BitsPerSample / 8 * h.Channels
+}
+
+// DataLength is how many data bytes to read: DataSize, or unbounded (read
+// to EOF) when the writer left the WAVUnknownDataSize placeholder. A zero
+// size is a real, empty data chunk.
+func (h *WAVHeader) DataLength() int64 {
+	if h.DataSize == WAVUnknownDataSize {
+		return math.MaxInt64
+	}
+	return int64(h.DataSize)
+}
+
+// WAVError reports which part of a WAV header couldn't be read. Code is a
+// short snake_case identifier (e.g. "wav_fmt_short") fit for error details
+// sent to clients.
+type WAVError struct {
+	Code string
+	Err  error
+}
+
+func (e *WAVError) Error() string {
+	if e.Err == nil {
+		return e.Code
+	}
+	return e.Code + ": " + e.Err.Error()
+}
+
+func (e *WAVError) Unwrap() error {
+	return e.Err
+}
+
+func wavError(code string, err error) error {
+	return &WAVError{Code: code, Err: err}
+}
+
+// ReadWAVHeader parses the RIFF header and chunks up to the data chunk,
+// leaving br at the first data byte. The format is not validated beyond
+// what's needed to parse it and a sample rate in 1..MaxWAVSampleRate;
+// callers check AudioFormat, BitsPerSample and Channels against what they
+// support.
+func ReadWAVHeader(br *bufio.Reader) (*WAVHeader, error) {
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, wavError("wav_header_read", err)
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, wavError("wav_not_riff_wave", nil)
+	}
+
+	var h WAVHeader
+	haveFmt := false
+	for {
+		// Each chunk: 4-byte id + 4-byte size
+		hdr := make([]byte, 8)
+		if _, err := io.ReadFull(br, hdr); err != nil {
+			return nil, wavError("wav_chunk_header", err)
+		}
+		id := string(hdr[0:4])
+		size := binary.LittleEndian.Uint32(hdr[4:8])
+
+		switch id {
+		case "fmt ":
+			// AudioFormat (2), NumChannels (2), SampleRate (4), ByteRate (4), BlockAlign (2), BitsPerSample (2)
+			if size < 16 {
+				return nil, wavError("wav_fmt_short", nil)
+			}
+			buf := make([]byte, 16)
+			if _, err := io.ReadFull(br, buf); err != nil {
+				return nil, wavError("wav_fmt_read", err)
+			}
+			// Extended fmt data isn't used; tolerate it being short
+			missing, err := readFmtExtension(br, int(size)-16)
+			if err != nil {
+				return nil, wavError("wav_fmt_read", err)
+			}
+			if missing == 0 && size%2 == 1 {
+				// Chunks are padded to even sizes
+				if _, err := br.ReadByte(); err != nil {
+					return nil, wavError("wav_fmt_pad", err)
+				}
+			}
+			h.AudioFormat = binary.LittleEndian.Uint16(buf[0:2])
+			h.Channels = int(binary.LittleEndian.Uint16(buf[2:4]))
+			h.SampleRate = int(binary.LittleEndian.Uint32(buf[4:8]))
+			if h.SampleRate <= 0 || h.SampleRate > MaxWAVSampleRate {
+				return nil, wavError("wav_sample_rate", fmt.Errorf("sample rate %d", h.SampleRate))
+			}
+			h.BitsPerSample = int(binary.LittleEndian.Uint16(buf[14:16]))
+			h.FmtShortBy = missing
+			haveFmt = true
+
+		case "data":
+			if !haveFmt {
+				return nil, wavError("wav_missing_fmt_or_data", errors.New("data chunk before fmt"))
+			}
+			h.DataSize = size
+			return &h, nil
+
+		default:
+			if _, err := io.CopyN(io.Discard, br, int64(size)); err != nil {
+				return nil, wavError("wav_skip_chunk", err)
+			}
+			if size%2 == 1 {
+				if _, err := br.ReadByte(); err != nil {
+					return nil, wavError("wav_skip_pad", err)
+				}
+			}
+		}
+	}
+}
+
+// wavChunkIDs are chunk ids that may follow fmt; seeing one inside a
+// declared fmt extension means the extension was never written
+var wavChunkIDs = []string{"data", "fact", "LIST", "JUNK", "PEAK", "bext", "cue ", "smpl", "id3 "}
+
+// readFmtExtension consumes the ext bytes a fmt chunk declares past the 16
+// PCM fields. When the next chunk header turns up inside the declared
+// extension only the bytes before it are consumed. Returns how many
+// declared bytes were missing.
+func readFmtExtension(br *bufio.Reader, ext int) (int, error) {
+	if ext <= 0 {
+		return 0, nil
+	}
+	if ext+3 > br.Size() {
+		_, err := br.Discard(ext)
+		return 0, err
+	}
+	// Look 3 bytes past the extension so a header starting inside it is
+	// seen whole
+	peek, err := br.Peek(ext + 3)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	for off := 0; off < ext && off+4 <= len(peek); off += 2 {
+		for _, id := range wavChunkIDs {
+			if string(peek[off:off+4]) == id {
+				_, err := br.Discard(off)
+				return ext - off, err
+			}
+		}
+	}
+	n := min(ext, len(peek))
+	if _, err := br.Discard(n); err != nil {
+		return 0, err
+	}
+	return ext - n, nil
+}
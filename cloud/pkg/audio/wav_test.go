@@ -0,0 +1,160 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+)
+
+// chunk is a RIFF chunk: id, little-endian size, body and the pad byte
+// odd sizes need. size overrides len(body) when non-zero.
+func chunk(id string, size uint32, body []byte) []byte {
+	if size == 0 {
+		size = uint32(len(body))
+	}
+	out := append([]byte(id), binary.LittleEndian.AppendUint32(nil, size)...)
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func fmtBody(format, channels uint16, rate uint32, bits uint16) []byte {
+	b := binary.LittleEndian.AppendUint16(nil, format)
+	b = binary.LittleEndian.AppendUint16(b, channels)
+	b = binary.LittleEndian.AppendUint32(b, rate)
+	b = binary.LittleEndian.AppendUint32(b, rate*uint32(channels)*uint32(bits)/8)
+	b = binary.LittleEndian.AppendUint16(b, channels*bits/8)
+	return binary.LittleEndian.AppendUint16(b, bits)
+}
+
+func riff(chunks ...[]byte) []byte {
+	out := []byte("RIFF\x00\x00\x00\x00WAVE")
+	for _, c := range chunks {
+		out = append(out, c...)
+	}
+	return out
+}
+
+func TestReadWAVHeader(t *testing.T) {
+	pcm := fmtBody(1, 1, 16000, 16)
+	data := []byte{1, 2, 3, 4}
+
+	tests := []struct {
+		name     string
+		in       []byte
+		want     WAVHeader
+		wantCode string
+		wantData []byte // first bytes left in the reader
+	}{
+		{
+			name:     "plain",
+			in:       riff(chunk("fmt ", 0, pcm), chunk("data", 0, data)),
+			want:     WAVHeader{AudioFormat: 1, Channels: 1, SampleRate: 16000, BitsPerSample: 16, DataSize: 4},
+			wantData: data,
+		},
+		{
+			name:     "stereo 44.1k",
+			in:       riff(chunk("fmt ", 0, fmtBody(1, 2, 44100, 16)), chunk("data", 0, data)),
+			want:     WAVHeader{AudioFormat: 1, Channels: 2, SampleRate: 44100, BitsPerSample: 16, DataSize: 4},
+			wantData: data,
+		},
+		{
+			name:     "skips odd-sized chunk and its pad byte",
+			in:       riff(chunk("fmt ", 0, pcm), chunk("LIST", 0, []byte{9, 9, 9}), chunk("data", 0, data)),
+			want:     WAVHeader{AudioFormat: 1, Channels: 1, SampleRate: 16000, BitsPerSample: 16, DataSize: 4},
+			wantData: data,
+		},
+		{
+			name:     "extended fmt",
+			in:       riff(chunk("fmt ", 0, append(append([]byte{}, pcm...), 0, 0)), chunk("data", 0, data)),
+			want:     WAVHeader{AudioFormat: 1, Channels: 1, SampleRate: 16000, BitsPerSample: 16, DataSize: 4},
+			wantData: data,
+		},
+		{
+			name:     "fmt declares an extension it doesn't contain",
+			in:       riff(chunk("fmt ", 18, pcm), chunk("data", 0, data)),
+			want:     WAVHeader{AudioFormat: 1, Channels: 1, SampleRate: 16000, BitsPerSample: 16, DataSize: 4, FmtShortBy: 2},
+			wantData: data,
+		},
+		{
+			name: "streaming placeholder size",
+			in:   riff(chunk("fmt ", 0, pcm), chunk("data", WAVUnknownDataSize, nil), data),
+			want: WAVHeader{AudioFormat: 1, Channels: 1, SampleRate: 16000, BitsPerSample: 16, DataSize: WAVUnknownDataSize},
+		},
+		{name: "not riff", in: []byte("RIFX\x00\x00\x00\x00WAVE"), wantCode: "wav_not_riff_wave"},
+		{name: "truncated riff header", in: []byte("RIFF"), wantCode: "wav_header_read"},
+		{name: "fmt too short", in: riff(chunk("fmt ", 0, pcm[:14])), wantCode: "wav_fmt_short"},
+		{name: "data before fmt", in: riff(chunk("data", 0, data)), wantCode: "wav_missing_fmt_or_data"},
+		{name: "no data chunk", in: riff(chunk("fmt ", 0, pcm)), wantCode: "wav_chunk_header"},
+		{name: "zero sample rate", in: riff(chunk("fmt ", 0, fmtBody(1, 1, 0, 16)), chunk("data", 0, data)), wantCode: "wav_sample_rate"},
+		{name: "absurd sample rate", in: riff(chunk("fmt ", 0, fmtBody(1, 1, MaxWAVSampleRate+1, 16)), chunk("data", 0, data)), wantCode: "wav_sample_rate"},
+		{name: "truncated skipped chunk", in: riff(chunk("fmt ", 0, pcm), chunk("LIST", 100, []byte{1, 2})[:10]), wantCode: "wav_skip_chunk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := bufio.NewReader(bytes.NewReader(tt.in))
+			h, err := ReadWAVHeader(br)
+			if tt.wantCode != "" {
+				var wavErr *WAVError
+				if !errors.As(err, &wavErr) || wavErr.Code != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *h != tt.want {
+				t.Errorf("header = %+v, want %+v", *h, tt.want)
+			}
+			if tt.wantData != nil {
+				rest, _ := io.ReadAll(br)
+				if !bytes.HasPrefix(rest, tt.wantData) {
+					t.Errorf("reader left at %v, want data %v", rest, tt.wantData)
+				}
+			}
+		})
+	}
+}
+
+func TestWAVHeaderDataLength(t *testing.T) {
+	tests := []struct {
+		size uint32
+		want int64
+	}{
+		{0, 0},
+		{4, 4},
+		{0x7FFFFFFF, 0x7FFFFFFF},
+		{WAVUnknownDataSize - 1, WAVUnknownDataSize - 1},
+		{WAVUnknownDataSize, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		h := WAVHeader{DataSize: tt.size}
+		if got := h.DataLength(); got != tt.want {
+			t.Errorf("DataLength(%#x) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestWAVHeaderBlockAlign(t *testing.T) {
+	tests := []struct {
+		bits, channels, want int
+	}{
+		{16, 1, 2},
+		{16, 2, 4},
+		{24, 6, 18},
+		{8, 1, 1},
+	}
+	for _, tt := range tests {
+		h := WAVHeader{BitsPerSample: tt.bits, Channels: tt.channels}
+		if got := h.BlockAlign(); got != tt.want {
+			t.Errorf("BlockAlign(%d bits, %d ch) = %d, want %d", tt.bits, tt.channels, got, tt.want)
+		}
+	}
+}
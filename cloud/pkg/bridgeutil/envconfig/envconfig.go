@@ -0,0 +1,159 @@
+// Package envconfig reads service settings from environment variables, with
+// an optional CONFIG_FILE supplying defaults for any of them and *_FILE
+// variants for secrets mounted as files.
+package envconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileValues holds values from CONFIG_FILE, keyed by env var name. Env vars
+// override them; see Lookup.
+var fileValues map[string]string
+
+// Load reads the file named by CONFIG_FILE, if set, so later lookups fall
+// back to it. Calling it again rereads the file.
+func Load() error {
+	fileValues = nil
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		return nil
+	}
+	values, err := ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("CONFIG_FILE: %w", err)
+	}
+	fileValues = values
+	return nil
+}
+
+// ReadFile parses a flat YAML or JSON (by extension) mapping of env var
+// names to values, e.g.
+//
+//	LIVEKIT_URL: wss://livekit.internal
+//	PUBLISH_GAIN: 1.5
+//	AUDIO_PROCESSORS: [gain, watermark]
+//
+// Values keep the text they were written with, so 1000000 stays 1000000
+// rather than becoming 1e+06. Lists are joined with commas, matching the
+// env var form.
+func ReadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		values, err = parseJSON(data)
+	default:
+		values, err = parseYAML(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	raw := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		switch v := v.(type) {
+		case nil:
+		case []interface{}:
+			parts := make([]string, len(v))
+			for i, p := range v {
+				parts[i] = fmt.Sprint(p)
+			}
+			values[key] = strings.Join(parts, ",")
+		case map[string]interface{}:
+			return nil, fmt.Errorf("%s must be a scalar or a list", key)
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
+
+func parseYAML(data []byte) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	if len(doc.Content) == 0 {
+		return values, nil // empty file
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level must be a mapping")
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, v := root.Content[i].Value, root.Content[i+1]
+		switch v.Kind {
+		case yaml.ScalarNode:
+			if v.Tag != "!!null" {
+				values[key] = v.Value
+			}
+		case yaml.SequenceNode:
+			parts := make([]string, len(v.Content))
+			for j, p := range v.Content {
+				if p.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("%s must be a list of scalars", key)
+				}
+				parts[j] = p.Value
+			}
+			values[key] = strings.Join(parts, ",")
+		default:
+			return nil, fmt.Errorf("%s must be a scalar or a list", key)
+		}
+	}
+	return values, nil
+}
+
+// Lookup returns the env var if set, otherwise the CONFIG_FILE value
+func Lookup(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fileValues[key]
+}
+
+// Get returns Lookup(key), or defaultValue when that is empty
+func Get(key, defaultValue string) string {
+	if value := Lookup(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Secret returns key's value, or the trimmed contents of the file named by
+// key+"_FILE" (as mounted by Docker and Kubernetes secrets) when key itself
+// is unset. An unreadable file is an error rather than an empty secret.
+func Secret(key string) (string, error) {
+	if value := Lookup(key); value != "" {
+		return value, nil
+	}
+	path := Lookup(key + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %w", key, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
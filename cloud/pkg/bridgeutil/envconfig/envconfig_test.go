@@ -0,0 +1,113 @@
+package envconfig
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "yaml",
+			file:    "c.yaml",
+			content: "LIVEKIT_URL: wss://lk.internal\nPUBLISH_GAIN: 1.5\nBIG: 1000000\nON: true\nAUDIO_PROCESSORS: [gain, watermark]\nUNSET: ~\n",
+			want: map[string]string{
+				"LIVEKIT_URL": "wss://lk.internal", "PUBLISH_GAIN": "1.5", "BIG": "1000000",
+				"ON": "true", "AUDIO_PROCESSORS": "gain,watermark",
+			},
+		},
+		{
+			name:    "json keeps number text",
+			file:    "c.json",
+			content: `{"BIG": 1000000, "GAIN": 1.5, "ON": false, "LIST": ["a", 2], "UNSET": null}`,
+			want:    map[string]string{"BIG": "1000000", "GAIN": "1.5", "ON": "false", "LIST": "a,2"},
+		},
+		{name: "empty yaml", file: "c.yml", content: "", want: map[string]string{}},
+		{name: "yaml nested map", file: "c.yaml", content: "A:\n  B: 1\n", wantErr: true},
+		{name: "yaml list of maps", file: "c.yaml", content: "A:\n  - B: 1\n", wantErr: true},
+		{name: "yaml not a mapping", file: "c.yaml", content: "- a\n- b\n", wantErr: true},
+		{name: "json nested map", file: "c.json", content: `{"A": {"B": 1}}`, wantErr: true},
+		{name: "bad json", file: "c.json", content: `{"A": `, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadFile(writeFile(t, tt.file, tt.content))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ReadFile = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("ReadFile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupPrecedence(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "c.yaml", "FROM_FILE: file\nBOTH: file\n"))
+	t.Setenv("BOTH", "env")
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fileValues = nil })
+
+	tests := []struct{ key, want string }{
+		{"FROM_FILE", "file"},
+		{"BOTH", "env"},
+		{"NEITHER", ""},
+	}
+	for _, tt := range tests {
+		if got := Lookup(tt.key); got != tt.want {
+			t.Errorf("Lookup(%s) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+	if got := Get("NEITHER", "default"); got != "default" {
+		t.Errorf("Get default = %q", got)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	t.Setenv("S_DIRECT", "direct")
+	t.Setenv("S_DIRECT_FILE", writeFile(t, "ignored", "from-file"))
+	t.Setenv("S_FILE_ONLY_FILE", writeFile(t, "secret", "  from-file\n"))
+	t.Setenv("S_MISSING_FILE", filepath.Join(t.TempDir(), "nope"))
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "S_DIRECT", want: "direct"},
+		{key: "S_FILE_ONLY", want: "from-file"},
+		{key: "S_UNSET", want: ""},
+		{key: "S_MISSING", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Secret(tt.key)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Secret(%s) = %q, %v; want %q, error %v", tt.key, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/Mentra-Community/MentraOS/cloud/pkg/bridgeutil
+
+go 1.24.2
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,118 @@
+// Package registry provides a concurrency-safe map of per-user entries
+// (clients, room sessions) that keeps capacity metrics.
+package registry
+
+import "sync"
+
+// Registry is a concurrency-safe map keyed by user ID. It keeps capacity
+// metrics so callers don't each track them with their own locking.
+type Registry[V comparable] struct {
+	mu      sync.RWMutex
+	items   map[string]V
+	peak    int
+	created int64
+}
+
+// Stats is a snapshot of a registry's capacity metrics
+type Stats struct {
+	Size    int   `json:"size"`
+	Peak    int   `json:"peak"`
+	Created int64 `json:"created"`
+}
+
+// New returns an empty registry
+func New[V comparable]() *Registry[V] {
+	return &Registry[V]{items: make(map[string]V)}
+}
+
+// Get returns the entry stored under key
+func (r *Registry[V]) Get(key string) (V, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.items[key]
+	return v, ok
+}
+
+// Put stores v under key, returning any entry it replaced
+func (r *Registry[V]) Put(key string, v V) (V, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, replaced := r.items[key]
+	r.store(key, v)
+	return prev, replaced
+}
+
+// PutIfAbsent stores v only if key is free. Returns false if it was taken.
+func (r *Registry[V]) PutIfAbsent(key string, v V) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.items[key]; exists {
+		return false
+	}
+	r.store(key, v)
+	return true
+}
+
+// store must be called with mu held
+func (r *Registry[V]) store(key string, v V) {
+	r.items[key] = v
+	r.created++
+	if len(r.items) > r.peak {
+		r.peak = len(r.items)
+	}
+}
+
+// Delete removes key, returning the entry it held
+func (r *Registry[V]) Delete(key string) (V, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.items[key]
+	delete(r.items, key)
+	return v, ok
+}
+
+// DeleteIf removes key only while it still maps to v, so cleanup of a
+// replaced entry can't remove its replacement
+func (r *Registry[V]) DeleteIf(key string, v V) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cur, ok := r.items[key]; !ok || cur != v {
+		return false
+	}
+	delete(r.items, key)
+	return true
+}
+
+// Len is the number of entries
+func (r *Registry[V]) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.items)
+}
+
+// Each calls fn for every entry until it returns false. It iterates a
+// snapshot, so fn may call back into the registry.
+func (r *Registry[V]) Each(fn func(key string, v V) bool) {
+	r.mu.RLock()
+	keys := make([]string, 0, len(r.items))
+	vals := make([]V, 0, len(r.items))
+	for k, v := range r.items {
+		keys = append(keys, k)
+		vals = append(vals, v)
+	}
+	r.mu.RUnlock()
+
+	for i := range keys {
+		if !fn(keys[i], vals[i]) {
+			return
+		}
+	}
+}
+
+// Stats returns the current size, the peak size and how many entries were
+// ever stored
+func (r *Registry[V]) Stats() Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return Stats{Size: len(r.items), Peak: r.peak, Created: r.created}
+}
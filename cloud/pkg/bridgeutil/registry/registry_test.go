@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"sync"
+	"testing"
+)
+
+type entry struct{ id int }
+
+func TestRegistry(t *testing.T) {
+	r := New[*entry]()
+	a, b := &entry{1}, &entry{2}
+
+	if _, replaced := r.Put("u1", a); replaced {
+		t.Error("Put into an empty registry replaced something")
+	}
+	if r.PutIfAbsent("u1", b) {
+		t.Error("PutIfAbsent over a taken key succeeded")
+	}
+	if prev, replaced := r.Put("u1", b); !replaced || prev != a {
+		t.Errorf("Put = %v, %v; want a replaced", prev, replaced)
+	}
+	if r.DeleteIf("u1", a) {
+		t.Error("DeleteIf removed the replacement of a stale entry")
+	}
+	if v, ok := r.Get("u1"); !ok || v != b {
+		t.Errorf("Get = %v, %v; want b", v, ok)
+	}
+	if !r.PutIfAbsent("u2", a) {
+		t.Error("PutIfAbsent on a free key failed")
+	}
+
+	want := Stats{Size: 2, Peak: 2, Created: 3}
+	if got := r.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+
+	if !r.DeleteIf("u1", b) {
+		t.Error("DeleteIf of the current entry failed")
+	}
+	if v, ok := r.Delete("u2"); !ok || v != a {
+		t.Errorf("Delete = %v, %v; want a", v, ok)
+	}
+	if _, ok := r.Delete("u2"); ok {
+		t.Error("second Delete found the entry")
+	}
+	want = Stats{Size: 0, Peak: 2, Created: 3}
+	if got := r.Stats(); got != want || r.Len() != 0 {
+		t.Errorf("Stats = %+v, Len = %d; want %+v", got, r.Len(), want)
+	}
+}
+
+func TestRegistryEachAllowsReentry(t *testing.T) {
+	r := New[*entry]()
+	for i, k := range []string{"a", "b", "c"} {
+		r.Put(k, &entry{i})
+	}
+	seen := 0
+	r.Each(func(key string, v *entry) bool {
+		r.Delete(key) // would deadlock without the snapshot
+		seen++
+		return seen < 2
+	})
+	if seen != 2 || r.Len() != 1 {
+		t.Errorf("Each visited %d and left %d, want 2 and 1", seen, r.Len())
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	r := New[int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := string(rune('a' + i))
+			for j := 0; j < 1000; j++ {
+				r.Put(key, j)
+				r.Get(key)
+				r.Each(func(string, int) bool { return true })
+				r.DeleteIf(key, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := r.Stats(); got.Created != 8000 || got.Size != 0 {
+		t.Errorf("Stats = %+v, want 8000 created and none left", got)
+	}
+}
@@ -0,0 +1,52 @@
+// Package retry repeats operations that fail transiently, such as LiveKit
+// publishes and object store requests.
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Transient calls fn up to attempts times, doubling delay between tries,
+// and returns the last error. It stops early if ctx is done or fn returns
+// an error marked with Permanent.
+func Transient(ctx context.Context, attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if i == attempts-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// Permanent marks err as not worth retrying, e.g. a 4xx response. Transient
+// returns err itself, unwrapped. Permanent(nil) is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
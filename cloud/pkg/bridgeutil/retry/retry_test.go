@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTransient(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name      string
+		attempts  int
+		failures  int   // calls that fail before one succeeds
+		failWith  error // error returned by failing calls
+		wantCalls int
+		wantErr   error
+	}{
+		{"first try", 3, 0, errBoom, 1, nil},
+		{"succeeds on retry", 3, 2, errBoom, 3, nil},
+		{"gives up", 3, 5, errBoom, 3, errBoom},
+		{"single attempt", 1, 5, errBoom, 1, errBoom},
+		{"permanent stops at once", 3, 5, Permanent(errBoom), 1, errBoom},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Transient(context.Background(), tt.attempts, time.Millisecond, func() error {
+				calls++
+				if calls <= tt.failures {
+					return tt.failWith
+				}
+				return nil
+			})
+			if err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTransientStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Transient(ctx, 5, time.Hour, func() error {
+		calls++
+		cancel()
+		return errors.New("boom")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("err = %v after %d calls, want the error after 1", err, calls)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+	errBoom := errors.New("boom")
+	if err := Permanent(errBoom); !errors.Is(err, errBoom) || err.Error() != "boom" {
+		t.Errorf("Permanent(boom) = %v, want it to wrap boom", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package g711track is a LiveKit publish track that sends PCMU or PCMA, for
+// SIP gateways that expect G.711 instead of Opus
+package g711track
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+	"github.com/livekit/media-sdk"
+	"github.com/livekit/media-sdk/g711"
+	"github.com/pion/webrtc/v4"
+	pionmedia "github.com/pion/webrtc/v4/pkg/media"
+)
+
+// Codec names, as clients pass them in join options
+const (
+	PCMU = "pcmu"
+	PCMA = "pcma"
+)
+
+// Packets carry 20ms at 8kHz
+const (
+	Rate          = 8000
+	packetSamples = Rate / 50
+	packetTime    = 20 * time.Millisecond
+	maxPending    = Rate * 5 // drop audio queued more than 5s ahead
+)
+
+// ErrClosed is returned by WriteSample after Close
+var ErrClosed = errors.New("g711 track closed")
+
+// Track accepts PCM at the rate given to New and paces 20ms G.711 packets
+// out in real time, like PCMLocalTrack does for Opus. Audio is resampled
+// to 8kHz first.
+type Track struct {
+	*webrtc.TrackLocalStaticSample
+
+	alaw      bool
+	mu        sync.Mutex
+	rs        *resample.Resampler
+	pending   []int16 // 8kHz samples not yet sent
+	sendErr   error   // first packet write failure since the last WriteSample
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a PCMU or PCMA track fed with mono PCM at inputRate
+func New(codec string, inputRate int, quality resample.Quality) (*Track, error) {
+	var mime string
+	switch codec {
+	case PCMU:
+		mime = webrtc.MimeTypePCMU
+	case PCMA:
+		mime = webrtc.MimeTypePCMA
+	default:
+		return nil, fmt.Errorf("unknown g711 codec %q", codec)
+	}
+	rs, err := resample.New(inputRate, Rate, quality)
+	if err != nil {
+		return nil, err
+	}
+	id := strconv.FormatInt(time.Now().UnixNano(), 36)
+	track, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: mime, ClockRate: Rate, Channels: 1},
+		"go_track"+id, "go_stream"+id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	t := &Track{
+		TrackLocalStaticSample: track,
+		alaw:                   codec == PCMA,
+		rs:                     rs,
+		done:                   make(chan struct{}),
+	}
+	go t.run()
+	return t, nil
+}
+
+// WriteSample queues PCM for sending. Packets go out asynchronously, so a
+// failed send is reported by the next WriteSample.
+func (t *Track) WriteSample(chunk media.PCM16Sample) error {
+	select {
+	case <-t.done:
+		return ErrClosed
+	default:
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.sendErr; err != nil {
+		t.sendErr = nil
+		return fmt.Errorf("send g711 packet: %w", err)
+	}
+	t.pending = append(t.pending, t.rs.Process(chunk)...)
+	if over := len(t.pending) - maxPending; over > 0 {
+		t.pending = append(t.pending[:0], t.pending[over:]...)
+	}
+	return nil
+}
+
+// run sends one 20ms packet per tick while audio is queued
+func (t *Track) run() {
+	ticker := time.NewTicker(packetTime)
+	defer ticker.Stop()
+	frame := make([]int16, packetSamples)
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+
+		t.mu.Lock()
+		if len(t.pending) < packetSamples {
+			t.mu.Unlock()
+			continue
+		}
+		copy(frame, t.pending)
+		t.pending = append(t.pending[:0], t.pending[packetSamples:]...)
+		t.mu.Unlock()
+
+		payload := make([]byte, packetSamples)
+		if t.alaw {
+			g711.EncodeALawTo(payload, frame)
+		} else {
+			g711.EncodeULawTo(payload, frame)
+		}
+		if err := t.TrackLocalStaticSample.WriteSample(pionmedia.Sample{Data: payload, Duration: packetTime}); err != nil {
+			t.mu.Lock()
+			if t.sendErr == nil {
+				t.sendErr = err
+			}
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Close stops sending; queued audio is dropped
+func (t *Track) Close() {
+	t.closeOnce.Do(func() { close(t.done) })
+}
@@ -0,0 +1,63 @@
+package g711track
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio/resample"
+)
+
+func TestNewRejectsUnknownCodec(t *testing.T) {
+	if _, err := New("opus", 16000, resample.Medium); err == nil {
+		t.Fatal("New(opus) succeeded")
+	}
+}
+
+func TestWriteSampleCapsQueue(t *testing.T) {
+	tr, err := New(PCMU, 16000, resample.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	// 10s at 16kHz: twice what may be queued
+	if err := tr.WriteSample(make([]int16, 160000)); err != nil {
+		t.Fatal(err)
+	}
+	tr.mu.Lock()
+	n := len(tr.pending)
+	tr.mu.Unlock()
+	if n > maxPending {
+		t.Errorf("pending = %d samples, want at most %d", n, maxPending)
+	}
+}
+
+func TestWriteSampleReportsSendError(t *testing.T) {
+	tr, err := New(PCMA, 16000, resample.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	tr.mu.Lock()
+	tr.sendErr = io.ErrClosedPipe
+	tr.mu.Unlock()
+
+	if err := tr.WriteSample(make([]int16, 320)); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("err = %v, want the send error", err)
+	}
+	if err := tr.WriteSample(make([]int16, 320)); err != nil {
+		t.Fatalf("send error reported twice: %v", err)
+	}
+}
+
+func TestWriteSampleAfterClose(t *testing.T) {
+	tr, err := New(PCMU, 16000, resample.Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.Close()
+	tr.Close()
+	if err := tr.WriteSample(make([]int16, 320)); !errors.Is(err, ErrClosed) {
+		t.Fatalf("err = %v, want ErrClosed", err)
+	}
+}
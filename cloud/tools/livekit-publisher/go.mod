@@ -5,6 +5,7 @@ go 1.24.2
 toolchain go1.24.6
 
 require (
+	github.com/Mentra-Community/MentraOS/cloud/pkg/audio v0.0.0-00010101000000-000000000000
 	github.com/joho/godotenv v1.5.1
 	github.com/livekit/protocol v1.39.4-0.20250807105828-ccbae8154e54
 	github.com/livekit/server-sdk-go/v2 v2.10.0
@@ -83,3 +84,5 @@ require (
 	gopkg.in/hraban/opus.v2 v2.0.0-20230925203106-0188a62cb302 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/Mentra-Community/MentraOS/cloud/pkg/audio => ../../pkg/audio
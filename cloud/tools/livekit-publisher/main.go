@@ -1,8 +1,8 @@
 package main
 
 import (
+	"bufio"
 	"encoding/base64"
-	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
 	"github.com/joho/godotenv"
 	lkauth "github.com/livekit/protocol/auth"
 	lksdk "github.com/livekit/server-sdk-go/v2"
@@ -39,6 +40,7 @@ var (
 	flagOnce      bool
 	flagFrameMs   int
 	flagLogEvery  int
+	flagMaxMemMB  int
 )
 
 func init() {
@@ -54,6 +56,7 @@ func init() {
 	flag.BoolVar(&flagOnce, "once", false, "Play the WAV only once (default: loop)")
 	flag.IntVar(&flagFrameMs, "frame-ms", 10, "Frame size in ms (typ 10)")
 	flag.IntVar(&flagLogEvery, "log-every", 100, "Log every N frames (0=disable)")
+	flag.IntVar(&flagMaxMemMB, "max-memory-mb", 64, "Stream WAV data larger than this from disk instead of loading it (0=always stream)")
 }
 
 func main() {
@@ -96,11 +99,11 @@ func run() error {
 	}
 	abs, _ := filepath.Abs(flagWavPath)
 	log.Printf("wav file: %s", abs)
-	wav, err := loadWAV(flagWavPath)
+	wav, err := loadWAV(flagWavPath, int64(flagMaxMemMB)<<20)
 	if err != nil {
 		return fmt.Errorf("load wav: %w", err)
 	}
-	log.Printf("wav: sr=%d ch=%d bits=%d bytes=%d dur=%.2fs", wav.SampleRate, wav.Channels, wav.BitsPerSample, len(wav.Data), wav.DurationSeconds())
+	log.Printf("wav: sr=%d ch=%d bits=%d bytes=%d dur=%.2fs streamed=%v", wav.SampleRate, wav.Channels, wav.BitsPerSample, wav.DataSize, wav.DurationSeconds(), wav.Data == nil)
 	if wav.BitsPerSample != 16 {
 		return fmt.Errorf("need 16-bit pcm, got %d", wav.BitsPerSample)
 	}
@@ -160,14 +163,18 @@ func run() error {
 		return fmt.Errorf("bad samplesPerFrame calc")
 	}
 	log.Printf("frame: %d ms = %d samples (%d bytes)", flagFrameMs, samplesPerFrame, samplesPerFrame*2)
-	totalSamples := len(wav.Data) / 2
-	pcm := make([]int16, totalSamples)
-	for i := 0; i < totalSamples; i++ {
-		pcm[i] = int16(binary.LittleEndian.Uint16(wav.Data[i*2 : i*2+2]))
-	}
-	if flagGain != 1.0 {
-		applyGain(pcm, flagGain)
+	var src pcmSource
+	if wav.Data != nil {
+		src = newMemorySource(wav.Data, flagGain)
+	} else {
+		streamSrc, err := newStreamSource(flagWavPath, wav.DataOffset, wav.DataSize, flagGain)
+		if err != nil {
+			return fmt.Errorf("open wav stream: %w", err)
+		}
+		defer streamSrc.Close()
+		src = streamSrc
 	}
+	frame := make([]int16, samplesPerFrame)
 	frameIndex := 0
 	loopCount := 0
 	ticker := time.NewTicker(time.Duration(flagFrameMs) * time.Millisecond)
@@ -176,22 +183,25 @@ func run() error {
 	log.Printf("starting playback; loop=%v", !flagOnce)
 	for {
 		<-ticker.C
-		startSample := frameIndex * samplesPerFrame
-		endSample := startSample + samplesPerFrame
-		if endSample > len(pcm) {
+		n, err := src.Read(frame)
+		if err != nil {
+			return fmt.Errorf("read wav: %w", err)
+		}
+		if n < samplesPerFrame {
 			loopCount++
 			if flagOnce {
 				log.Printf("done: duration=%.2fs frames=%d loops=%d", time.Since(start).Seconds(), frameIndex, loopCount)
 				break
 			}
 			frameIndex = 0
-			startSample = 0
-			endSample = samplesPerFrame
-			if endSample > len(pcm) {
-				endSample = len(pcm)
+			if err := src.Rewind(); err != nil {
+				return fmt.Errorf("rewind wav: %w", err)
+			}
+			if n, err = src.Read(frame); err != nil {
+				return fmt.Errorf("read wav: %w", err)
 			}
 		}
-		if err := track.WriteSample(pcm[startSample:endSample]); err != nil {
+		if err := track.WriteSample(frame[:n]); err != nil {
 			return fmt.Errorf("write sample: %w", err)
 		}
 		frameIndex++
@@ -203,88 +213,62 @@ func run() error {
 	return nil
 }
 
-func applyGain(samples []int16, gain float64) {
-	if gain == 1.0 {
-		return
-	}
-	for i, v := range samples {
-		scaled := float64(v) * gain
-		if scaled > 32767 {
-			scaled = 32767
-		} else if scaled < -32768 {
-			scaled = -32768
-		}
-		samples[i] = int16(scaled)
-	}
-}
-
 type wavFile struct {
 	SampleRate    int
 	Channels      int
 	BitsPerSample int
-	Data          []byte
+	Data          []byte // nil when the data chunk is streamed from disk
+	DataOffset    int64  // file offset of the data chunk payload
+	DataSize      int64
 }
 
 func (w *wavFile) DurationSeconds() float64 {
 	if w.SampleRate == 0 || w.Channels == 0 {
 		return 0
 	}
-	samples := len(w.Data) / 2 / w.Channels
+	samples := w.DataSize / 2 / int64(w.Channels)
 	return float64(samples) / float64(w.SampleRate)
 }
 
-func loadWAV(path string) (*wavFile, error) {
+// loadWAV parses the WAV at path. A data chunk larger than maxInMemory bytes
+// is left on disk (Data nil) to be streamed; smaller ones are read in.
+func loadWAV(path string, maxInMemory int64) (*wavFile, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
-	header := make([]byte, 12)
-	if _, err := io.ReadFull(f, header); err != nil {
+	br := bufio.NewReader(f)
+	hdr, err := audio.ReadWAVHeader(br)
+	if err != nil {
 		return nil, err
 	}
-	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
-		return nil, errors.New("not a RIFF/WAVE file")
+	if hdr.AudioFormat != 1 {
+		return nil, fmt.Errorf("unsupported format=%d", hdr.AudioFormat)
 	}
-	var sampleRate, channels, bitsPerSample int
-	var dataChunk []byte
-	for {
-		chunkHeader := make([]byte, 8)
-		if _, err := io.ReadFull(f, chunkHeader); err != nil {
-			return nil, fmt.Errorf("read chunk header: %w", err)
-		}
-		id := string(chunkHeader[0:4])
-		size := int(binary.LittleEndian.Uint32(chunkHeader[4:8]))
-		payload := make([]byte, size)
-		if _, err := io.ReadFull(f, payload); err != nil {
-			return nil, fmt.Errorf("read chunk %s: %w", id, err)
-		}
-		switch id {
-		case "fmt ":
-			if size < 16 {
-				return nil, errors.New("fmt chunk too small")
-			}
-			if af := binary.LittleEndian.Uint16(payload[0:2]); af != 1 {
-				return nil, fmt.Errorf("unsupported format=%d", af)
-			}
-			channels = int(binary.LittleEndian.Uint16(payload[2:4]))
-			sampleRate = int(binary.LittleEndian.Uint32(payload[4:8]))
-			bitsPerSample = int(binary.LittleEndian.Uint16(payload[14:16]))
-		case "data":
-			dataChunk = payload
-		}
-		if sampleRate != 0 && dataChunk != nil {
-			break
-		}
-		stat, _ := f.Stat()
-		if pos, _ := f.Seek(0, io.SeekCurrent); stat != nil && pos >= stat.Size() {
-			break
-		}
+	pos, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
 	}
-	if sampleRate == 0 || dataChunk == nil {
-		return nil, errors.New("missing fmt or data chunk")
+	w := &wavFile{
+		SampleRate:    hdr.SampleRate,
+		Channels:      hdr.Channels,
+		BitsPerSample: hdr.BitsPerSample,
+		DataOffset:    pos - int64(br.Buffered()),
+		DataSize:      hdr.DataLength(),
+	}
+	if stat, err := f.Stat(); err == nil && w.DataSize > stat.Size()-w.DataOffset {
+		// Truncated file, or a streamed WAV with a placeholder size; use
+		// what's actually there
+		w.DataSize = stat.Size() - w.DataOffset
+	}
+	if w.DataSize <= maxInMemory {
+		w.Data = make([]byte, w.DataSize)
+		if _, err := io.ReadFull(br, w.Data); err != nil {
+			return nil, fmt.Errorf("read chunk data: %w", err)
+		}
 	}
-	return &wavFile{SampleRate: sampleRate, Channels: channels, BitsPerSample: bitsPerSample, Data: dataChunk}, nil
+	return w, nil
 }
 
 func decodeJWTIdentity(tok string) (sub, name string) {
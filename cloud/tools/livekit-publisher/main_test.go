@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// riffChunk is a RIFF chunk with the pad byte an odd-sized body needs
+func riffChunk(id string, body []byte) []byte {
+	out := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// TestLoadWAVSkipsPadByte checks the data offset and contents come out right
+// after an odd-sized chunk and its pad byte, whether the data is read in or
+// left on disk to stream
+func TestLoadWAVSkipsPadByte(t *testing.T) {
+	fmtBody := binary.LittleEndian.AppendUint16(nil, 1)        // PCM
+	fmtBody = binary.LittleEndian.AppendUint16(fmtBody, 1)     // mono
+	fmtBody = binary.LittleEndian.AppendUint32(fmtBody, 16000) // sample rate
+	fmtBody = binary.LittleEndian.AppendUint32(fmtBody, 32000) // byte rate
+	fmtBody = binary.LittleEndian.AppendUint16(fmtBody, 2)     // block align
+	fmtBody = binary.LittleEndian.AppendUint16(fmtBody, 16)    // bits
+	data := []byte{1, 2, 3, 4, 5, 6}
+
+	file := []byte("RIFF\x00\x00\x00\x00WAVE")
+	file = append(file, riffChunk("fmt ", fmtBody)...)
+	file = append(file, riffChunk("LIST", []byte("odd"))...)
+	file = append(file, riffChunk("data", data)...)
+	wantOffset := int64(len(file) - len(data))
+
+	path := filepath.Join(t.TempDir(), "odd.wav")
+	if err := os.WriteFile(path, file, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, maxInMemory := range []int64{1 << 20, 0} {
+		w, err := loadWAV(path, maxInMemory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.DataOffset != wantOffset || w.DataSize != int64(len(data)) {
+			t.Errorf("maxInMemory=%d: data at %d+%d, want %d+%d", maxInMemory, w.DataOffset, w.DataSize, wantOffset, len(data))
+		}
+		if maxInMemory > 0 && !bytes.Equal(w.Data, data) {
+			t.Errorf("data = %v, want %v", w.Data, data)
+		}
+		if maxInMemory == 0 && w.Data != nil {
+			t.Errorf("data read in, want it left on disk")
+		}
+	}
+}
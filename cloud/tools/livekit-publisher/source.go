@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/Mentra-Community/MentraOS/cloud/pkg/audio"
+)
+
+// pcmSource yields 16-bit samples for the playback loop, either from memory
+// or streamed from the WAV on disk.
+type pcmSource interface {
+	// Read fills dst and returns how many samples it wrote. Fewer than
+	// len(dst) means the end of the data was reached.
+	Read(dst []int16) (int, error)
+	// Rewind starts again from the first sample.
+	Rewind() error
+}
+
+// memorySource plays a fully decoded buffer.
+type memorySource struct {
+	pcm []int16
+	pos int
+}
+
+func newMemorySource(data []byte, gain float64) *memorySource {
+	pcm := audio.BytesToInt16(data)
+	audio.ApplyGain(pcm, gain)
+	return &memorySource{pcm: pcm}
+}
+
+func (m *memorySource) Read(dst []int16) (int, error) {
+	n := copy(dst, m.pcm[m.pos:])
+	m.pos += n
+	return n, nil
+}
+
+func (m *memorySource) Rewind() error {
+	m.pos = 0
+	return nil
+}
+
+// streamSource reads the data chunk from disk a window at a time, so memory
+// stays flat however long the file is.
+type streamSource struct {
+	f       *os.File
+	section *io.SectionReader
+	r       *bufio.Reader
+	gain    float64
+	raw     []byte
+}
+
+// streamWindow is how much of the data chunk is buffered from disk at once
+const streamWindow = 256 << 10
+
+func newStreamSource(path string, offset, size int64, gain float64) (*streamSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	section := io.NewSectionReader(f, offset, size)
+	return &streamSource{
+		f:       f,
+		section: section,
+		r:       bufio.NewReaderSize(section, streamWindow),
+		gain:    gain,
+	}, nil
+}
+
+func (s *streamSource) Read(dst []int16) (int, error) {
+	if cap(s.raw) < len(dst)*2 {
+		s.raw = make([]byte, len(dst)*2)
+	}
+	raw := s.raw[:len(dst)*2]
+	n, err := io.ReadFull(s.r, raw)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+	samples := copy(dst, audio.BytesToInt16(raw[:n]))
+	audio.ApplyGain(dst[:samples], s.gain)
+	return samples, nil
+}
+
+func (s *streamSource) Rewind() error {
+	if _, err := s.section.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.r.Reset(s.section)
+	return nil
+}
+
+func (s *streamSource) Close() error {
+	return s.f.Close()
+}